@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/nathfavour/autocommiter.go/internal/git"
+	"github.com/nathfavour/autocommiter.go/internal/processor"
+	"github.com/spf13/cobra"
+)
+
+var (
+	changelogFrom   string
+	changelogTo     string
+	changelogFormat string
+	changelogNotes  bool
+	changelogModel  string
+)
+
+func init() {
+	changelogCmd.Flags().StringVar(&changelogFrom, "from", "", "Tag or revision to start after (defaults to the latest tag before --to)")
+	changelogCmd.Flags().StringVar(&changelogTo, "to", "HEAD", "Tag or revision to end at")
+	changelogCmd.Flags().StringVar(&changelogFormat, "format", "markdown", "Output format: markdown or json")
+	changelogCmd.Flags().BoolVar(&changelogNotes, "notes", false, "Rewrite the changelog as user-facing release notes with the LLM")
+	changelogCmd.Flags().StringVar(&changelogModel, "model", "", "Model to use instead of the configured one (with --notes)")
+	rootCmd.AddCommand(changelogCmd)
+}
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog",
+	Short: "Generate a changelog and suggest the next version from commit history",
+	Long: `Walk the commits between two tags, group their Conventional Commit
+subjects (gitmoji prefixes included) by type and scope, list breaking
+changes, and suggest the next semantic version.
+
+With --notes the changelog is rewritten as user-facing release notes by the
+configured AI provider.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if changelogFormat != "markdown" && changelogFormat != "json" {
+			return fmt.Errorf("unknown format %q (use markdown or json)", changelogFormat)
+		}
+		path := repoPath
+		if path == "" {
+			path = "."
+		}
+		repoRoot, err := git.GetRepoRoot(path)
+		if err != nil {
+			return err
+		}
+
+		// Progress goes to stderr so the changelog can be piped.
+		color.Output = os.Stderr
+		release, err := processor.GenerateChangelog(repoRoot, processor.ChangelogOptions{
+			From:    changelogFrom,
+			To:      changelogTo,
+			Notes:   changelogNotes,
+			Offline: offline,
+			Model:   changelogModel,
+		})
+		color.Output = os.Stdout
+		if err != nil {
+			return err
+		}
+
+		if changelogFormat == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(release)
+		}
+
+		if release.Notes != "" {
+			fmt.Println(release.Notes)
+		} else {
+			fmt.Print(release.Markdown())
+		}
+		if release.NextVersion != "" {
+			from := release.From
+			if from == "" {
+				from = "no previous tag"
+			}
+			color.New(color.FgGreen).Fprintf(os.Stderr, "\n📦 Suggested next version: %s (%s bump from %s)\n", release.NextVersion, release.Bump, from)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/nathfavour/autocommiter.go/internal/git"
+	"github.com/nathfavour/autocommiter.go/internal/hooks"
+	"github.com/nathfavour/autocommiter.go/internal/processor"
+	"github.com/spf13/cobra"
+)
+
+var installCommitMsgHook bool
+
+func init() {
+	installHookCmd.Flags().BoolVar(&installCommitMsgHook, "commit-msg", false, "Also install a commit-msg hook that rejects messages breaking the lint rules")
+	rootCmd.AddCommand(installHookCmd)
+	rootCmd.AddCommand(uninstallHookCmd)
+	rootCmd.AddCommand(hookCmd)
+}
+
+func hookRepoRoot() (string, error) {
+	path := repoPath
+	if path == "" {
+		path = "."
+	}
+	return git.GetRepoRoot(path)
+}
+
+var installHookCmd = &cobra.Command{
+	Use:   "install-hook",
+	Short: "Install a prepare-commit-msg hook so plain 'git commit' gets an AI message",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repoRoot, err := hookRepoRoot()
+		if err != nil {
+			return err
+		}
+		exe, err := os.Executable()
+		if err != nil {
+			exe = "autocommiter"
+		}
+		hookTypes := []string{hooks.PrepareCommitMsg}
+		if installCommitMsgHook {
+			hookTypes = append(hookTypes, hooks.CommitMsg)
+		}
+		for _, hookType := range hookTypes {
+			path, err := hooks.Install(repoRoot, hookType, exe)
+			if err != nil {
+				return err
+			}
+			color.Green("✓ Installed %s hook at %s", hookType, path)
+			if _, err := os.Stat(path + hooks.ChainSuffix); err == nil {
+				color.New(color.Faint).Printf("  Existing hook kept as %s and run first.\n", path+hooks.ChainSuffix)
+			}
+		}
+		return nil
+	},
+}
+
+var uninstallHookCmd = &cobra.Command{
+	Use:   "uninstall-hook",
+	Short: "Remove the autocommiter prepare-commit-msg and commit-msg hooks",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repoRoot, err := hookRepoRoot()
+		if err != nil {
+			return err
+		}
+		removed := false
+		for _, hookType := range []string{hooks.PrepareCommitMsg, hooks.CommitMsg} {
+			path, err := hooks.Uninstall(repoRoot, hookType)
+			if err != nil {
+				return err
+			}
+			if path != "" {
+				color.Green("✓ Removed %s", path)
+				removed = true
+			}
+		}
+		if !removed {
+			color.Yellow("ℹ️ No autocommiter hook installed.")
+		}
+		return nil
+	},
+}
+
+var hookCmd = &cobra.Command{
+	Use:    "hook [TYPE] [ARGS...]",
+	Short:  "Entry point invoked by installed git hooks",
+	Hidden: true,
+	Args:   cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Hooks share the terminal with git; keep progress off stdout.
+		color.Output = os.Stderr
+
+		switch args[0] {
+		case hooks.PrepareCommitMsg:
+			if len(args) < 2 {
+				return fmt.Errorf("%s hook requires the message file", hooks.PrepareCommitMsg)
+			}
+			source := ""
+			if len(args) > 2 {
+				source = args[2]
+			}
+			path := repoPath
+			if path == "" {
+				path = "."
+			}
+			if err := processor.RunPrepareCommitMsgHook(path, args[1], source); err != nil {
+				// Never block the commit; the user can still write a message.
+				color.Yellow("⚠️ autocommiter: could not generate a message: %v", err)
+			}
+			return nil
+		case hooks.CommitMsg:
+			if len(args) < 2 {
+				return fmt.Errorf("%s hook requires the message file", hooks.CommitMsg)
+			}
+			path := repoPath
+			if path == "" {
+				path = "."
+			}
+			result, err := processor.RunCommitMsgHook(path, args[1])
+			if err != nil {
+				// Only a message that breaks the rules blocks the commit.
+				color.Yellow("⚠️ autocommiter: could not lint the message: %v", err)
+				return nil
+			}
+			if len(result.Violations) == 0 {
+				return nil
+			}
+			color.Red("✖ Commit message rejected by autocommiter lint:")
+			for _, v := range result.Violations {
+				fmt.Fprintf(os.Stderr, "  %s\n", v)
+			}
+			color.New(color.Faint).Println("  The message is kept in .git/COMMIT_EDITMSG; 'git commit --no-verify' skips this check.")
+			os.Exit(1)
+			return nil
+		default:
+			return fmt.Errorf("unsupported hook: %s", args[0])
+		}
+	},
+}
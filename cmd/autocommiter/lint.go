@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/nathfavour/autocommiter.go/internal/git"
+	"github.com/nathfavour/autocommiter.go/internal/lint"
+	"github.com/nathfavour/autocommiter.go/internal/processor"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lintRange  string
+	lintFormat string
+	lintFix    bool
+)
+
+func init() {
+	lintCmd.Flags().StringVar(&lintRange, "range", "", "Lint the commits of a revision range (e.g. origin/main..HEAD)")
+	lintCmd.Flags().StringVar(&lintFormat, "format", "human", "Output format: human or json")
+	lintCmd.Flags().BoolVar(&lintFix, "fix", false, "Fix what can be fixed mechanically (rewrites FILE, or prints the message read from stdin)")
+	rootCmd.AddCommand(lintCmd)
+}
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [FILE|-]",
+	Short: "Check commit messages against the configured lint rules",
+	Long: `Check a commit message against the rules in the commit_lint config: allowed
+types and scopes, subject length, no trailing period, body wrap width and
+required trailers.
+
+The message is read from FILE, or from stdin with "-". With --range every
+commit of the range is checked, and with neither the HEAD commit is.
+
+Exits with status 1 when a message breaks a rule.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if lintFormat != "human" && lintFormat != "json" {
+			return fmt.Errorf("unknown format %q (use human or json)", lintFormat)
+		}
+		if lintRange != "" && len(args) > 0 {
+			return fmt.Errorf("--range and FILE cannot be combined")
+		}
+		if lintFix && len(args) == 0 {
+			return fmt.Errorf("--fix needs a message FILE or - for stdin")
+		}
+		path := repoPath
+		if path == "" {
+			path = "."
+		}
+		repoRoot, err := git.GetRepoRoot(path)
+		if err != nil {
+			return err
+		}
+
+		var results []processor.LintResult
+		switch {
+		case len(args) == 1:
+			var data []byte
+			if args[0] == "-" {
+				data, err = io.ReadAll(os.Stdin)
+			} else {
+				data, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				return err
+			}
+			message := lint.Clean(string(data), "#")
+			if lintFix {
+				message = processor.FixMessage(repoRoot, message)
+				if args[0] == "-" {
+					fmt.Println(message)
+				} else if err := os.WriteFile(args[0], []byte(message+"\n"), 0644); err != nil {
+					return err
+				}
+			}
+			results = append(results, processor.LintMessage(repoRoot, message))
+		case lintRange != "":
+			results, err = processor.LintCommits(repoRoot, lintRange)
+		default:
+			// The latest commit; "HEAD^!" would fail on a root commit.
+			results, err = processor.LintCommits(repoRoot, "--max-count=1")
+		}
+		if err != nil {
+			return err
+		}
+
+		failed := 0
+		for _, r := range results {
+			if len(r.Violations) > 0 {
+				failed++
+			}
+		}
+
+		if lintFormat == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(results); err != nil {
+				return err
+			}
+		} else {
+			// With --fix on stdin, stdout carries the fixed message.
+			out := os.Stdout
+			if lintFix {
+				out = os.Stderr
+			}
+			printLintResults(out, results, failed)
+		}
+
+		if failed > 0 {
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+func printLintResults(out io.Writer, results []processor.LintResult, failed int) {
+	for _, r := range results {
+		if len(r.Violations) == 0 {
+			continue
+		}
+		header := r.Subject
+		if r.Commit != "" {
+			header = r.Commit[:7] + " " + header
+		}
+		color.New(color.Bold).Fprintln(out, header)
+		for _, v := range r.Violations {
+			fmt.Fprint(out, "  ")
+			if v.Fixable {
+				color.New(color.FgYellow).Fprintln(out, v.String()+" (fixable)")
+			} else {
+				color.New(color.FgRed).Fprintln(out, v.String())
+			}
+		}
+	}
+	if failed == 0 {
+		color.New(color.FgGreen).Fprintf(out, "✓ %d message(s) follow the lint rules\n", len(results))
+		return
+	}
+	color.New(color.FgRed).Fprintf(out, "✖ %d of %d message(s) break the lint rules\n", failed, len(results))
+}
@@ -12,11 +12,13 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/nathfavour/autocommiter.go/internal/anyisland"
+	"github.com/nathfavour/autocommiter.go/internal/api"
 	"github.com/nathfavour/autocommiter.go/internal/auth"
 	"github.com/nathfavour/autocommiter.go/internal/config"
 	"github.com/nathfavour/autocommiter.go/internal/git"
 	"github.com/nathfavour/autocommiter.go/internal/models"
 	"github.com/nathfavour/autocommiter.go/internal/processor"
+	"github.com/nathfavour/autocommiter.go/internal/workspace"
 	"github.com/spf13/cobra"
 )
 
@@ -29,7 +31,14 @@ rootCmd = &cobra.Command{
 	noPush   bool
 	noSecure bool
 	force    bool
+	offline  bool
+	stage    string
 	user     string
+	jobs     int
+	report   string
+	tags     []string
+	excludes []string
+	submods  string
 
 	// Version metadata fallbacks
 	version = "dev"
@@ -64,29 +73,37 @@ func main() {
 		return nil
 	}
 	rootCmd.RunE = func(cmd *cobra.Command, args []string) error {
-		return processor.GenerateCommit(repoPath, noPush, noSecure, force)
+		return generateCommit()
 	}
 
 	rootCmd.PersistentFlags().StringVarP(&repoPath, "repo", "r", "", "Path to git repository (defaults to current directory)")
 	rootCmd.PersistentFlags().BoolVarP(&noPush, "no-push", "n", false, "Skip pushing after commit")
 	rootCmd.PersistentFlags().BoolVar(&noSecure, "no-secure", false, "Skip SECURE_MODE checks for this run")
 	rootCmd.PersistentFlags().BoolVarP(&force, "force", "f", false, "Don't ask for confirmation before committing")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "Generate messages locally without contacting any AI provider")
+	rootCmd.PersistentFlags().StringVar(&stage, "stage", "", "Staging policy when nothing is staged: tracked, all, interactive, abort")
 	rootCmd.PersistentFlags().StringVarP(&user, "user", "u", "", "Set default GitHub user for this repository")
+	rootCmd.PersistentFlags().StringVar(&submods, "submodules", "", "Submodule policy for this run: skip, include, recurse")
+	rootCmd.PersistentFlags().IntVarP(&jobs, "jobs", "j", processor.DefaultJobs, "Repositories prepared at once when several are found")
+	rootCmd.PersistentFlags().StringVar(&report, "report", processor.ReportTable, "Batch summary format: table or json")
 
 	var generateCmd = &cobra.Command{
 		Use:   "generate",
 		Short: "Generate commit message and commit changes",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return processor.GenerateCommit(repoPath, noPush, noSecure, force)
+			return generateCommit()
 		},
 	}
 	rootCmd.AddCommand(generateCmd)
+	for _, cmd := range []*cobra.Command{rootCmd, generateCmd} {
+		addFilterFlags(cmd)
+	}
 
 	var generateMessageCmd = &cobra.Command{
 		Use:   "generate-message",
 		Short: "Generate and output only the commit message (no commit/push)",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			msg, err := processor.GenerateMessage(repoPath, nil)
+			msg, err := processor.GenerateMessage(repoPath, nil, processor.MessageOptions{Offline: offline})
 			if err != nil {
 				return err
 			}
@@ -100,12 +117,15 @@ func main() {
 	var listReposCmd = &cobra.Command{
 		Use:   "list-repos",
 		Short: "List all git repositories in the specified path",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			path := repoPath
 			if path == "" {
 				path = "."
 			}
-			repos := git.DiscoverRepositories(path)
+			repos, err := workspace.Discover(path, repoFilter())
+			if err != nil {
+				return err
+			}
 			if jsonOutput {
 				data, _ := json.Marshal(repos)
 				fmt.Print(string(data))
@@ -114,27 +134,53 @@ func main() {
 					fmt.Println(r)
 				}
 			}
+			return nil
 		},
 	}
 	listReposCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	addFilterFlags(listReposCmd)
 	rootCmd.AddCommand(listReposCmd)
 
 	var prepareCmd = &cobra.Command{
 		Use:   "prepare",
-		Short: "Prepare a repository ( stage changes and ensure gitignore safety)",
+		Short: "Prepare a repository (stage changes per the staging policy and ensure gitignore safety)",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			path := repoPath
 			if path == "" {
 				path = "."
 			}
-			if err := processor.EnsureGitignoreSafety(path); err != nil {
+			repoRoot, err := git.GetRepoRoot(path)
+			if err != nil {
+				return err
+			}
+			repo := processor.OpenRepository(repoRoot)
+			if err := processor.EnsureGitignoreSafety(repo); err != nil {
 				return err
 			}
-			return git.StageAllChanges(path)
+			cfg, _ := config.LoadMergedConfig(repoRoot)
+			policy, err := processor.ResolveStagingPolicy(cfg, stage)
+			if err != nil {
+				return err
+			}
+			return processor.StageByPolicy(repo, policy, !force)
 		},
 	}
 	rootCmd.AddCommand(prepareCmd)
 
+	var splitCmd = &cobra.Command{
+		Use:   "split",
+		Short: "Split staged changes into multiple atomic commits",
+		Long:  "Group the staged files into logical changesets (dependencies, CI, docs, and code by directory), propose a message for each, and commit them in order.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := repoPath
+			if path == "" {
+				path = "."
+			}
+			return processor.SplitCommit(path, commitOptions())
+		},
+	}
+	rootCmd.AddCommand(splitCmd)
+
 	var summarizeCmd = &cobra.Command{
 		Use:   "summarize",
 		Short: "Summarize changes in a repository as JSON",
@@ -153,6 +199,44 @@ func main() {
 	}
 	rootCmd.AddCommand(summarizeCmd)
 
+	var refreshStyle bool
+	var styleCmd = &cobra.Command{
+		Use:   "style",
+		Short: "Show the commit style learned from the repository's history",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := repoPath
+			if path == "" {
+				path = "."
+			}
+			repoRoot, err := git.GetRepoRoot(path)
+			if err != nil {
+				return err
+			}
+			cfg, _ := config.LoadMergedConfig(repoRoot)
+			profile, err := processor.LoadStyleProfile(repoRoot, cfg, refreshStyle)
+			if err != nil {
+				return err
+			}
+
+			color.New(color.FgCyan, color.Bold).Println("🎨 Commit Style:")
+			fmt.Printf("  Samples:        %d\n", profile.Samples)
+			fmt.Printf("  Convention:     %s\n", color.YellowString(profile.Convention))
+			fmt.Printf("  Casing:         %s\n", profile.Casing)
+			fmt.Printf("  Gitmoji:        %s\n", formatBool(profile.Gitmoji))
+			fmt.Printf("  Avg subject:    %d chars\n", profile.AvgSubjectLen)
+			fmt.Printf("  Bodies:         %.0f%%\n", profile.BodyRatio*100)
+			if len(profile.Scopes) > 0 {
+				fmt.Printf("  Scopes:         %s\n", strings.Join(profile.Scopes, ", "))
+			}
+			if profile.PromptRules() == "" {
+				color.Yellow("ℹ️ Not enough history yet; the default Conventional Commits prompt is used.")
+			}
+			return nil
+		},
+	}
+	styleCmd.Flags().BoolVar(&refreshStyle, "refresh", false, "Re-analyze history instead of using the cached profile")
+	rootCmd.AddCommand(styleCmd)
+
 	var setApiKeyCmd = &cobra.Command{
 		Use:   "set-api-key [KEY]",
 		Short: "Set GitHub API key (optional if 'gh auth login' is used)",
@@ -308,6 +392,148 @@ func main() {
 	}
 	rootCmd.AddCommand(selectModelCmd)
 
+	var setProviderCmd = &cobra.Command{
+		Use:   "set-provider [PROVIDER] [BASE_URL]",
+		Short: "Set the LLM provider (github, openai, ollama, anthropic)",
+		Long:  "Set the LLM provider used for generation. BASE_URL points OpenAI-compatible servers (vLLM, LM Studio, llama.cpp) or a remote Ollama at the right host. Use a repository's .autocommiter.json to select a provider per project.",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := strings.ToLower(strings.TrimSpace(args[0]))
+			baseURL := ""
+			if len(args) > 1 {
+				baseURL = strings.TrimSpace(args[1])
+			}
+			if _, err := api.NewProvider(name, baseURL, ""); err != nil {
+				return err
+			}
+
+			cfg, _ := config.LoadConfig()
+			cfg.Provider = &name
+			cfg.ProviderBaseURL = &baseURL
+			if err := config.SaveConfig(cfg); err != nil {
+				return err
+			}
+			if baseURL != "" {
+				color.Green("✓ Provider set to: %s (%s)", name, baseURL)
+			} else {
+				color.Green("✓ Provider set to: %s", name)
+			}
+			return nil
+		},
+	}
+	rootCmd.AddCommand(setProviderCmd)
+
+	var setStagingPolicyCmd = &cobra.Command{
+		Use:   "set-staging-policy [POLICY]",
+		Short: "Set what gets staged when nothing is staged (tracked, all, interactive, abort)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			policy := strings.ToLower(strings.TrimSpace(args[0]))
+			if err := processor.ValidateStagingPolicy(policy); err != nil {
+				return err
+			}
+
+			cfg, _ := config.LoadConfig()
+			cfg.StagingPolicy = &policy
+			if err := config.SaveConfig(cfg); err != nil {
+				return err
+			}
+			color.Green("✓ Staging policy set to: %s", policy)
+			return nil
+		},
+	}
+	rootCmd.AddCommand(setStagingPolicyCmd)
+
+	var setSubmodulePolicyCmd = &cobra.Command{
+		Use:   "set-submodule-policy [POLICY]",
+		Short: "Set how submodules of discovered repositories are committed (skip, include, recurse)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			policy := strings.ToLower(strings.TrimSpace(args[0]))
+			if err := processor.ValidateSubmodulePolicy(policy); err != nil {
+				return err
+			}
+
+			cfg, _ := config.LoadConfig()
+			cfg.SubmodulePolicy = &policy
+			if err := config.SaveConfig(cfg); err != nil {
+				return err
+			}
+			color.Green("✓ Submodule policy set to: %s", policy)
+			return nil
+		},
+	}
+	rootCmd.AddCommand(setSubmodulePolicyCmd)
+
+	var setGitBackendCmd = &cobra.Command{
+		Use:   "set-git-backend [BACKEND]",
+		Short: "Set how staged changes are read (exec, go-git)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			backend := strings.ToLower(strings.TrimSpace(args[0]))
+			if err := processor.ValidateGitBackend(backend); err != nil {
+				return err
+			}
+
+			cfg, _ := config.LoadConfig()
+			cfg.GitBackend = &backend
+			if err := config.SaveConfig(cfg); err != nil {
+				return err
+			}
+			color.Green("✓ Git backend set to: %s", backend)
+			return nil
+		},
+	}
+	rootCmd.AddCommand(setGitBackendCmd)
+
+	var setBulkyPolicyCmd = &cobra.Command{
+		Use:   "set-bulky-policy [POLICY]",
+		Short: "Set how staged bulky binaries are handled (ignore, lfs, block)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			policy := strings.ToLower(strings.TrimSpace(args[0]))
+			if err := processor.ValidateBulkyPolicy(policy); err != nil {
+				return err
+			}
+
+			cfg, _ := config.LoadConfig()
+			cfg.BulkyFilePolicy = &policy
+			if err := config.SaveConfig(cfg); err != nil {
+				return err
+			}
+			color.Green("✓ Bulky file policy set to: %s", policy)
+			if policy == processor.BulkyLFS {
+				cwd, _ := os.Getwd()
+				if !git.IsLFSInstalled(cwd) {
+					color.Yellow("⚠️  git-lfs is not installed; commits with bulky binaries will be blocked until it is.")
+				}
+			}
+			return nil
+		},
+	}
+	rootCmd.AddCommand(setBulkyPolicyCmd)
+
+	var setBulkyThresholdCmd = &cobra.Command{
+		Use:   "set-bulky-threshold [MB]",
+		Short: "Set the size in MB above which staged binaries count as bulky",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mb, err := strconv.Atoi(args[0])
+			if err != nil || mb <= 0 {
+				return fmt.Errorf("threshold must be a positive number of megabytes")
+			}
+
+			cfg, _ := config.LoadConfig()
+			cfg.BulkyThresholdMB = &mb
+			if err := config.SaveConfig(cfg); err != nil {
+				return err
+			}
+			color.Green("✓ Bulky file threshold set to: %dMB", mb)
+			return nil
+		},
+	}
+	rootCmd.AddCommand(setBulkyThresholdCmd)
+
 	var rawModel bool
 	var getModelCmd = &cobra.Command{
 		Use:   "get-model",
@@ -475,11 +701,25 @@ func main() {
 				}
 			}
 
+			color.Cyan("\nProvider:")
+			provider := api.ProviderGitHub
+			if cfg.Provider != nil && *cfg.Provider != "" {
+				provider = *cfg.Provider
+			}
+			if cfg.ProviderBaseURL != nil && *cfg.ProviderBaseURL != "" {
+				fmt.Printf("  %s (%s)\n", color.YellowString(provider), *cfg.ProviderBaseURL)
+			} else {
+				fmt.Printf("  %s\n", color.YellowString(provider))
+			}
+
 			color.Cyan("\nSelected Model:")
-			model := "gpt-4o-mini"
+			model := ""
 			if cfg.SelectedModel != nil {
 				model = *cfg.SelectedModel
 			}
+			if p, err := api.NewProvider(provider, "", ""); err == nil {
+				model = api.ResolveModel(p, model)
+			}
 			fmt.Printf("  %s\n", color.YellowString(model))
 
 			color.Cyan("\nGitmoji Enabled:")
@@ -504,6 +744,24 @@ func main() {
 				color.Red("  No")
 			}
 
+			color.Cyan("\nStaging Policy:")
+			stagingPolicy := processor.StageTracked
+			if cfg.StagingPolicy != nil && *cfg.StagingPolicy != "" {
+				stagingPolicy = *cfg.StagingPolicy
+			}
+			fmt.Printf("  %s\n", color.YellowString(stagingPolicy))
+
+			color.Cyan("\nOffline Fallback:")
+			fallback := true
+			if cfg.OfflineFallback != nil {
+				fallback = *cfg.OfflineFallback
+			}
+			if fallback {
+				color.Green("  Yes")
+			} else {
+				color.Red("  No")
+			}
+
 			color.Cyan("\nSkip Confirmation:")
 			skip := false
 			if cfg.SkipConfirmation != nil {
@@ -535,6 +793,8 @@ func main() {
 
 				fmt.Printf("  - Detect PII/Leaks:   %s\n", formatBool(pii))
 				fmt.Printf("  - Detect Bulky Files: %s\n", formatBool(bulky))
+				bulkyPolicy, _ := processor.ResolveBulkyPolicy(cfg)
+				fmt.Printf("  - Bulky File Policy:  %s (> %dMB)\n", color.YellowString(bulkyPolicy), processor.BulkyThreshold(cfg)/(1024*1024))
 			} else {
 				color.Red("  No")
 			}
@@ -705,6 +965,47 @@ func main() {
 	}
 }
 
+func commitOptions() processor.CommitOptions {
+	return processor.CommitOptions{
+		NoPush:     noPush,
+		NoSecure:   noSecure,
+		Force:      force,
+		Offline:    offline,
+		Stage:      stage,
+		Jobs:       jobs,
+		Report:     report,
+		Filter:     repoFilter(),
+		Submodules: submods,
+	}
+}
+
+// addFilterFlags adds the flags that narrow the repositories a command
+// discovers.
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&tags, "tag", nil, "Only repositories with this workspace tag (repeatable)")
+	cmd.Flags().StringSliceVar(&excludes, "exclude", nil, "Skip repositories whose path matches this glob (repeatable)")
+}
+
+func repoFilter() workspace.Filter {
+	return workspace.Filter{Tags: tags, Exclude: excludes}
+}
+
+func generateCommit() error {
+	if err := processor.ValidateReport(report); err != nil {
+		return err
+	}
+	if submods != "" {
+		if err := processor.ValidateSubmodulePolicy(submods); err != nil {
+			return err
+		}
+	}
+	if report == processor.ReportJSON {
+		// Keep stdout for the report.
+		color.Output = os.Stderr
+	}
+	return processor.GenerateCommit(repoPath, commitOptions())
+}
+
 func formatBool(b bool) string {
 	if b {
 		return color.GreenString("Yes")
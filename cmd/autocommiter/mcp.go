@@ -0,0 +1,30 @@
+package main
+
+import (
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/nathfavour/autocommiter.go/internal/mcp"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+}
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Run a Model Context Protocol (MCP) server over stdio",
+	Long:  "Speak JSON-RPC 2.0 MCP over stdin/stdout so editors and agents can drive autocommiter natively. Progress output is written to stderr.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// stdout carries protocol messages only; everything the workflow
+		// prints is redirected to stderr.
+		protocolOut := os.Stdout
+		os.Stdout = os.Stderr
+		color.Output = os.Stderr
+		color.NoColor = true
+
+		server := mcp.NewServer("autocommiter", version, agentTools())
+		return server.Serve(os.Stdin, protocolOut)
+	},
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/nathfavour/autocommiter.go/internal/git"
+	"github.com/nathfavour/autocommiter.go/internal/processor"
+	"github.com/spf13/cobra"
+)
+
+var (
+	prBase   string
+	prModel  string
+	prCreate bool
+	prDraft  bool
+	prJSON   bool
+)
+
+func init() {
+	prCmd.Flags().StringVar(&prBase, "base", "", "Branch the pull request targets (defaults to the remote's default branch)")
+	prCmd.Flags().StringVar(&prModel, "model", "", "Model to use instead of the configured one")
+	prCmd.Flags().BoolVar(&prCreate, "create", false, "Open the pull request with 'gh pr create'")
+	prCmd.Flags().BoolVar(&prDraft, "draft", false, "Open the pull request as a draft (with --create)")
+	prCmd.Flags().BoolVar(&prJSON, "json", false, "Print the title, body and breaking changes as JSON")
+	rootCmd.AddCommand(prCmd)
+}
+
+var prCmd = &cobra.Command{
+	Use:   "pr",
+	Short: "Generate a pull request title and description for the current branch",
+	Long: `Summarize the commits and the aggregate diff between the merge base with
+--base and HEAD into a pull request title, a description with a testing
+section, and a list of breaking changes.
+
+With --create the branch is pushed if needed and the pull request is opened
+with the GitHub CLI (gh).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := repoPath
+		if path == "" {
+			path = "."
+		}
+		repoRoot, err := git.GetRepoRoot(path)
+		if err != nil {
+			return err
+		}
+
+		// Progress goes to stderr so the description can be piped.
+		color.Output = os.Stderr
+		pr, err := processor.GeneratePullRequest(repoRoot, processor.PROptions{Base: prBase, Offline: offline, Model: prModel})
+		color.Output = os.Stdout
+		if err != nil {
+			return err
+		}
+
+		if prJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(struct {
+				*processor.PullRequest
+				Body string `json:"body"`
+			}{pr, pr.Body()}); err != nil {
+				return err
+			}
+		} else {
+			fmt.Printf("%s\n\n%s", pr.Title, pr.Body())
+		}
+
+		if !prCreate {
+			return nil
+		}
+		if !force {
+			fmt.Fprint(os.Stderr, color.CyanString("\n🤔 Open this pull request against %s? (y/n): ", pr.Base))
+			input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			if !strings.EqualFold(strings.TrimSpace(input), "y") {
+				color.New(color.FgRed).Fprintln(os.Stderr, "❌ Cancelled.")
+				return nil
+			}
+		}
+
+		if !noPush {
+			color.New(color.FgCyan).Fprintln(os.Stderr, "🚀 Pushing branch...")
+			push := git.PushChanges
+			if !git.HasUpstream(repoRoot) {
+				push = git.PushNewBranch
+			}
+			if err := push(repoRoot); err != nil {
+				return err
+			}
+		}
+
+		url, err := git.CreatePullRequest(repoRoot, pr.Base, pr.Title, pr.Body(), prDraft)
+		if err != nil {
+			return err
+		}
+		color.New(color.FgGreen).Fprintf(os.Stderr, "✓ Pull request created: %s\n", url)
+		return nil
+	},
+}
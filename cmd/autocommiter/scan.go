@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/nathfavour/autocommiter.go/internal/git"
+	"github.com/nathfavour/autocommiter.go/internal/processor"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of the scan command.
+const (
+	scanExitWarning = 2
+	scanExitError   = 3
+)
+
+var (
+	scanRange  string
+	scanAll    bool
+	scanFormat string
+	scanOutput string
+	scanFailOn string
+)
+
+func init() {
+	scanCmd.Flags().StringVar(&scanRange, "range", "", "Scan a revision range (e.g. origin/main..HEAD) instead of staged changes")
+	scanCmd.Flags().BoolVar(&scanAll, "all", false, "Scan every tracked file instead of staged changes")
+	scanCmd.Flags().StringVar(&scanFormat, "format", "human", "Output format: human, json, sarif")
+	scanCmd.Flags().StringVarP(&scanOutput, "output", "o", "", "Write the report to a file instead of stdout")
+	scanCmd.Flags().StringVar(&scanFailOn, "fail-on", processor.SeverityError, "Lowest severity that fails the scan: error, warning, none")
+	rootCmd.AddCommand(scanCmd)
+}
+
+var scanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: "Scan for secrets, PII and sensitive files without committing",
+	Long: `Run the SECURE_MODE checks on the staged changes, a commit range (--range) or the whole tree (--all).
+
+Exit codes: 0 when nothing at or above --fail-on was found, 1 when the scan
+itself failed, 2 when the worst finding is a warning, 3 when it is an error.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if scanAll && scanRange != "" {
+			return fmt.Errorf("--all and --range cannot be combined")
+		}
+		switch scanFailOn {
+		case processor.SeverityError, processor.SeverityWarning, "none":
+		default:
+			return fmt.Errorf("invalid --fail-on %q (use error, warning or none)", scanFailOn)
+		}
+
+		path := repoPath
+		if path == "" {
+			path = "."
+		}
+		repoRoot, err := git.GetRepoRoot(path)
+		if err != nil {
+			return err
+		}
+
+		report, err := processor.Scan(repoRoot, processor.ScanOptions{Range: scanRange, All: scanAll})
+		if err != nil {
+			return err
+		}
+
+		out := os.Stdout
+		if scanOutput != "" {
+			f, err := os.Create(scanOutput)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+
+		switch scanFormat {
+		case "json":
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(report); err != nil {
+				return err
+			}
+		case "sarif":
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(report.SARIF(version)); err != nil {
+				return err
+			}
+		case "human":
+			printScanReport(report)
+		default:
+			return fmt.Errorf("unknown format %q (use human, json or sarif)", scanFormat)
+		}
+
+		switch worst := report.MaxSeverity(); {
+		case worst == processor.SeverityError && scanFailOn != "none":
+			os.Exit(scanExitError)
+		case worst == processor.SeverityWarning && scanFailOn == processor.SeverityWarning:
+			os.Exit(scanExitWarning)
+		}
+		return nil
+	},
+}
+
+func printScanReport(report *processor.ScanReport) {
+	if len(report.Files) == 0 && len(report.Leaks) == 0 {
+		color.Green("✓ No findings (%s)", report.Target)
+		return
+	}
+
+	severityColor := func(s string) *color.Color {
+		if s == processor.SeverityError {
+			return color.New(color.FgRed)
+		}
+		return color.New(color.FgYellow)
+	}
+
+	color.New(color.FgCyan, color.Bold).Printf("🔍 Scan results (%s):\n", report.Target)
+	for _, f := range report.Files {
+		severityColor(f.Severity).Printf("   %-7s %s [%s]\n", f.Severity, f.File, f.Reason)
+	}
+	for _, l := range report.Leaks {
+		location := l.File
+		if l.Line > 0 {
+			location = fmt.Sprintf("%s:%d", l.File, l.Line)
+		}
+		severityColor(l.Severity).Printf("   %-7s %s [%s]", l.Severity, location, l.Type)
+		if l.Line > 0 {
+			fmt.Printf(": %s", color.New(color.Faint).Sprint(l.Content))
+		}
+		fmt.Println()
+	}
+	fmt.Printf("\n%d file issues, %d leaks\n", len(report.Files), len(report.Leaks))
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"github.com/fatih/color"
+	"github.com/nathfavour/autocommiter.go/internal/git"
+	"github.com/nathfavour/autocommiter.go/internal/processor"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	securityCmd.AddCommand(securityBaselineCmd)
+	rootCmd.AddCommand(securityCmd)
+}
+
+var securityCmd = &cobra.Command{
+	Use:   "security",
+	Short: "Manage SECURE_MODE secret detection",
+}
+
+var securityBaselineCmd = &cobra.Command{
+	Use:   "baseline",
+	Short: "Acknowledge the findings in the staged changes so they no longer block commits",
+	Long:  "Record every finding in the staged changes in .autocommiter-baseline.json, keyed by file, rule and a hash of the secret. Commit the file to share it with your team; new leaks still block.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := repoPath
+		if path == "" {
+			path = "."
+		}
+		repoRoot, err := git.GetRepoRoot(path)
+		if err != nil {
+			return err
+		}
+
+		added, baselinePath, err := processor.RecordBaseline(repoRoot)
+		if err != nil {
+			return err
+		}
+		if added == 0 {
+			color.Green("✓ No new findings to record.")
+			return nil
+		}
+		color.Green("✓ Recorded %d findings in %s", added, baselinePath)
+		return nil
+	},
+}
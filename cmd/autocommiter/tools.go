@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nathfavour/autocommiter.go/internal/git"
+	"github.com/nathfavour/autocommiter.go/internal/mcp"
+	"github.com/nathfavour/autocommiter.go/internal/processor"
+	"github.com/nathfavour/autocommiter.go/internal/workspace"
+)
+
+// toolArgs is the union of the arguments accepted by the agent tools.
+type toolArgs struct {
+	RepoPath string   `json:"repo_path"`
+	Path     string   `json:"path"`
+	Offline  bool     `json:"offline"`
+	Message  string   `json:"message"`
+	Push     bool     `json:"push"`
+	NoSecure bool     `json:"no_secure"`
+	Tags     []string `json:"tags"`
+}
+
+const repoPathSchema = `"repo_path":{"type":"string","description":"Path to the git repository (defaults to the current directory)"}`
+
+// agentTools lists the tools exposed to editors and agents through both the
+// MCP server and the vibe execute command.
+func agentTools() []mcp.Tool {
+	return []mcp.Tool{
+		{
+			Name:        "generate_commit_message",
+			Description: "Generate a commit message for the staged changes without committing",
+			InputSchema: json.RawMessage(`{"type":"object","properties":{` + repoPathSchema + `,"offline":{"type":"boolean","description":"Use the local heuristic generator instead of an AI provider"}}}`),
+			Handler:     toolGenerateMessage,
+		},
+		{
+			Name:        "summarize_changes",
+			Description: "Summarize staged changes as JSON",
+			InputSchema: json.RawMessage(`{"type":"object","properties":{` + repoPathSchema + `}}`),
+			Handler:     toolSummarizeChanges,
+		},
+		{
+			Name:        "commit",
+			Description: "Commit the staged changes, optionally pushing. When nothing is staged, files are staged per the configured staging_policy, with interactive falling back to tracked files",
+			InputSchema: json.RawMessage(`{"type":"object","properties":{` + repoPathSchema + `,"message":{"type":"string","description":"Commit message to use verbatim; generated when omitted"},"offline":{"type":"boolean","description":"Generate the message with the local heuristic generator instead of an AI provider","default":false},"push":{"type":"boolean","description":"Push after committing","default":false},"no_secure":{"type":"boolean","description":"Skip SECURE_MODE checks","default":false}}}`),
+			Handler:     toolCommit,
+		},
+		{
+			Name:        "security_scan",
+			Description: "Scan staged changes for secrets, PII and sensitive or bulky files without modifying anything",
+			InputSchema: json.RawMessage(`{"type":"object","properties":{` + repoPathSchema + `}}`),
+			Handler:     toolSecurityScan,
+		},
+		{
+			Name:        "list_repos",
+			Description: "List git repositories under a path (comma-separated paths are allowed)",
+			InputSchema: json.RawMessage(`{"type":"object","properties":{"path":{"type":"string","description":"Directory to search (defaults to the current directory)"},"tags":{"type":"array","items":{"type":"string"},"description":"Only repositories with one of these workspace manifest tags"}}}`),
+			Handler:     toolListRepos,
+		},
+		{
+			Name:        "analyze_accounts",
+			Description: "Compare the repository's git identity and active GitHub account with the account autocommiter would choose",
+			InputSchema: json.RawMessage(`{"type":"object","properties":{` + repoPathSchema + `}}`),
+			Handler:     toolAnalyzeAccounts,
+		},
+	}
+}
+
+func parseToolArgs(raw json.RawMessage) (toolArgs, error) {
+	var a toolArgs
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &a); err != nil {
+			return a, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+	if a.RepoPath == "" {
+		a.RepoPath = "."
+	}
+	if a.Path == "" {
+		a.Path = "."
+	}
+	return a, nil
+}
+
+func toolGenerateMessage(raw json.RawMessage) (interface{}, error) {
+	a, err := parseToolArgs(raw)
+	if err != nil {
+		return nil, err
+	}
+	repoRoot, err := git.GetRepoRoot(a.RepoPath)
+	if err != nil {
+		return nil, err
+	}
+	return processor.GenerateMessage(repoRoot, nil, processor.MessageOptions{Offline: a.Offline})
+}
+
+func toolSummarizeChanges(raw json.RawMessage) (interface{}, error) {
+	a, err := parseToolArgs(raw)
+	if err != nil {
+		return nil, err
+	}
+	repoRoot, err := git.GetRepoRoot(a.RepoPath)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := processor.GetSummarizedChanges(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(summary), nil
+}
+
+func toolCommit(raw json.RawMessage) (interface{}, error) {
+	a, err := parseToolArgs(raw)
+	if err != nil {
+		return nil, err
+	}
+	repoRoot, err := git.GetRepoRoot(a.RepoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := processor.OpenRepository(repoRoot)
+	before, _ := repo.HeadCommit()
+	err = processor.ProcessSingleRepo(repo, processor.CommitOptions{
+		NoPush:   !a.Push,
+		NoSecure: a.NoSecure,
+		Force:    true,
+		Offline:  a.Offline,
+		Message:  a.Message,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	after, _ := repo.HeadCommit()
+	if after == before {
+		return map[string]interface{}{"committed": false, "reason": "nothing to commit"}, nil
+	}
+	message, _ := git.RunGitCommand(repoRoot, "log", "-1", "--format=%B")
+	return map[string]interface{}{
+		"committed": true,
+		"sha":       after,
+		"message":   strings.TrimSpace(message),
+		"pushed":    a.Push,
+	}, nil
+}
+
+func toolSecurityScan(raw json.RawMessage) (interface{}, error) {
+	a, err := parseToolArgs(raw)
+	if err != nil {
+		return nil, err
+	}
+	repoRoot, err := git.GetRepoRoot(a.RepoPath)
+	if err != nil {
+		return nil, err
+	}
+	insecureFiles, leaks, err := processor.ScanStagedChanges(processor.OpenRepository(repoRoot))
+	if err != nil {
+		return nil, err
+	}
+	if insecureFiles == nil {
+		insecureFiles = []string{}
+	}
+	if leaks == nil {
+		leaks = []processor.LeakMatch{}
+	}
+	return map[string]interface{}{
+		"clean":          len(insecureFiles) == 0 && len(leaks) == 0,
+		"insecure_files": insecureFiles,
+		"leaks":          leaks,
+	}, nil
+}
+
+func toolListRepos(raw json.RawMessage) (interface{}, error) {
+	a, err := parseToolArgs(raw)
+	if err != nil {
+		return nil, err
+	}
+	repos, err := workspace.Discover(a.Path, workspace.Filter{Tags: a.Tags})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"repos": repos}, nil
+}
+
+func toolAnalyzeAccounts(raw json.RawMessage) (interface{}, error) {
+	a, err := parseToolArgs(raw)
+	if err != nil {
+		return nil, err
+	}
+	repoRoot, err := git.GetRepoRoot(a.RepoPath)
+	if err != nil {
+		return nil, err
+	}
+	return processor.AnalyzeAccounts(repoRoot)
+}
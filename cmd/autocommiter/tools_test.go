@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestToolSecurityScanFromSubdirectory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Skipf("git init failed: %v: %s", err, out)
+	}
+	token := "ghp_" + strings.Repeat("aB3dE5", 6)
+	if err := os.MkdirAll(filepath.Join(dir, "sub", "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.go"), []byte("package main\n\nconst token = \""+token+"\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("git", "-C", dir, "add", "config.go").CombinedOutput(); err != nil {
+		t.Fatalf("git add: %v: %s", err, out)
+	}
+
+	args, _ := json.Marshal(map[string]string{"repo_path": filepath.Join(dir, "sub", "pkg")})
+	res, err := toolSecurityScan(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clean := res.(map[string]interface{})["clean"]; clean != false {
+		t.Errorf("security_scan from a subdirectory: clean = %v; want false", clean)
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/nathfavour/autocommiter.go/internal/processor"
+	"github.com/nathfavour/autocommiter.go/internal/mcp"
 	"github.com/spf13/cobra"
 )
 
@@ -27,18 +27,7 @@ var vibeManifestCmd = &cobra.Command{
 			"protocol":    "stdio",
 			"command":     "autocommiter",
 			"inbuilt":     true,
-			"tool_set": []map[string]interface{}{
-				{
-					"name":        "generate_commit_message",
-					"description": "Generate a commit message for the staged changes",
-					"inputSchema": json.RawMessage(`{"type":"object","properties":{"repo_path":{"type":"string","description":"Path to the git repository"}}}`),
-				},
-				{
-					"name":        "summarize_changes",
-					"description": "Summarize staged changes as JSON",
-					"inputSchema": json.RawMessage(`{"type":"object","properties":{"repo_path":{"type":"string","description":"Path to the git repository"}}}`),
-				},
-			},
+			"tool_set":    agentTools(),
 		}
 		data, _ := json.MarshalIndent(manifest, "", "  ")
 		fmt.Println(string(data))
@@ -55,35 +44,32 @@ var executeCmd = &cobra.Command{
 		}
 
 		toolName := args[0]
-		var params struct {
-			RepoPath string `json:"repo_path"`
+		var tool *mcp.Tool
+		for _, t := range agentTools() {
+			if t.Name == toolName {
+				tool = &t
+				break
+			}
 		}
-		if len(args) > 1 {
-			_ = json.Unmarshal([]byte(args[1]), &params)
+		if tool == nil {
+			fmt.Printf("Unknown tool: %s\n", toolName)
+			os.Exit(1)
 		}
 
-		if params.RepoPath == "" {
-			params.RepoPath = "."
+		params := json.RawMessage("{}")
+		if len(args) > 1 && json.Valid([]byte(args[1])) {
+			params = json.RawMessage(args[1])
 		}
 
-		switch toolName {
-		case "generate_commit_message":
-			msg, err := processor.GenerateMessage(params.RepoPath, nil)
-			if err != nil {
-				fmt.Printf(`{"content": "Error: %v", "status": "error"}`+"\n", err)
-				return
-			}
-			fmt.Printf(`{"content": %q, "status": "success"}`+"\n", msg)
-		case "summarize_changes":
-			summary, err := processor.GetSummarizedChanges(params.RepoPath)
-			if err != nil {
-				fmt.Printf(`{"content": "Error: %v", "status": "error"}`+"\n", err)
-				return
-			}
-			fmt.Printf(`{"content": %q, "status": "success"}`+"\n", summary)
-		default:
-			fmt.Printf("Unknown tool: %s\n", toolName)
-			os.Exit(1)
+		reply := map[string]interface{}{"status": "success"}
+		out, err := tool.Handler(params)
+		if err != nil {
+			reply["content"] = "Error: " + err.Error()
+			reply["status"] = "error"
+		} else {
+			reply["content"] = mcp.NewToolResult(out).Content[0].Text
 		}
+		data, _ := json.Marshal(reply)
+		fmt.Println(string(data))
 	},
 }
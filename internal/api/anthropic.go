@@ -0,0 +1,75 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+const anthropicVersion = "2023-06-01"
+
+// AnthropicProvider uses Anthropic's Messages API.
+type AnthropicProvider struct {
+	BaseURL string
+	APIKey  string
+}
+
+type anthropicRequest struct {
+	Model     string    `json:"model"`
+	MaxTokens int       `json:"max_tokens"`
+	System    string    `json:"system,omitempty"`
+	Messages  []Message `json:"messages"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+}
+
+func (p *AnthropicProvider) Name() string {
+	return ProviderAnthropic
+}
+
+func (p *AnthropicProvider) DefaultModel() string {
+	return "claude-3-5-haiku-latest"
+}
+
+func (p *AnthropicProvider) Complete(messages []Message, model string) (string, error) {
+	// The Messages API takes the system prompt as a top-level field
+	// rather than as part of the conversation.
+	request := anthropicRequest{
+		Model:     model,
+		MaxTokens: 1024,
+	}
+	var system []string
+	for _, m := range messages {
+		if m.Role == "system" {
+			system = append(system, m.Content)
+			continue
+		}
+		request.Messages = append(request.Messages, m)
+	}
+	request.System = strings.Join(system, "\n\n")
+
+	headers := map[string]string{
+		"x-api-key":         p.APIKey,
+		"anthropic-version": anthropicVersion,
+	}
+
+	var responseData anthropicResponse
+	if err := postJSON(p.BaseURL+"/v1/messages", headers, request, &responseData); err != nil {
+		return "", err
+	}
+
+	var parts []string
+	for _, c := range responseData.Content {
+		if c.Type == "text" {
+			parts = append(parts, c.Text)
+		}
+	}
+	if len(parts) == 0 {
+		return "", fmt.Errorf("unexpected API response format")
+	}
+	return strings.TrimSpace(strings.Join(parts, "")), nil
+}
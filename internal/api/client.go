@@ -1,14 +1,8 @@
 package api
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"strings"
-
-	"github.com/nathfavour/autocommiter.go/internal/netutil"
 )
 
 type Message struct {
@@ -33,7 +27,7 @@ const SystemPrompt = `You are an expert software engineer specializing in high-q
 Your task is to generate a concise, professional, and descriptive commit message based on the provided diffs and file changes.
 
 Follow these rules:
-1. Format: Use the Conventional Commits specification (e.g., feat: ..., fix: ..., docs: ..., refactor: ..., chore: ..., style: ..., test: ...).
+%s
 2. Subject Line:
    - Must be under 50 characters if possible, never exceeding 72.
    - Use the imperative mood (e.g., "add", not "added" or "adds").
@@ -49,65 +43,135 @@ Context:
 - Current branch: %s
 `
 
-func CallInferenceAPI(apiKey, branch, prompt, model string) (string, error) {
-	url := "https://models.inference.ai.azure.com/chat/completions"
-
-	request := ChatCompletionRequest{
-		Messages: []Message{
-			{
-				Role:    "system",
-				Content: fmt.Sprintf(SystemPrompt, branch),
-			},
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-		Model: model,
-	}
+const conventionalFormatRule = "1. Format: Use the Conventional Commits specification (e.g., feat: ..., fix: ..., docs: ..., refactor: ..., chore: ..., style: ..., test: ...)."
+
+const learnedFormatRule = "1. Format: Follow the repository commit style described below. It overrides any other formatting convention."
+
+// PromptContext carries repository context that shapes the system prompt.
+type PromptContext struct {
+	Branch string
+	// Style describes the repository's own commit conventions. When set it
+	// replaces the default Conventional Commits format rule.
+	Style string
+	// Hint is a short instruction from the author, such as "mention the
+	// migration", added to the generation request.
+	Hint string
+	// Scopes are the monorepo packages the change touches.
+	Scopes []string
+	// Ticket says where to reference the issue the branch is for.
+	Ticket string
+}
 
-	body, err := json.Marshal(request)
-	if err != nil {
-		return "", err
+// BuildSystemPrompt renders SystemPrompt for ctx.
+func BuildSystemPrompt(ctx PromptContext) string {
+	formatRule := conventionalFormatRule
+	if ctx.Style != "" {
+		formatRule = learnedFormatRule
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
-	if err != nil {
-		return "", err
+	prompt := fmt.Sprintf(SystemPrompt, formatRule, ctx.Branch)
+	switch len(ctx.Scopes) {
+	case 0:
+	case 1:
+		prompt += fmt.Sprintf("- Package: the change is in the %q package; use exactly %q as the scope.\n", ctx.Scopes[0], ctx.Scopes[0])
+	default:
+		prompt += fmt.Sprintf("- Packages: the change spans %s; use the scope of the main change, or none.\n", strings.Join(ctx.Scopes, ", "))
 	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+apiKey)
-
-	client := netutil.GetHttpClient()
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
+	if ctx.Ticket != "" {
+		prompt += "- Ticket: " + ctx.Ticket + "\n"
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		respBody, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(respBody))
+	if ctx.Style != "" {
+		prompt += "\n" + ctx.Style
 	}
+	return prompt
+}
 
-	var responseData ChatCompletionResponse
-	if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-		return "", err
-	}
+// CallInferenceAPI sends prompt to GitHub Models using apiKey.
+func CallInferenceAPI(apiKey, branch, prompt, model string) (string, error) {
+	provider, _ := NewProvider(ProviderGitHub, "", apiKey)
+	return CallProvider(provider, PromptContext{Branch: branch}, prompt, model)
+}
 
-	if len(responseData.Choices) > 0 {
-		return strings.TrimSpace(responseData.Choices[0].Message.Content), nil
+// CallProvider sends prompt to provider together with the commit system prompt.
+func CallProvider(provider Provider, ctx PromptContext, prompt, model string) (string, error) {
+	messages := []Message{
+		{
+			Role:    "system",
+			Content: BuildSystemPrompt(ctx),
+		},
+		{
+			Role:    "user",
+			Content: prompt,
+		},
 	}
 
-	return "", fmt.Errorf("unexpected API response format")
+	return provider.Complete(messages, model)
 }
 
-func GenerateCommitMessage(apiKey, branch, fileNames, compressedJSON, model string) (string, error) {
+func GenerateCommitMessage(provider Provider, ctx PromptContext, fileNames, compressedJSON, model string) (string, error) {
 	prompt := fmt.Sprintf(
 		"Generate a commit message for the following changes:\n\nFiles changed:\n%s\n\nDetailed changes (JSON):\n%s",
 		fileNames, compressedJSON,
 	)
+	if ctx.Hint != "" {
+		prompt += fmt.Sprintf("\n\nAuthor's guidance for this message: %s", ctx.Hint)
+	}
+
+	return CallProvider(provider, ctx, prompt, model)
+}
+
+// PullRequestPrompt is the system prompt for describing a branch. It takes
+// the head and base branch names.
+const PullRequestPrompt = `You are an expert software engineer writing a pull request description for reviewers.
+Based on the commit messages and file changes of a branch, return a JSON object with these fields:
+- "title": a concise pull request title in the imperative mood, under 72 characters.
+- "summary": one or two short Markdown paragraphs explaining what changed and why.
+- "testing": a list of concrete steps a reviewer can follow to verify the change.
+- "breaking_changes": a list of changes that break existing users (APIs, CLI flags, configuration, data formats). Use an empty list if there are none.
+Return ONLY the JSON object. No markdown fences, no commentary.
+
+Context:
+- Branch: %s
+- Base: %s
+`
 
-	return CallInferenceAPI(apiKey, branch, prompt, model)
+// GeneratePullRequest asks provider to describe the commits and changes of
+// a branch. The reply is expected to be the JSON object PullRequestPrompt asks for.
+func GeneratePullRequest(provider Provider, head, base, commits, fileNames, compressedJSON, model string) (string, error) {
+	messages := []Message{
+		{
+			Role:    "system",
+			Content: fmt.Sprintf(PullRequestPrompt, head, base),
+		},
+		{
+			Role: "user",
+			Content: fmt.Sprintf(
+				"Describe this pull request.\n\nCommits (oldest first):\n%s\n\nFiles changed:\n%s\n\nDetailed changes (JSON):\n%s",
+				commits, fileNames, compressedJSON,
+			),
+		},
+	}
+
+	return provider.Complete(messages, model)
+}
+
+// ReleaseNotesPrompt is the system prompt for turning a changelog into
+// user-facing release notes.
+const ReleaseNotesPrompt = `You are a technical writer preparing release notes for the users of a software project.
+Rewrite the changelog you are given as release notes in Markdown:
+- Lead with the most important user-visible changes; merge related entries.
+- Explain each change in terms of what users can now do or what was fixed, not how it was implemented.
+- Keep a "Breaking changes" section with upgrade guidance when the changelog has breaking changes.
+- Leave out purely internal work (refactors, CI, tests, chores) unless it affects users.
+- Do not invent changes that are not in the changelog.
+Return ONLY the Markdown release notes.`
+
+// GenerateReleaseNotes asks provider to rewrite a Markdown changelog as
+// release notes.
+func GenerateReleaseNotes(provider Provider, changelog, model string) (string, error) {
+	messages := []Message{
+		{Role: "system", Content: ReleaseNotesPrompt},
+		{Role: "user", Content: "Changelog:\n\n" + changelog},
+	}
+	return provider.Complete(messages, model)
 }
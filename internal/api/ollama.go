@@ -0,0 +1,53 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OllamaProvider uses Ollama's native /api/chat endpoint.
+type OllamaProvider struct {
+	BaseURL string
+}
+
+type ollamaChatRequest struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	Stream   bool      `json:"stream"`
+}
+
+type ollamaChatResponse struct {
+	Message Message `json:"message"`
+	Error   string  `json:"error,omitempty"`
+}
+
+func (p *OllamaProvider) Name() string {
+	return ProviderOllama
+}
+
+func (p *OllamaProvider) DefaultModel() string {
+	return "llama3.2"
+}
+
+func (p *OllamaProvider) Complete(messages []Message, model string) (string, error) {
+	request := ollamaChatRequest{
+		Model:    model,
+		Messages: messages,
+		Stream:   false,
+	}
+
+	var responseData ollamaChatResponse
+	if err := postJSON(p.BaseURL+"/api/chat", nil, request, &responseData); err != nil {
+		return "", err
+	}
+
+	if responseData.Error != "" {
+		return "", fmt.Errorf("ollama error: %s", responseData.Error)
+	}
+
+	content := strings.TrimSpace(responseData.Message.Content)
+	if content == "" {
+		return "", fmt.Errorf("unexpected API response format")
+	}
+	return content, nil
+}
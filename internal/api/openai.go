@@ -0,0 +1,48 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OpenAIProvider talks to any endpoint implementing the OpenAI chat completions
+// API. GitHub Models, vLLM, LM Studio and the llama.cpp server all qualify.
+type OpenAIProvider struct {
+	name    string
+	BaseURL string
+	APIKey  string
+}
+
+func (p *OpenAIProvider) Name() string {
+	if p.name == "" {
+		return ProviderOpenAI
+	}
+	return p.name
+}
+
+func (p *OpenAIProvider) DefaultModel() string {
+	return "gpt-4o-mini"
+}
+
+func (p *OpenAIProvider) Complete(messages []Message, model string) (string, error) {
+	request := ChatCompletionRequest{
+		Messages: messages,
+		Model:    model,
+	}
+
+	headers := map[string]string{}
+	if p.APIKey != "" {
+		headers["Authorization"] = "Bearer " + p.APIKey
+	}
+
+	var responseData ChatCompletionResponse
+	if err := postJSON(p.BaseURL+"/chat/completions", headers, request, &responseData); err != nil {
+		return "", err
+	}
+
+	if len(responseData.Choices) > 0 {
+		return strings.TrimSpace(responseData.Choices[0].Message.Content), nil
+	}
+
+	return "", fmt.Errorf("unexpected API response format")
+}
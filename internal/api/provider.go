@@ -0,0 +1,138 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/nathfavour/autocommiter.go/internal/netutil"
+)
+
+// Supported provider identifiers, as used in the "provider" config field.
+const (
+	ProviderGitHub    = "github"
+	ProviderOpenAI    = "openai"
+	ProviderOllama    = "ollama"
+	ProviderAnthropic = "anthropic"
+)
+
+// GitHubModelsURL is the default endpoint of the github provider, the only
+// one the user's gh token is sent to.
+const GitHubModelsURL = "https://models.inference.ai.azure.com"
+
+// Provider is an LLM backend capable of answering a chat conversation.
+type Provider interface {
+	// Name returns the provider identifier (e.g. "github", "ollama").
+	Name() string
+	// DefaultModel is the model used when none is selected for the provider.
+	DefaultModel() string
+	// Complete sends the conversation to the backend and returns the reply text.
+	Complete(messages []Message, model string) (string, error)
+}
+
+// NewProvider builds the provider registered under name. An empty baseURL
+// selects the provider's public default endpoint.
+func NewProvider(name, baseURL, apiKey string) (Provider, error) {
+	baseURL = strings.TrimSuffix(strings.TrimSpace(baseURL), "/")
+
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", ProviderGitHub:
+		if baseURL == "" {
+			baseURL = GitHubModelsURL
+		}
+		return &OpenAIProvider{name: ProviderGitHub, BaseURL: baseURL, APIKey: apiKey}, nil
+	case ProviderOpenAI:
+		if baseURL == "" {
+			baseURL = "https://api.openai.com/v1"
+		}
+		return &OpenAIProvider{name: ProviderOpenAI, BaseURL: baseURL, APIKey: apiKey}, nil
+	case ProviderOllama:
+		if baseURL == "" {
+			baseURL = "http://localhost:11434"
+		}
+		return &OllamaProvider{BaseURL: baseURL}, nil
+	case ProviderAnthropic:
+		if baseURL == "" {
+			baseURL = "https://api.anthropic.com"
+		}
+		return &AnthropicProvider{BaseURL: baseURL, APIKey: apiKey}, nil
+	}
+
+	return nil, fmt.Errorf("unknown provider %q (expected one of: %s)", name, strings.Join(ProviderNames(), ", "))
+}
+
+// ProviderNames lists the identifiers accepted by NewProvider.
+func ProviderNames() []string {
+	return []string{ProviderGitHub, ProviderOpenAI, ProviderOllama, ProviderAnthropic}
+}
+
+// modelFamilies maps model name prefixes to the providers serving them.
+// Unknown names, such as local Ollama or vLLM models, are served by anyone.
+var modelFamilies = []struct {
+	prefix    string
+	providers []string
+}{
+	{"claude-", []string{ProviderAnthropic}},
+	{"gpt-", []string{ProviderGitHub, ProviderOpenAI}},
+	{"o1", []string{ProviderGitHub, ProviderOpenAI}},
+	{"o3", []string{ProviderGitHub, ProviderOpenAI}},
+	{"o4-", []string{ProviderGitHub, ProviderOpenAI}},
+}
+
+// ResolveModel returns selected, or the default model of p when selected is
+// empty or names a model of another provider, such as the built-in
+// "gpt-4o-mini" default with Ollama.
+func ResolveModel(p Provider, selected string) string {
+	selected = strings.TrimSpace(selected)
+	if selected == "" {
+		return p.DefaultModel()
+	}
+	lower := strings.ToLower(selected)
+	for _, f := range modelFamilies {
+		if !strings.HasPrefix(lower, f.prefix) {
+			continue
+		}
+		for _, name := range f.providers {
+			if name == p.Name() {
+				return selected
+			}
+		}
+		return p.DefaultModel()
+	}
+	return selected
+}
+
+// postJSON sends payload as JSON to url and decodes a successful response into out.
+func postJSON(url string, headers map[string]string, payload interface{}, out interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	client := netutil.GetHttpClient()
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,27 @@
+package api
+
+import "testing"
+
+func TestResolveModel(t *testing.T) {
+	tests := []struct {
+		provider, selected, want string
+	}{
+		{ProviderGitHub, "", "gpt-4o-mini"},
+		{ProviderGitHub, "Phi-3-mini-128k-instruct", "Phi-3-mini-128k-instruct"},
+		{ProviderOpenAI, "gpt-4o", "gpt-4o"},
+		{ProviderOllama, "gpt-4o-mini", "llama3.2"},
+		{ProviderOllama, "qwen2.5-coder:7b", "qwen2.5-coder:7b"},
+		{ProviderAnthropic, "gpt-4o-mini", "claude-3-5-haiku-latest"},
+		{ProviderAnthropic, "claude-sonnet-4-5", "claude-sonnet-4-5"},
+		{ProviderOpenAI, "claude-sonnet-4-5", "gpt-4o-mini"},
+	}
+	for _, tt := range tests {
+		p, err := NewProvider(tt.provider, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ResolveModel(p, tt.selected); got != tt.want {
+			t.Errorf("ResolveModel(%s, %q) = %q; want %q", tt.provider, tt.selected, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+// Package changelog groups Conventional Commits into release sections and
+// renders them as Markdown.
+package changelog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nathfavour/autocommiter.go/internal/conventional"
+)
+
+// OtherType collects commits that do not follow Conventional Commits or use
+// an unknown type.
+const OtherType = "other"
+
+// sectionTitles lists the known types in the order sections are rendered.
+var sectionTitles = []struct{ Type, Title string }{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance Improvements"},
+	{"revert", "Reverts"},
+	{"refactor", "Code Refactoring"},
+	{"docs", "Documentation"},
+	{"style", "Styles"},
+	{"test", "Tests"},
+	{"build", "Build System"},
+	{"ci", "Continuous Integration"},
+	{"chore", "Chores"},
+	{OtherType, "Other Changes"},
+}
+
+// Commit is a commit to include in a release.
+type Commit struct {
+	Hash    string
+	Message string
+}
+
+// Entry is one changelog line.
+type Entry struct {
+	Hash        string `json:"hash"`
+	Scope       string `json:"scope,omitempty"`
+	Description string `json:"description"`
+	Breaking    bool   `json:"breaking,omitempty"`
+}
+
+// ScopeGroup holds the entries of a section that share a scope.
+type ScopeGroup struct {
+	Scope   string  `json:"scope"`
+	Entries []Entry `json:"entries"`
+}
+
+// Section holds the entries of one commit type.
+type Section struct {
+	Type   string       `json:"type"`
+	Title  string       `json:"title"`
+	Scopes []ScopeGroup `json:"scopes"`
+}
+
+// BreakingChange is a note from a breaking commit.
+type BreakingChange struct {
+	Hash  string `json:"hash"`
+	Scope string `json:"scope,omitempty"`
+	Note  string `json:"note"`
+}
+
+// Release is the changelog of a revision range.
+type Release struct {
+	From string `json:"from,omitempty"`
+	To   string `json:"to"`
+	Date string `json:"date,omitempty"`
+	// Version is the tag at To, if any.
+	Version string `json:"version,omitempty"`
+	// Bump and NextVersion are the semver increment the commits call for
+	// and its result applied to From.
+	Bump        string           `json:"bump"`
+	NextVersion string           `json:"next_version,omitempty"`
+	Breaking    []BreakingChange `json:"breaking_changes"`
+	Sections    []Section        `json:"sections"`
+	// Notes are user-facing release notes, when requested.
+	Notes string `json:"notes,omitempty"`
+}
+
+// Build groups commits (newest first, as git log lists them) by type and
+// scope and suggests the next version after the from tag.
+func Build(from, to string, commits []Commit) *Release {
+	r := &Release{From: from, To: to, Breaking: []BreakingChange{}, Sections: []Section{}}
+
+	byType := make(map[string]map[string][]Entry)
+	var parsed []conventional.Commit
+	for _, raw := range commits {
+		c := conventional.Parse(raw.Message)
+		parsed = append(parsed, c)
+
+		typ := c.Type
+		if !known(typ) {
+			typ = OtherType
+		}
+		entry := Entry{Hash: raw.Hash, Scope: c.Scope, Description: c.Description, Breaking: c.Breaking}
+		if byType[typ] == nil {
+			byType[typ] = make(map[string][]Entry)
+		}
+		byType[typ][c.Scope] = append(byType[typ][c.Scope], entry)
+
+		for _, note := range c.BreakingChanges() {
+			r.Breaking = append(r.Breaking, BreakingChange{Hash: raw.Hash, Scope: c.Scope, Note: note})
+		}
+	}
+
+	for _, st := range sectionTitles {
+		scopes := byType[st.Type]
+		if len(scopes) == 0 {
+			continue
+		}
+		section := Section{Type: st.Type, Title: st.Title}
+		names := make([]string, 0, len(scopes))
+		for name := range scopes {
+			names = append(names, name)
+		}
+		// Unscoped entries first, then scopes alphabetically.
+		sort.Strings(names)
+		for _, name := range names {
+			section.Scopes = append(section.Scopes, ScopeGroup{Scope: name, Entries: scopes[name]})
+		}
+		r.Sections = append(r.Sections, section)
+	}
+
+	bump := conventional.BumpFor(parsed)
+	r.Bump = bump.String()
+	if next, err := conventional.NextVersion(from, bump); err == nil && bump != conventional.BumpNone {
+		r.NextVersion = next
+	}
+	return r
+}
+
+// Markdown renders the release as a changelog section.
+func (r *Release) Markdown() string {
+	var b strings.Builder
+	title := r.Version
+	if title == "" {
+		title = r.NextVersion
+	}
+	if title == "" {
+		title = "Unreleased"
+	}
+	if r.Date != "" {
+		title += " (" + r.Date + ")"
+	}
+	fmt.Fprintf(&b, "## %s\n", title)
+
+	if len(r.Breaking) > 0 {
+		b.WriteString("\n### ⚠ BREAKING CHANGES\n\n")
+		for _, c := range r.Breaking {
+			fmt.Fprintf(&b, "- %s%s (%s)\n", scopePrefix(c.Scope), oneLine(c.Note), short(c.Hash))
+		}
+	}
+	for _, s := range r.Sections {
+		fmt.Fprintf(&b, "\n### %s\n\n", s.Title)
+		for _, g := range s.Scopes {
+			for _, e := range g.Entries {
+				fmt.Fprintf(&b, "- %s%s (%s)\n", scopePrefix(e.Scope), e.Description, short(e.Hash))
+			}
+		}
+	}
+	if len(r.Sections) == 0 {
+		b.WriteString("\nNo changes.\n")
+	}
+	return b.String()
+}
+
+func known(typ string) bool {
+	for _, st := range sectionTitles {
+		if st.Type == typ && typ != OtherType {
+			return true
+		}
+	}
+	return false
+}
+
+func scopePrefix(scope string) string {
+	if scope == "" {
+		return ""
+	}
+	return "**" + scope + ":** "
+}
+
+// oneLine joins wrapped footer lines so a note fits a single list item.
+func oneLine(note string) string {
+	return strings.Join(strings.Fields(note), " ")
+}
+
+func short(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -0,0 +1,80 @@
+package changelog
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func testCommits() []Commit {
+	return []Commit{
+		{Hash: "1111111aaaa", Message: "✨ feat(api): add pagination"},
+		{Hash: "2222222bbbb", Message: "fix: handle empty config\n\nBREAKING CHANGE: Load returns an error\nfor empty files"},
+		{Hash: "3333333cccc", Message: "feat: dark mode"},
+		{Hash: "4444444dddd", Message: "chore(deps): bump cobra"},
+		{Hash: "5555555eeee", Message: "Update README"},
+		{Hash: "6666666ffff", Message: "feat(api)!: drop v1 routes"},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	r := Build("v1.4.2", "HEAD", testCommits())
+
+	if r.Bump != "major" || r.NextVersion != "v2.0.0" {
+		t.Errorf("Bump = %s, NextVersion = %s; want major, v2.0.0", r.Bump, r.NextVersion)
+	}
+
+	var types []string
+	for _, s := range r.Sections {
+		types = append(types, s.Type)
+	}
+	if got := strings.Join(types, ","); got != "feat,fix,chore,other" {
+		t.Errorf("section order = %s", got)
+	}
+
+	feat := r.Sections[0]
+	if len(feat.Scopes) != 2 || feat.Scopes[0].Scope != "" || feat.Scopes[1].Scope != "api" || len(feat.Scopes[1].Entries) != 2 {
+		t.Errorf("feat scopes = %+v", feat.Scopes)
+	}
+	if len(r.Breaking) != 2 || r.Breaking[0].Note != "Load returns an error\nfor empty files" || r.Breaking[1].Note != "drop v1 routes" {
+		t.Errorf("Breaking = %+v", r.Breaking)
+	}
+}
+
+func TestBuildWithoutReleaseWorthyCommits(t *testing.T) {
+	r := Build("v1.0.0", "HEAD", []Commit{{Hash: "abc", Message: "docs: typo"}})
+	if r.Bump != "none" || r.NextVersion != "" {
+		t.Errorf("Bump = %s, NextVersion = %q; want none and no suggestion", r.Bump, r.NextVersion)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	r := Build("v0.9.0", "HEAD", testCommits())
+	r.Date = "2026-10-16"
+	md := r.Markdown()
+
+	for _, want := range []string{
+		"## v0.10.0 (2026-10-16)\n",
+		"### ⚠ BREAKING CHANGES\n\n- Load returns an error for empty files (2222222)\n- **api:** drop v1 routes (6666666)\n",
+		"### Features\n\n- dark mode (3333333)\n- **api:** add pagination (1111111)\n- **api:** drop v1 routes (6666666)\n",
+		"### Other Changes\n\n- Update README (5555555)\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown() missing %q:\n%s", want, md)
+		}
+	}
+
+	if md := (&Release{To: "HEAD"}).Markdown(); md != "## Unreleased\n\nNo changes.\n" {
+		t.Errorf("empty Markdown() = %q", md)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	data, err := json.Marshal(Build("", "HEAD", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"breaking_changes":[]`) || !strings.Contains(string(data), `"sections":[]`) {
+		t.Errorf("empty release JSON = %s", data)
+	}
+}
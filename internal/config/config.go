@@ -5,44 +5,102 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/nathfavour/autocommiter.go/internal/lint"
+	"github.com/nathfavour/autocommiter.go/internal/scope"
+	"github.com/nathfavour/autocommiter.go/internal/secrets"
+	"github.com/nathfavour/autocommiter.go/internal/ticket"
+	"github.com/nathfavour/autocommiter.go/internal/workspace"
 )
 
 type Config struct {
 	APIKey             *string  `json:"api_key,omitempty"`
 	SelectedModel      *string  `json:"selected_model,omitempty"`
+	Provider           *string  `json:"provider,omitempty"`
+	ProviderBaseURL    *string  `json:"provider_base_url,omitempty"`
+	ProviderAPIKey     *string  `json:"provider_api_key,omitempty"`
 	EnableGitmoji      *bool    `json:"enable_gitmoji,omitempty"`
 	UpdateGitignore    *bool    `json:"update_gitignore,omitempty"`
 	SecureMode         *bool    `json:"secure_mode,omitempty"`
 	SecureDetectPII    *bool    `json:"secure_detect_pii,omitempty"`
 	SecureDetectBulky  *bool    `json:"secure_detect_bulky,omitempty"`
+	BulkyFilePolicy    *string  `json:"bulky_file_policy,omitempty"`
+	BulkyThresholdMB   *int     `json:"bulky_threshold_mb,omitempty"`
 	SkipConfirmation   *bool    `json:"skip_confirmation,omitempty"`
+	StagingPolicy      *string  `json:"staging_policy,omitempty"`
+	SubmodulePolicy    *string  `json:"submodule_policy,omitempty"`
+	GitBackend         *string  `json:"git_backend,omitempty"`
+	OfflineFallback    *bool    `json:"offline_fallback,omitempty"`
+	LearnStyle         *bool    `json:"learn_style,omitempty"`
+	StyleSampleSize    *int     `json:"style_sample_size,omitempty"`
 	PreferNoReplyEmail *bool    `json:"prefer_noreply_email,omitempty"`
 	EnableForkSync     *bool    `json:"enable_fork_sync,omitempty"`
 	ForkUsername       *string  `json:"fork_username,omitempty"`
 	GitignorePatterns  []string `json:"gitignore_patterns,omitempty"`
+	// SecretRules are gitleaks-style rules added to the built-in ruleset; a
+	// rule with a built-in ID replaces it.
+	SecretRules []secrets.Rule `json:"secret_rules,omitempty"`
+	// SecretRulesFile points to a gitleaks TOML or JSON rules file, relative
+	// to the repository root.
+	SecretRulesFile *string `json:"secret_rules_file,omitempty"`
+	// SecretEntropy tunes detection of high-entropy strings no rule matches.
+	SecretEntropy *secrets.EntropyConfig `json:"secret_entropy,omitempty"`
+	// RedactDiffs masks secrets, PII and high-entropy strings in diffs before
+	// they are sent to an LLM.
+	RedactDiffs *bool `json:"redact_diffs,omitempty"`
+	// LLMExcludePaths are globs (e.g. "config/prod/**") whose files are never
+	// sent to an LLM.
+	LLMExcludePaths []string `json:"llm_exclude_paths,omitempty"`
+	// CommitLint holds messages to rules such as allowed types and scopes,
+	// subject length and required trailers.
+	CommitLint *lint.Config `json:"commit_lint,omitempty"`
+	// Scope maps monorepo paths to commit scopes.
+	Scope *scope.Config `json:"scope,omitempty"`
+	// Ticket extracts issue references from branch names into messages.
+	Ticket *ticket.Config `json:"ticket,omitempty"`
 }
 
 func DefaultConfig() Config {
 	apiKey := ""
 	selectedModel := "gpt-4o-mini"
+	provider := "github"
 	enableGitmoji := false
 	updateGitignore := false
 	secureMode := true
 	secureDetectPII := true
 	secureDetectBulky := true
+	bulkyFilePolicy := "ignore"
+	bulkyThresholdMB := 5
 	skipConfirmation := false
+	stagingPolicy := "tracked"
+	submodulePolicy := "include"
+	gitBackend := "exec"
+	offlineFallback := true
+	redactDiffs := true
+	learnStyle := true
+	styleSampleSize := 50
 	preferNoReplyEmail := true
 	enableForkSync := false
 
 	return Config{
 		APIKey:             &apiKey,
 		SelectedModel:      &selectedModel,
+		Provider:           &provider,
 		EnableGitmoji:      &enableGitmoji,
 		UpdateGitignore:    &updateGitignore,
 		SecureMode:         &secureMode,
 		SecureDetectPII:    &secureDetectPII,
 		SecureDetectBulky:  &secureDetectBulky,
+		BulkyFilePolicy:    &bulkyFilePolicy,
+		BulkyThresholdMB:   &bulkyThresholdMB,
 		SkipConfirmation:   &skipConfirmation,
+		StagingPolicy:      &stagingPolicy,
+		SubmodulePolicy:    &submodulePolicy,
+		GitBackend:         &gitBackend,
+		OfflineFallback:    &offlineFallback,
+		RedactDiffs:        &redactDiffs,
+		LearnStyle:         &learnStyle,
+		StyleSampleSize:    &styleSampleSize,
 		PreferNoReplyEmail: &preferNoReplyEmail,
 		EnableForkSync:     &enableForkSync,
 		GitignorePatterns:  []string{"*.env*", ".env*", "docx/", ".docx/"},
@@ -104,12 +162,24 @@ func LoadMergedConfig(repoRoot string) (Config, error) {
 		return cfg, nil
 	}
 
+	// Workspace overrides sit between the global and the repository config.
+	if overrides, err := workspace.Overrides(repoRoot); err == nil && overrides != nil {
+		if content, err := json.Marshal(overrides); err == nil {
+			var wsCfg Config
+			if err := json.Unmarshal(content, &wsCfg); err == nil {
+				dropProviderSettings(&wsCfg)
+				mergeConfigs(&cfg, wsCfg)
+			}
+		}
+	}
+
 	repoConfigPath := filepath.Join(repoRoot, ".autocommiter.json")
 	if _, err := os.Stat(repoConfigPath); err == nil {
 		content, err := os.ReadFile(repoConfigPath)
 		if err == nil {
 			var repoCfg Config
 			if err := json.Unmarshal(content, &repoCfg); err == nil {
+				dropProviderSettings(&repoCfg)
 				mergeConfigs(&cfg, repoCfg)
 			}
 		}
@@ -118,6 +188,16 @@ func LoadMergedConfig(repoRoot string) (Config, error) {
 	return cfg, nil
 }
 
+// dropProviderSettings clears the settings that decide where diffs and
+// credentials are sent. A cloned repository's .autocommiter.json could
+// otherwise point the user's token at any server, so only the global config
+// sets them.
+func dropProviderSettings(cfg *Config) {
+	cfg.Provider = nil
+	cfg.ProviderBaseURL = nil
+	cfg.ProviderAPIKey = nil
+}
+
 func mergeConfigs(base *Config, override Config) {
 	if override.APIKey != nil {
 		base.APIKey = override.APIKey
@@ -125,6 +205,15 @@ func mergeConfigs(base *Config, override Config) {
 	if override.SelectedModel != nil {
 		base.SelectedModel = override.SelectedModel
 	}
+	if override.Provider != nil {
+		base.Provider = override.Provider
+	}
+	if override.ProviderBaseURL != nil {
+		base.ProviderBaseURL = override.ProviderBaseURL
+	}
+	if override.ProviderAPIKey != nil {
+		base.ProviderAPIKey = override.ProviderAPIKey
+	}
 	if override.EnableGitmoji != nil {
 		base.EnableGitmoji = override.EnableGitmoji
 	}
@@ -140,9 +229,33 @@ func mergeConfigs(base *Config, override Config) {
 	if override.SecureDetectBulky != nil {
 		base.SecureDetectBulky = override.SecureDetectBulky
 	}
+	if override.BulkyFilePolicy != nil {
+		base.BulkyFilePolicy = override.BulkyFilePolicy
+	}
+	if override.BulkyThresholdMB != nil {
+		base.BulkyThresholdMB = override.BulkyThresholdMB
+	}
 	if override.SkipConfirmation != nil {
 		base.SkipConfirmation = override.SkipConfirmation
 	}
+	if override.StagingPolicy != nil {
+		base.StagingPolicy = override.StagingPolicy
+	}
+	if override.SubmodulePolicy != nil {
+		base.SubmodulePolicy = override.SubmodulePolicy
+	}
+	if override.GitBackend != nil {
+		base.GitBackend = override.GitBackend
+	}
+	if override.OfflineFallback != nil {
+		base.OfflineFallback = override.OfflineFallback
+	}
+	if override.LearnStyle != nil {
+		base.LearnStyle = override.LearnStyle
+	}
+	if override.StyleSampleSize != nil {
+		base.StyleSampleSize = override.StyleSampleSize
+	}
 	if override.PreferNoReplyEmail != nil {
 		base.PreferNoReplyEmail = override.PreferNoReplyEmail
 	}
@@ -155,6 +268,29 @@ func mergeConfigs(base *Config, override Config) {
 	if len(override.GitignorePatterns) > 0 {
 		base.GitignorePatterns = override.GitignorePatterns
 	}
+	if len(override.SecretRules) > 0 {
+		base.SecretRules = secrets.Merge(base.SecretRules, override.SecretRules)
+	}
+	if override.SecretRulesFile != nil {
+		base.SecretRulesFile = override.SecretRulesFile
+	}
+	if override.SecretEntropy != nil {
+		base.SecretEntropy = override.SecretEntropy
+	}
+	if override.RedactDiffs != nil {
+		base.RedactDiffs = override.RedactDiffs
+	}
+	if override.CommitLint != nil {
+		base.CommitLint = override.CommitLint
+	}
+	if override.Scope != nil {
+		base.Scope = override.Scope
+	}
+	if override.Ticket != nil {
+		base.Ticket = override.Ticket
+	}
+	// Exclusions accumulate so a repository cannot re-expose globally excluded paths.
+	base.LLMExcludePaths = append(base.LLMExcludePaths, override.LLMExcludePaths...)
 }
 
 func SaveConfig(config Config) error {
@@ -0,0 +1,148 @@
+// Package conventional parses Conventional Commits messages, including the
+// gitmoji-prefixed form autocommiter produces.
+package conventional
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Footer is a trailer such as "Refs: #12" or "BREAKING CHANGE: ...".
+type Footer struct {
+	Token string `json:"token"`
+	Value string `json:"value"`
+}
+
+// Commit is a parsed commit message.
+type Commit struct {
+	// Conventional reports whether the subject follows the specification.
+	// When false only Subject, Description and Body are set.
+	Conventional bool     `json:"conventional"`
+	Emoji        string   `json:"emoji,omitempty"`
+	Type         string   `json:"type,omitempty"`
+	Scope        string   `json:"scope,omitempty"`
+	Breaking     bool     `json:"breaking"`
+	Description  string   `json:"description"`
+	Subject      string   `json:"subject"`
+	Body         string   `json:"body,omitempty"`
+	Footers      []Footer `json:"footers,omitempty"`
+}
+
+var (
+	headerRegex = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^()]*)\))?(!)?: (.+)$`)
+	footerRegex = regexp.MustCompile(`^(BREAKING[ -]CHANGE|[A-Za-z][\w-]*)(?:: | #)(.*)$`)
+	// shortcodeRegex matches gitmoji shortcodes such as ":sparkles:".
+	shortcodeRegex = regexp.MustCompile(`^:[a-z0-9_+-]+:$`)
+)
+
+// Parse parses message. It never fails: messages that do not follow the
+// specification come back with Conventional unset.
+func Parse(message string) Commit {
+	message = strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n"))
+	subject, rest, _ := strings.Cut(message, "\n")
+	subject = strings.TrimSpace(subject)
+
+	c := Commit{Subject: subject, Description: subject}
+	header := subject
+	if emoji, remainder, ok := splitEmoji(subject); ok {
+		c.Emoji = emoji
+		header = remainder
+		c.Description = remainder
+	}
+
+	c.Body, c.Footers = splitFooters(strings.TrimSpace(rest))
+
+	m := headerRegex.FindStringSubmatch(header)
+	if m == nil {
+		return c
+	}
+	c.Conventional = true
+	c.Type = strings.ToLower(m[1])
+	c.Scope = strings.TrimSpace(m[2])
+	c.Breaking = m[3] == "!"
+	c.Description = strings.TrimSpace(m[4])
+	for _, f := range c.Footers {
+		if isBreakingToken(f.Token) {
+			c.Breaking = true
+		}
+	}
+	return c
+}
+
+// BreakingChanges describes what the commit breaks: the BREAKING CHANGE
+// footers, or the description for a commit only marked with "!".
+func (c Commit) BreakingChanges() []string {
+	if !c.Breaking {
+		return nil
+	}
+	var notes []string
+	for _, f := range c.Footers {
+		if isBreakingToken(f.Token) {
+			notes = append(notes, f.Value)
+		}
+	}
+	if len(notes) == 0 {
+		notes = append(notes, c.Description)
+	}
+	return notes
+}
+
+// Footer returns the value of the first footer with token, matched case
+// insensitively.
+func (c Commit) Footer(token string) (string, bool) {
+	for _, f := range c.Footers {
+		if strings.EqualFold(f.Token, token) {
+			return f.Value, true
+		}
+	}
+	return "", false
+}
+
+func isBreakingToken(token string) bool {
+	return token == "BREAKING CHANGE" || token == "BREAKING-CHANGE"
+}
+
+// splitEmoji removes a leading gitmoji, either the character itself (with
+// any variation selector) or a ":shortcode:".
+func splitEmoji(subject string) (string, string, bool) {
+	first, remainder, ok := strings.Cut(subject, " ")
+	if !ok {
+		return "", "", false
+	}
+	if shortcodeRegex.MatchString(first) {
+		return first, strings.TrimSpace(remainder), true
+	}
+	for _, r := range first {
+		if r < 0x80 || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return "", "", false
+		}
+	}
+	return first, strings.TrimSpace(remainder), true
+}
+
+// splitFooters separates the trailing footer paragraph from the body. A
+// footer's value continues on following lines until the next footer.
+func splitFooters(rest string) (string, []Footer) {
+	if rest == "" {
+		return "", nil
+	}
+	paragraphs := strings.Split(rest, "\n\n")
+	last := paragraphs[len(paragraphs)-1]
+	lines := strings.Split(last, "\n")
+	if !footerRegex.MatchString(lines[0]) {
+		return rest, nil
+	}
+
+	var footers []Footer
+	for _, line := range lines {
+		if m := footerRegex.FindStringSubmatch(line); m != nil {
+			footers = append(footers, Footer{Token: m[1], Value: strings.TrimSpace(m[2])})
+			continue
+		}
+		f := &footers[len(footers)-1]
+		f.Value = strings.TrimSpace(f.Value + "\n" + line)
+	}
+	body := strings.TrimSpace(strings.Join(paragraphs[:len(paragraphs)-1], "\n\n"))
+	return body, footers
+}
@@ -0,0 +1,139 @@
+package conventional
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nathfavour/autocommiter.go/internal/gitmoji"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		message string
+		want    Commit
+	}{
+		{
+			message: "feat(auth): add token refresh",
+			want:    Commit{Conventional: true, Type: "feat", Scope: "auth", Description: "add token refresh", Subject: "feat(auth): add token refresh"},
+		},
+		{
+			message: "✨ feat: add login",
+			want:    Commit{Conventional: true, Emoji: "✨", Type: "feat", Description: "add login", Subject: "✨ feat: add login"},
+		},
+		{
+			message: "⬆️ build(deps)!: bump go to 1.22",
+			want:    Commit{Conventional: true, Emoji: "⬆️", Type: "build", Scope: "deps", Breaking: true, Description: "bump go to 1.22", Subject: "⬆️ build(deps)!: bump go to 1.22"},
+		},
+		{
+			message: ":bug: Fix: crash on empty config",
+			want:    Commit{Conventional: true, Emoji: ":bug:", Type: "fix", Description: "crash on empty config", Subject: ":bug: Fix: crash on empty config"},
+		},
+		{
+			message: "fix: handle nil\n\nThe loader crashed.\n\nRefs: #12\nBREAKING CHANGE: Load now returns an error\n  for empty files",
+			want: Commit{
+				Conventional: true, Type: "fix", Breaking: true, Description: "handle nil", Subject: "fix: handle nil",
+				Body: "The loader crashed.",
+				Footers: []Footer{
+					{Token: "Refs", Value: "#12"},
+					{Token: "BREAKING CHANGE", Value: "Load now returns an error\n  for empty files"},
+				},
+			},
+		},
+		{
+			message: "Merge branch 'main' into feature",
+			want:    Commit{Description: "Merge branch 'main' into feature", Subject: "Merge branch 'main' into feature"},
+		},
+		{
+			message: "🎉 initial commit",
+			want:    Commit{Emoji: "🎉", Description: "initial commit", Subject: "🎉 initial commit"},
+		},
+		{
+			message: "docs: explain setup\n\nCloses #4",
+			want:    Commit{Conventional: true, Type: "docs", Description: "explain setup", Subject: "docs: explain setup", Footers: []Footer{{Token: "Closes", Value: "4"}}},
+		},
+		{
+			message: "docs: explain setup\n\nSee the notes: they cover it.",
+			want:    Commit{Conventional: true, Type: "docs", Description: "explain setup", Subject: "docs: explain setup", Body: "See the notes: they cover it."},
+		},
+	}
+	for _, tt := range tests {
+		if got := Parse(tt.message); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) =\n  %+v\nwant\n  %+v", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestParseGitmojifiedMessages(t *testing.T) {
+	for _, msg := range []string{"feat(api): add pagination", "fix: remove unused flag", "docs: update readme"} {
+		c := Parse(gitmoji.GetGitmojifiedMessage(msg))
+		if !c.Conventional || c.Emoji == "" || c.Subject == msg {
+			t.Errorf("Parse(gitmojified %q) = %+v", msg, c)
+		}
+		if want := Parse(msg); c.Type != want.Type || c.Scope != want.Scope || c.Description != want.Description {
+			t.Errorf("gitmoji changed the parse of %q: %+v vs %+v", msg, c, want)
+		}
+	}
+}
+
+func TestBreakingChanges(t *testing.T) {
+	if got := Parse("feat!: drop v1").BreakingChanges(); !reflect.DeepEqual(got, []string{"drop v1"}) {
+		t.Errorf("bang-only = %q", got)
+	}
+	got := Parse("fix: y\n\nBREAKING-CHANGE: a\nBREAKING CHANGE: b").BreakingChanges()
+	if !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("footers = %q", got)
+	}
+	if got := Parse("feat: x\n\nmentions BREAKING CHANGE: inline").BreakingChanges(); got != nil {
+		t.Errorf("inline mention = %q; want none", got)
+	}
+}
+
+func TestNextVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		bump    Bump
+		want    string
+	}{
+		{"v1.4.2", BumpMajor, "v2.0.0"},
+		{"1.4.2", BumpMinor, "1.5.0"},
+		{"v1.4.2", BumpPatch, "v1.4.3"},
+		{"v1.4.2", BumpNone, "v1.4.2"},
+		{"v0.3.1", BumpMajor, "v0.4.0"},
+		{"v2.0.0-rc.1", BumpPatch, "v2.0.1"},
+		{"", BumpMinor, "0.1.0"},
+	}
+	for _, tt := range tests {
+		got, err := NextVersion(tt.version, tt.bump)
+		if err != nil || got != tt.want {
+			t.Errorf("NextVersion(%q, %s) = %q, %v; want %q", tt.version, tt.bump, got, err, tt.want)
+		}
+	}
+	if _, err := NextVersion("release-7", BumpPatch); err == nil {
+		t.Error("NextVersion accepted a non-semver tag")
+	}
+}
+
+func TestBumpFor(t *testing.T) {
+	commits := func(msgs ...string) []Commit {
+		var cs []Commit
+		for _, m := range msgs {
+			cs = append(cs, Parse(m))
+		}
+		return cs
+	}
+	tests := []struct {
+		commits []Commit
+		want    Bump
+	}{
+		{commits("chore: tidy", "docs: typo"), BumpNone},
+		{commits("chore: tidy", "fix: crash"), BumpPatch},
+		{commits("fix: crash", "feat: add x", "perf: faster"), BumpMinor},
+		{commits("feat: add x", "refactor!: rename package"), BumpMajor},
+		{commits("✨ feat(ui): dark mode"), BumpMinor},
+	}
+	for _, tt := range tests {
+		if got := BumpFor(tt.commits); got != tt.want {
+			t.Errorf("BumpFor(%v) = %s; want %s", tt.commits, got, tt.want)
+		}
+	}
+}
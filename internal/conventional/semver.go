@@ -0,0 +1,82 @@
+package conventional
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Bump is a semantic version increment.
+type Bump int
+
+const (
+	BumpNone Bump = iota
+	BumpPatch
+	BumpMinor
+	BumpMajor
+)
+
+func (b Bump) String() string {
+	switch b {
+	case BumpPatch:
+		return "patch"
+	case BumpMinor:
+		return "minor"
+	case BumpMajor:
+		return "major"
+	}
+	return "none"
+}
+
+// BumpFor returns the increment commits call for: major for breaking
+// changes, minor for features, patch for fixes and performance work.
+func BumpFor(commits []Commit) Bump {
+	bump := BumpNone
+	for _, c := range commits {
+		next := BumpNone
+		switch {
+		case c.Breaking:
+			next = BumpMajor
+		case c.Type == "feat":
+			next = BumpMinor
+		case c.Type == "fix" || c.Type == "perf":
+			next = BumpPatch
+		}
+		if next > bump {
+			bump = next
+		}
+	}
+	return bump
+}
+
+var versionRegex = regexp.MustCompile(`^(v?)(\d+)\.(\d+)\.(\d+)(?:[-+].*)?$`)
+
+// NextVersion applies bump to version ("1.4.2" or "v1.4.2"), keeping the
+// "v" prefix. Pre-release and build suffixes are dropped. Before 1.0.0 a
+// breaking change only bumps the minor version. An empty version counts as
+// 0.0.0.
+func NextVersion(version string, bump Bump) (string, error) {
+	if version == "" {
+		version = "0.0.0"
+	}
+	m := versionRegex.FindStringSubmatch(version)
+	if m == nil {
+		return "", fmt.Errorf("%q is not a semantic version", version)
+	}
+	major, _ := strconv.Atoi(m[2])
+	minor, _ := strconv.Atoi(m[3])
+	patch, _ := strconv.Atoi(m[4])
+
+	if bump == BumpMajor && major == 0 {
+		bump = BumpMinor
+	}
+	switch bump {
+	case BumpMajor:
+		major, minor, patch = major+1, 0, 0
+	case BumpMinor:
+		minor, patch = minor+1, 0
+	case BumpPatch:
+		patch++
+	}
+	return fmt.Sprintf("%s%d.%d.%d", m[1], major, minor, patch), nil
+}
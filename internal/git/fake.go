@@ -0,0 +1,437 @@
+package git
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"testing/fstest"
+)
+
+// FakeCommit is a commit recorded by FakeRepository.
+type FakeCommit struct {
+	Hash    string
+	Message string
+	Name    string
+	Email   string
+	// Files is the snapshot the commit records.
+	Files map[string]string
+}
+
+// FakeRepository is an in-memory Repository for tests. HEAD, the index and
+// the working tree are maps of path to content, and commits and pushes are
+// recorded instead of run. Ignore files are not honored.
+type FakeRepository struct {
+	// Branch is the checked out branch.
+	Branch string
+	// UserName and UserEmail are the local identity commits are made with.
+	UserName  string
+	UserEmail string
+	// Remote is the "owner/name" of origin, empty without a remote.
+	Remote string
+	// PushErr, when set, is returned by Push.
+	PushErr error
+	// LFSAvailable simulates an installed git-lfs. Files that .gitattributes
+	// routes through the LFS filter are staged as pointers.
+	LFSAvailable bool
+
+	// Commits are the commits made, oldest first.
+	Commits []FakeCommit
+	// Pushes counts successful pushes.
+	Pushes int
+
+	mu       sync.Mutex
+	root     string
+	index    map[string]string
+	worktree fstest.MapFS
+}
+
+var _ Repository = (*FakeRepository)(nil)
+
+// NewFakeRepository returns a repository rooted at root whose HEAD, index and
+// working tree hold files. Files are committed as an initial commit unless
+// there are none.
+func NewFakeRepository(root string, files map[string]string) *FakeRepository {
+	r := &FakeRepository{
+		Branch:    "main",
+		UserName:  "Test",
+		UserEmail: "test@example.com",
+		root:      root,
+		index:     make(map[string]string),
+		worktree:  make(fstest.MapFS),
+	}
+	for name, content := range files {
+		r.index[name] = content
+		r.worktree[name] = &fstest.MapFile{Data: []byte(content), Mode: 0644}
+	}
+	if len(files) > 0 {
+		r.commit("Initial commit")
+	}
+	return r
+}
+
+// RemoveFile deletes a working tree file.
+func (r *FakeRepository) RemoveFile(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.worktree, name)
+}
+
+// Staged returns the index content of name and whether it is in the index.
+func (r *FakeRepository) Staged(name string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	content, ok := r.index[name]
+	return content, ok
+}
+
+func (r *FakeRepository) Root() string { return r.root }
+
+func (r *FakeRepository) Worktree() fs.FS {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	snapshot := make(fstest.MapFS, len(r.worktree))
+	for name, f := range r.worktree {
+		snapshot[name] = f
+	}
+	return snapshot
+}
+
+func (r *FakeRepository) WriteFile(name string, data []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.worktree[name] = &fstest.MapFile{Data: append([]byte(nil), data...), Mode: 0644}
+	return nil
+}
+
+func (r *FakeRepository) head() map[string]string {
+	if len(r.Commits) == 0 {
+		return nil
+	}
+	return r.Commits[len(r.Commits)-1].Files
+}
+
+func (r *FakeRepository) StagedFiles() ([]string, error) {
+	statuses, _ := r.StagedNameStatus()
+	files := make([]string, 0, len(statuses))
+	for f := range statuses {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (r *FakeRepository) StagedNameStatus() (map[string]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	head := r.head()
+	statuses := make(map[string]string)
+	for f, content := range r.index {
+		old, ok := head[f]
+		switch {
+		case !ok:
+			statuses[f] = "A"
+		case old != content:
+			statuses[f] = "M"
+		}
+	}
+	for f := range head {
+		if _, ok := r.index[f]; !ok {
+			statuses[f] = "D"
+		}
+	}
+	return statuses, nil
+}
+
+func (r *FakeRepository) UnstagedFiles() ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var files []string
+	for f, content := range r.index {
+		if wt, ok := r.worktree[f]; !ok || string(wt.Data) != content {
+			files = append(files, f)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (r *FakeRepository) UntrackedFiles() ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var files []string
+	for f := range r.worktree {
+		if _, ok := r.index[f]; !ok {
+			files = append(files, f)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (r *FakeRepository) Stage(files []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, f := range files {
+		if wt, ok := r.worktree[f]; ok {
+			r.index[f] = r.clean(f, wt.Data)
+			continue
+		}
+		if _, ok := r.index[f]; !ok {
+			return fmt.Errorf("pathspec '%s' did not match any files", f)
+		}
+		delete(r.index, f)
+	}
+	return nil
+}
+
+func (r *FakeRepository) Unstage(files []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	head := r.head()
+	for _, f := range files {
+		if content, ok := head[f]; ok {
+			r.index[f] = content
+		} else {
+			delete(r.index, f)
+		}
+	}
+	return nil
+}
+
+// StagedDiff returns a diff replacing the whole HEAD version of file with the
+// staged one.
+func (r *FakeRepository) StagedDiff(file string) (string, error) {
+	return r.StagedDiffUnified(file)
+}
+
+func (r *FakeRepository) StagedDiffNumstat(file string) (string, error) {
+	old, updated, ok := r.versions(file)
+	if !ok {
+		return "", nil
+	}
+	return fmt.Sprintf("%d\t%d\t%s", len(lines(updated)), len(lines(old)), file), nil
+}
+
+func (r *FakeRepository) StagedDiffUnified(file string) (string, error) {
+	old, updated, ok := r.versions(file)
+	if !ok {
+		return "", nil
+	}
+	oldLines, newLines := lines(old), lines(updated)
+	var b strings.Builder
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n", file, file, file, file)
+	fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(len(oldLines)), hunkRange(len(newLines)))
+	for _, l := range oldLines {
+		b.WriteString("-" + l + "\n")
+	}
+	for _, l := range newLines {
+		b.WriteString("+" + l + "\n")
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// versions returns the HEAD and staged content of file, and false when they
+// are the same.
+func (r *FakeRepository) versions(file string) (string, string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old, inHead := r.head()[file]
+	updated, inIndex := r.index[file]
+	return old, updated, inHead != inIndex || old != updated
+}
+
+func lines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+func hunkRange(n int) string {
+	if n == 0 {
+		return "0,0"
+	}
+	return fmt.Sprintf("1,%d", n)
+}
+
+func (r *FakeRepository) Commit(message string) error {
+	if staged, _ := r.StagedFiles(); len(staged) == 0 {
+		return fmt.Errorf("nothing to commit")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commit(message)
+	return nil
+}
+
+func (r *FakeRepository) commit(message string) {
+	snapshot := make(map[string]string, len(r.index))
+	for f, content := range r.index {
+		snapshot[f] = content
+	}
+	hash := sha1.Sum([]byte(fmt.Sprintf("%d\x00%s", len(r.Commits), message)))
+	r.Commits = append(r.Commits, FakeCommit{
+		Hash:    fmt.Sprintf("%x", hash),
+		Message: message,
+		Name:    r.UserName,
+		Email:   r.UserEmail,
+		Files:   snapshot,
+	})
+}
+
+func (r *FakeRepository) AmendAuthor(name, email string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.Commits) == 0 {
+		return fmt.Errorf("no commit to amend")
+	}
+	last := &r.Commits[len(r.Commits)-1]
+	last.Name, last.Email = name, email
+	return nil
+}
+
+func (r *FakeRepository) HeadCommit() (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.Commits) == 0 {
+		return "", fmt.Errorf("no commits yet")
+	}
+	return r.Commits[len(r.Commits)-1].Hash, nil
+}
+
+func (r *FakeRepository) CurrentBranch() (string, error) { return r.Branch, nil }
+
+func (r *FakeRepository) RecentCommitMessages(n int) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var messages []string
+	for i := len(r.Commits) - 1; i >= 0 && len(messages) < n; i-- {
+		messages = append(messages, r.Commits[i].Message)
+	}
+	return messages, nil
+}
+
+func (r *FakeRepository) Push() error {
+	if r.PushErr != nil {
+		return r.PushErr
+	}
+	r.Pushes++
+	return nil
+}
+
+func (r *FakeRepository) LocalIdentity() (string, string) { return r.UserName, r.UserEmail }
+
+func (r *FakeRepository) HistoryIdentity() (string, string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.Commits) == 0 {
+		return "", ""
+	}
+	last := r.Commits[len(r.Commits)-1]
+	return last.Name, last.Email
+}
+
+func (r *FakeRepository) SetLocalIdentity(name, email string) error {
+	if name != "" {
+		r.UserName = name
+	}
+	if email != "" {
+		r.UserEmail = email
+	}
+	return nil
+}
+
+func (r *FakeRepository) RemoteOwner() string {
+	owner, _, _ := strings.Cut(r.Remote, "/")
+	return owner
+}
+
+func (r *FakeRepository) RepoName() string {
+	_, name, _ := strings.Cut(r.Remote, "/")
+	return name
+}
+
+func (r *FakeRepository) LFSInstalled() bool { return r.LFSAvailable }
+
+// LFSTrack appends an LFS filter line for each new pattern to .gitattributes.
+func (r *FakeRepository) LFSTrack(patterns []string) error {
+	if !r.LFSAvailable {
+		return fmt.Errorf("git: 'lfs' is not a git command")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var attrs string
+	if f, ok := r.worktree[".gitattributes"]; ok {
+		attrs = string(f.Data)
+	}
+	for _, p := range patterns {
+		line := p + " filter=lfs diff=lfs merge=lfs -text"
+		if !strings.Contains("\n"+attrs, "\n"+line+"\n") {
+			attrs += line + "\n"
+		}
+	}
+	r.worktree[".gitattributes"] = &fstest.MapFile{Data: []byte(attrs), Mode: 0644}
+	return nil
+}
+
+func (r *FakeRepository) IsLFSTracked(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lfsTracked(name)
+}
+
+// lfsTracked matches name against the LFS lines of .gitattributes the way
+// git does: case-sensitively, and against the base name for patterns
+// without a slash.
+func (r *FakeRepository) lfsTracked(name string) bool {
+	f, ok := r.worktree[".gitattributes"]
+	if !ok {
+		return false
+	}
+	tracked := false
+	for _, line := range strings.Split(string(f.Data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		pattern, target := fields[0], path.Base(name)
+		if strings.Contains(pattern, "/") {
+			pattern, target = strings.TrimPrefix(pattern, "/"), name
+		}
+		if ok, _ := path.Match(pattern, target); !ok {
+			continue
+		}
+		for _, attr := range fields[1:] {
+			if strings.HasPrefix(attr, "filter=") || attr == "-filter" {
+				tracked = attr == "filter=lfs"
+			}
+		}
+	}
+	return tracked
+}
+
+// clean is the content git stores for a file: an LFS pointer when the file is
+// tracked with LFS and git-lfs is installed.
+func (r *FakeRepository) clean(name string, data []byte) string {
+	if !r.LFSAvailable || !r.lfsTracked(name) {
+		return string(data)
+	}
+	return fmt.Sprintf("version https://git-lfs.github.com/spec/v1\noid sha256:%x\nsize %d\n", sha256.Sum256(data), len(data))
+}
+
+func (r *FakeRepository) Restage(files []string) error { return r.Stage(files) }
+
+func (r *FakeRepository) StagedSize(name string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	content, ok := r.index[name]
+	if !ok {
+		return 0, fmt.Errorf("path '%s' is not in the index", name)
+	}
+	return int64(len(content)), nil
+}
@@ -0,0 +1,55 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFakeRepository(t *testing.T) {
+	r := NewFakeRepository("/repo", map[string]string{"a.go": "one\ntwo\n", "b.go": "b\n"})
+	r.WriteFile("a.go", []byte("one\n2\nthree\n"))
+	r.RemoveFile("b.go")
+	r.WriteFile("c.go", []byte("c\n"))
+
+	unstaged, _ := r.UnstagedFiles()
+	untracked, _ := r.UntrackedFiles()
+	if !reflect.DeepEqual(unstaged, []string{"a.go", "b.go"}) || !reflect.DeepEqual(untracked, []string{"c.go"}) {
+		t.Fatalf("unstaged %v, untracked %v", unstaged, untracked)
+	}
+
+	if err := r.Stage([]string{"a.go", "b.go", "c.go"}); err != nil {
+		t.Fatal(err)
+	}
+	statuses, _ := r.StagedNameStatus()
+	if want := map[string]string{"a.go": "M", "b.go": "D", "c.go": "A"}; !reflect.DeepEqual(statuses, want) {
+		t.Errorf("StagedNameStatus = %v; want %v", statuses, want)
+	}
+
+	diff, _ := r.StagedDiffUnified("a.go")
+	var added []string
+	for _, l := range ParseAddedLines(diff) {
+		added = append(added, l.Content)
+		if l.File != "a.go" {
+			t.Errorf("added line of %q", l.File)
+		}
+	}
+	if !reflect.DeepEqual(added, []string{"one", "2", "three"}) {
+		t.Errorf("added lines = %v", added)
+	}
+
+	if err := r.Unstage([]string{"c.go"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Commit("feat: c"); err != nil {
+		t.Fatal(err)
+	}
+	if staged, _ := r.StagedFiles(); len(staged) != 0 {
+		t.Errorf("staged after commit: %v", staged)
+	}
+	if err := r.Commit("empty"); err == nil {
+		t.Error("Commit succeeded with nothing staged")
+	}
+	if messages, _ := r.RecentCommitMessages(5); !reflect.DeepEqual(messages, []string{"feat: c", "Initial commit"}) {
+		t.Errorf("RecentCommitMessages = %v", messages)
+	}
+}
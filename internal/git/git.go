@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -21,11 +22,63 @@ func RunGitCommand(cwd string, args ...string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// RunGitCommandWithInput is RunGitCommand with input piped to git's stdin.
+func RunGitCommandWithInput(cwd string, input string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = cwd
+	cmd.Stdin = strings.NewReader(input)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %s, error: %w", strings.Join(args, " "), string(output), err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
 func StageAllChanges(cwd string) error {
 	_, err := RunGitCommand(cwd, "add", ".")
 	return err
 }
 
+// StageFiles stages the given paths, including deletions.
+func StageFiles(cwd string, files []string) error {
+	if len(files) == 0 {
+		return nil
+	}
+	args := append([]string{"add", "-A", "--"}, files...)
+	_, err := RunGitCommand(cwd, args...)
+	return err
+}
+
+// GetUnstagedFiles returns tracked files with unstaged changes.
+func GetUnstagedFiles(cwd string) ([]string, error) {
+	output, err := RunGitCommand(cwd, "diff", "--name-only", "-z")
+	if err != nil {
+		return nil, err
+	}
+	return splitNul(output), nil
+}
+
+// GetUntrackedFiles returns untracked files that are not ignored.
+func GetUntrackedFiles(cwd string) ([]string, error) {
+	output, err := RunGitCommand(cwd, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+	return splitNul(output), nil
+}
+
+func splitNul(output string) []string {
+	var result []string
+	for _, f := range strings.Split(output, "\x00") {
+		if f != "" {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
 func GetStagedFiles(cwd string) ([]string, error) {
 	output, err := RunGitCommand(cwd, "diff", "--staged", "--name-only")
 	if err != nil {
@@ -45,6 +98,98 @@ func GetStagedFiles(cwd string) ([]string, error) {
 	return result, nil
 }
 
+// GetStagedNameStatus maps each staged file to its status letter (A, M, D, T...).
+// Renames are reported as a deletion plus an addition.
+func GetStagedNameStatus(cwd string) (map[string]string, error) {
+	output, err := RunGitCommand(cwd, "diff", "--staged", "--name-status", "--no-renames")
+	if err != nil {
+		return nil, err
+	}
+	statuses := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "\t", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		statuses[parts[1]] = parts[0][:1]
+	}
+	return statuses, nil
+}
+
+// IndexEntry is a path as recorded in the index. A Mode of "0" marks a removal
+// when passed to UpdateIndexEntries.
+type IndexEntry struct {
+	Mode string
+	Hash string
+	Path string
+}
+
+const zeroHash = "0000000000000000000000000000000000000000"
+
+// RemovedEntry returns an entry that deletes path from the index.
+func RemovedEntry(path string) IndexEntry {
+	return IndexEntry{Mode: "0", Hash: zeroHash, Path: path}
+}
+
+// GetIndexEntries returns the stage-0 index entries for files. Files that are
+// not in the index (staged deletions) are returned as RemovedEntry values.
+func GetIndexEntries(cwd string, files []string) ([]IndexEntry, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+	args := append([]string{"ls-files", "-s", "-z", "--"}, files...)
+	output, err := RunGitCommand(cwd, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]IndexEntry)
+	for _, record := range strings.Split(output, "\x00") {
+		meta, path, ok := strings.Cut(record, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(meta)
+		if len(fields) != 3 || fields[2] != "0" {
+			continue
+		}
+		found[path] = IndexEntry{Mode: fields[0], Hash: fields[1], Path: path}
+	}
+
+	entries := make([]IndexEntry, 0, len(files))
+	for _, f := range files {
+		if e, ok := found[f]; ok {
+			entries = append(entries, e)
+		} else {
+			entries = append(entries, RemovedEntry(f))
+		}
+	}
+	return entries, nil
+}
+
+// ResetIndexToHead makes the index match HEAD without touching the working tree.
+func ResetIndexToHead(cwd string) error {
+	if _, err := RunGitCommand(cwd, "rev-parse", "--verify", "-q", "HEAD"); err != nil {
+		_, err = RunGitCommand(cwd, "read-tree", "--empty")
+		return err
+	}
+	_, err := RunGitCommand(cwd, "read-tree", "HEAD")
+	return err
+}
+
+// UpdateIndexEntries writes entries into the index as if they had been staged.
+func UpdateIndexEntries(cwd string, entries []IndexEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	for _, e := range entries {
+		fmt.Fprintf(&b, "%s %s\t%s\x00", e.Mode, e.Hash, e.Path)
+	}
+	_, err := RunGitCommandWithInput(cwd, b.String(), "update-index", "-z", "--index-info")
+	return err
+}
+
 func GetStagedDiff(cwd string, file string) (string, error) {
 	// Get the actual diff content for a file
 	return RunGitCommand(cwd, "diff", "--staged", "--unified=3", "--", file)
@@ -58,6 +203,157 @@ func GetStagedDiffUnified(cwd string, file string) (string, error) {
 	return RunGitCommand(cwd, "diff", "--staged", "--unified=0", "--", file)
 }
 
+// GetRangeDiff returns the zero-context diff of a revision range such as
+// "main..HEAD".
+func GetRangeDiff(cwd string, revRange string) (string, error) {
+	return RunGitCommand(cwd, "diff", "--unified=0", "--no-color", "--no-ext-diff", revRange)
+}
+
+// GetRangeFiles returns the files added or modified in a revision range.
+func GetRangeFiles(cwd string, revRange string) ([]string, error) {
+	output, err := RunGitCommand(cwd, "diff", "--name-only", "--diff-filter=AM", "-z", revRange)
+	if err != nil {
+		return nil, err
+	}
+	return splitNul(output), nil
+}
+
+// GetRangeNameStatus maps each file changed in a revision range to its
+// status letter (A, M, D, R...), keyed by the post-image path.
+func GetRangeNameStatus(cwd string, revRange string) (map[string]string, error) {
+	output, err := RunGitCommand(cwd, "diff", "--name-status", "-z", revRange)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make(map[string]string)
+	fields := splitNul(output)
+	for i := 0; i < len(fields); i++ {
+		status := fields[i]
+		// Renames and copies carry the old and the new path.
+		if strings.HasPrefix(status, "R") || strings.HasPrefix(status, "C") {
+			i += 2
+		} else {
+			i++
+		}
+		if i < len(fields) {
+			statuses[fields[i]] = status[:1]
+		}
+	}
+	return statuses, nil
+}
+
+// GetRangeFileDiff returns the diff of a single file in a revision range.
+func GetRangeFileDiff(cwd string, revRange string, file string) (string, error) {
+	return RunGitCommand(cwd, "diff", "--no-color", "--no-ext-diff", revRange, "--", file)
+}
+
+// GetRangeFileNumstat returns the numstat line of a single file in a revision range.
+func GetRangeFileNumstat(cwd string, revRange string, file string) (string, error) {
+	return RunGitCommand(cwd, "diff", "--numstat", revRange, "--", file)
+}
+
+// GetRangeCommitMessages returns the full messages of the non-merge commits
+// in a revision range, oldest first.
+func GetRangeCommitMessages(cwd string, revRange string) ([]string, error) {
+	output, err := RunGitCommand(cwd, "log", "--reverse", "--no-merges", "--format=%B%x00", revRange)
+	if err != nil {
+		return nil, err
+	}
+	var messages []string
+	for _, m := range strings.Split(output, "\x00") {
+		if trimmed := strings.TrimSpace(m); trimmed != "" {
+			messages = append(messages, trimmed)
+		}
+	}
+	return messages, nil
+}
+
+// LogEntry is a commit hash and its full message.
+type LogEntry struct {
+	Hash    string
+	Message string
+}
+
+// GetRangeLog returns the non-merge commits of a revision range, newest first.
+func GetRangeLog(cwd string, revRange string) ([]LogEntry, error) {
+	output, err := RunGitCommand(cwd, "log", "--no-merges", "--format=%H%x1f%B%x00", revRange)
+	if err != nil {
+		return nil, err
+	}
+	var entries []LogEntry
+	for _, record := range strings.Split(output, "\x00") {
+		hash, message, ok := strings.Cut(strings.TrimSpace(record), "\x1f")
+		if ok {
+			entries = append(entries, LogEntry{Hash: hash, Message: strings.TrimSpace(message)})
+		}
+	}
+	return entries, nil
+}
+
+// GetLatestTag returns the most recent tag reachable from rev.
+func GetLatestTag(cwd string, rev string) (string, error) {
+	return RunGitCommand(cwd, "describe", "--tags", "--abbrev=0", rev)
+}
+
+// GetExactTag returns the tag pointing at rev, if any.
+func GetExactTag(cwd string, rev string) (string, error) {
+	return RunGitCommand(cwd, "describe", "--tags", "--exact-match", rev)
+}
+
+// GetCommitDate returns the committer date of rev as YYYY-MM-DD.
+func GetCommitDate(cwd string, rev string) (string, error) {
+	return RunGitCommand(cwd, "log", "-1", "--format=%cs", rev)
+}
+
+// GetMergeBase returns the best common ancestor of a and b.
+func GetMergeBase(cwd string, a string, b string) (string, error) {
+	return RunGitCommand(cwd, "merge-base", a, b)
+}
+
+// GetDefaultBranch guesses the branch pull requests target: the remote's
+// HEAD when known, otherwise a local main or master.
+func GetDefaultBranch(cwd string) (string, error) {
+	if ref, err := RunGitCommand(cwd, "symbolic-ref", "--short", "refs/remotes/origin/HEAD"); err == nil && ref != "" {
+		return ref, nil
+	}
+	for _, name := range []string{"main", "master"} {
+		if _, err := RunGitCommand(cwd, "rev-parse", "--verify", "--quiet", name); err == nil {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("could not determine the default branch; pass --base")
+}
+
+// HasUpstream reports whether the current branch tracks a remote branch.
+func HasUpstream(cwd string) bool {
+	_, err := RunGitCommand(cwd, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{u}")
+	return err == nil
+}
+
+// PushNewBranch pushes the current branch to origin and sets it as upstream.
+func PushNewBranch(cwd string) error {
+	_, err := RunGitCommand(cwd, "push", "-u", "origin", "HEAD")
+	return err
+}
+
+// GetTrackedFiles returns every file tracked in the index.
+func GetTrackedFiles(cwd string) ([]string, error) {
+	output, err := RunGitCommand(cwd, "ls-files", "-z")
+	if err != nil {
+		return nil, err
+	}
+	return splitNul(output), nil
+}
+
+// GetBlobSize returns the size in bytes of path at rev.
+func GetBlobSize(cwd string, rev string, path string) (int64, error) {
+	output, err := RunGitCommand(cwd, "cat-file", "-s", rev+":"+path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(output, 10, 64)
+}
+
 func CommitWithMessage(cwd string, message string) error {
 	tmpFile, err := os.CreateTemp("", "commit-msg-")
 	if err != nil {
@@ -85,6 +381,11 @@ func GetRepoRoot(cwd string) (string, error) {
 	return RunGitCommand(cwd, "rev-parse", "--show-toplevel")
 }
 
+// GetHeadCommit returns the full SHA of HEAD.
+func GetHeadCommit(cwd string) (string, error) {
+	return RunGitCommand(cwd, "rev-parse", "HEAD")
+}
+
 func GetCurrentBranch(cwd string) (string, error) {
 	return RunGitCommand(cwd, "rev-parse", "--abbrev-ref", "HEAD")
 }
@@ -102,6 +403,22 @@ func GetHistoryIdentity(cwd string) (string, string) {
 	return name, email
 }
 
+// GetRecentCommitMessages returns the full messages of the last n non-merge
+// commits, newest first.
+func GetRecentCommitMessages(cwd string, n int) ([]string, error) {
+	output, err := RunGitCommand(cwd, "log", fmt.Sprintf("-n%d", n), "--no-merges", "--format=%B%x00")
+	if err != nil {
+		return nil, err
+	}
+	var messages []string
+	for _, m := range strings.Split(output, "\x00") {
+		if trimmed := strings.TrimSpace(m); trimmed != "" {
+			messages = append(messages, trimmed)
+		}
+	}
+	return messages, nil
+}
+
 func GetRemoteOwner(cwd string) string {
 	url, err := RunGitCommand(cwd, "remote", "get-url", "origin")
 	if err != nil {
@@ -145,6 +462,27 @@ func SyncFork(cwd string, target string) error {
 	return nil
 }
 
+// CreatePullRequest opens a pull request for the current branch with gh and
+// returns its URL.
+func CreatePullRequest(cwd string, base string, title string, body string, draft bool) (string, error) {
+	args := []string{"pr", "create", "--title", title, "--body-file", "-"}
+	if base != "" {
+		args = append(args, "--base", base)
+	}
+	if draft {
+		args = append(args, "--draft")
+	}
+	cmd := exec.Command("gh", args...)
+	cmd.Dir = cwd
+	cmd.Stdin = strings.NewReader(body)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("gh pr create failed: %s, error: %w", string(output), err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	return lines[len(lines)-1], nil
+}
+
 func SyncLocalConfig(cwd string, name string, email string) error {
 	if name != "" {
 		_, err := RunGitCommand(cwd, "config", "--local", "user.name", name)
@@ -161,6 +499,19 @@ func SyncLocalConfig(cwd string, name string, email string) error {
 	return nil
 }
 
+// SkippedDirs are dependency and build output directories never searched for
+// repositories.
+var SkippedDirs = map[string]bool{
+	"node_modules": true,
+	"target":       true,
+	".venv":        true,
+	"vendor":       true,
+	"dist":         true,
+	"out":          true,
+	"bin":          true,
+	"obj":          true,
+}
+
 func DiscoverRepositories(roots string) []string {
 	var repos []string
 	rootList := strings.Split(roots, ",")
@@ -183,17 +534,23 @@ func DiscoverRepositories(roots string) []string {
 			if err != nil {
 				return nil
 			}
+			name := d.Name()
 			if !d.IsDir() {
+				// Linked worktrees and submodules have a .git file.
+				if name == ".git" {
+					if absPath, err := filepath.Abs(filepath.Dir(path)); err == nil {
+						repos = append(repos, absPath)
+					}
+				}
 				return nil
 			}
-			name := d.Name()
 			if name == ".git" {
 				if absPath, err := filepath.Abs(filepath.Dir(path)); err == nil {
 					repos = append(repos, absPath)
 				}
 				return filepath.SkipDir
 			}
-			if name == "node_modules" || name == "target" || name == ".venv" || name == "vendor" || name == "dist" || name == "out" || name == "bin" || name == "obj" {
+			if SkippedDirs[name] || isBareRepository(path) {
 				return filepath.SkipDir
 			}
 
@@ -216,3 +573,13 @@ func DiscoverRepositories(roots string) []string {
 
 	return repos
 }
+
+// GetEditor returns the editor git uses for commit messages, honoring
+// GIT_EDITOR, core.editor, VISUAL and EDITOR.
+func GetEditor(cwd string) string {
+	editor, err := RunGitCommand(cwd, "var", "GIT_EDITOR")
+	if err != nil || editor == "" {
+		return "vi"
+	}
+	return editor
+}
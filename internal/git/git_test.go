@@ -0,0 +1,27 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestGetCommitDate(t *testing.T) {
+	dir := t.TempDir()
+	newRepo(t, dir)
+	cmd := exec.Command("git", "-c", "user.name=T", "-c", "user.email=t@example.com", "commit", "-q", "--allow-empty", "-m", "release")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE=2021-03-04T05:06:07Z")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %v: %s", err, out)
+	}
+	run(t, dir, "tag", "v1.0.0")
+	run(t, dir, "commit", "-q", "--allow-empty", "-m", "next")
+
+	if got, err := GetCommitDate(dir, "v1.0.0"); err != nil || got != "2021-03-04" {
+		t.Errorf("GetCommitDate(v1.0.0) = %q, %v; want 2021-03-04", got, err)
+	}
+	if _, err := GetCommitDate(dir, "v9.9.9"); err == nil {
+		t.Error("GetCommitDate(v9.9.9) succeeded; want an error")
+	}
+}
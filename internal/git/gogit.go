@@ -0,0 +1,488 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Git backends, selecting how staged changes are read.
+const (
+	BackendExec  = "exec"   // run the git binary for everything
+	BackendGoGit = "go-git" // read the index and HEAD in-process
+)
+
+// Backends returns the supported git backends.
+func Backends() []string {
+	return []string{BackendExec, BackendGoGit}
+}
+
+// GoGitRepository reads staged file lists and diffs straight from the index
+// and the HEAD tree with go-git, without starting a git process per file.
+// Everything that writes, such as staging, committing (with hooks and
+// signing) and pushing, still runs the git binary, as does any read go-git
+// fails on, like a split index.
+type GoGitRepository struct {
+	*ExecRepository
+	repo *gogit.Repository
+
+	mu sync.Mutex
+	// head maps the paths of the HEAD tree to their entries, for headHash.
+	head     map[string]entry
+	headHash plumbing.Hash
+	// staged maps the paths of the index to their entries, for the index
+	// whose trailing checksum is indexSum.
+	staged   map[string]entry
+	indexSum plumbing.Hash
+}
+
+type entry struct {
+	Hash plumbing.Hash
+	Mode filemode.FileMode
+}
+
+var _ Repository = (*GoGitRepository)(nil)
+
+// NewGoGitRepository opens the repository containing root with go-git.
+func NewGoGitRepository(root string) (*GoGitRepository, error) {
+	dir := root
+	if dir == "" {
+		dir = "."
+	}
+	repo, err := gogit.PlainOpenWithOptions(dir, &gogit.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+	if err != nil {
+		return nil, err
+	}
+	return &GoGitRepository{ExecRepository: NewExecRepository(root), repo: repo}, nil
+}
+
+// snapshot returns the HEAD and index entries, reading them again only when
+// HEAD moved or the index file's checksum changed. Like git, it reads the
+// index named by GIT_INDEX_FILE when set, such as the temporary index a
+// prepare-commit-msg hook sees under "git commit -a".
+func (r *GoGitRepository) snapshot() (map[string]entry, map[string]entry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	headHash := plumbing.ZeroHash
+	if ref, err := r.repo.Head(); err == nil {
+		headHash = ref.Hash()
+	} else if err != plumbing.ErrReferenceNotFound {
+		return nil, nil, err
+	}
+	if r.head == nil || headHash != r.headHash {
+		head, err := r.readHead(headHash)
+		if err != nil {
+			return nil, nil, err
+		}
+		r.head, r.headHash = head, headHash
+	}
+
+	sum, err := r.indexChecksum()
+	if err != nil {
+		return nil, nil, err
+	}
+	if r.staged == nil || sum.IsZero() || sum != r.indexSum {
+		idx, err := r.readIndex()
+		if err != nil {
+			return nil, nil, err
+		}
+		staged := make(map[string]entry, len(idx.Entries))
+		for _, e := range idx.Entries {
+			// Intent-to-add entries have no content staged yet, and git
+			// leaves them out of the staged diff too.
+			if e.IntentToAdd {
+				continue
+			}
+			// Merged entries decode as stage 0; index.Merged is 1 and would
+			// match the merge base of a conflict instead.
+			if e.Stage != 0 {
+				// An unmerged path; git reports it once, as "U".
+				staged[e.Name] = entry{Mode: filemode.Empty}
+				continue
+			}
+			staged[e.Name] = entry{Hash: e.Hash, Mode: e.Mode}
+		}
+		r.staged, r.indexSum = staged, sum
+	}
+	return r.head, r.staged, nil
+}
+
+func (r *GoGitRepository) readHead(hash plumbing.Hash) (map[string]entry, error) {
+	head := make(map[string]entry)
+	if hash.IsZero() {
+		// An unborn branch: everything in the index is added.
+		return head, nil
+	}
+	commit, err := r.repo.CommitObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, e, err := walker.Next()
+		if err == io.EOF {
+			return head, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if e.Mode != filemode.Dir {
+			head[name] = entry{Hash: e.Hash, Mode: e.Mode}
+		}
+	}
+}
+
+// indexChecksum identifies the version of the index file by the checksum git
+// writes at its end. Size and modification time are not enough: an index
+// rewritten to the same size within one timestamp tick would look unchanged.
+// It is zero, and the index is read every time, when there is no index file
+// or git skips the checksum (index.skipHash).
+func (r *GoGitRepository) indexChecksum() (plumbing.Hash, error) {
+	var sum plumbing.Hash
+	f, err := r.openIndex()
+	if os.IsNotExist(err) {
+		return sum, nil
+	}
+	if err != nil {
+		return sum, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(-int64(len(sum)), io.SeekEnd); err != nil {
+		return sum, err
+	}
+	if _, err := io.ReadFull(f, sum[:]); err != nil {
+		return sum, err
+	}
+	return sum, nil
+}
+
+// readIndex decodes the index file; a missing one is empty.
+func (r *GoGitRepository) readIndex() (*index.Index, error) {
+	idx := &index.Index{Version: 2}
+	f, err := r.openIndex()
+	if os.IsNotExist(err) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := index.NewDecoder(f).Decode(idx); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// openIndex opens $GIT_INDEX_FILE, or the index of the repository.
+func (r *GoGitRepository) openIndex() (io.ReadSeekCloser, error) {
+	if path := os.Getenv("GIT_INDEX_FILE"); path != "" {
+		return os.Open(path)
+	}
+	storage, ok := r.repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return nil, fmt.Errorf("unsupported go-git storage %T", r.repo.Storer)
+	}
+	return storage.Filesystem().Open("index")
+}
+
+// nameStatus compares the index with HEAD.
+func nameStatus(head, staged map[string]entry) map[string]string {
+	statuses := make(map[string]string)
+	for path, s := range staged {
+		h, ok := head[path]
+		switch {
+		case s.Mode == filemode.Empty:
+			statuses[path] = "U"
+		case !ok:
+			statuses[path] = "A"
+		case isLink(h.Mode) != isLink(s.Mode) || (h.Mode == filemode.Submodule) != (s.Mode == filemode.Submodule):
+			statuses[path] = "T"
+		case h != s:
+			statuses[path] = "M"
+		}
+	}
+	for path := range head {
+		if _, ok := staged[path]; !ok {
+			statuses[path] = "D"
+		}
+	}
+	return statuses
+}
+
+func isLink(m filemode.FileMode) bool { return m == filemode.Symlink }
+
+func (r *GoGitRepository) StagedFiles() ([]string, error) {
+	statuses, err := r.StagedNameStatus()
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(statuses))
+	for f := range statuses {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (r *GoGitRepository) StagedNameStatus() (map[string]string, error) {
+	head, staged, err := r.snapshot()
+	if err != nil {
+		return r.ExecRepository.StagedNameStatus()
+	}
+	return nameStatus(head, staged), nil
+}
+
+func (r *GoGitRepository) StagedDiff(file string) (string, error) {
+	p, err := r.filePatch(file)
+	if err != nil {
+		return r.ExecRepository.StagedDiff(file)
+	}
+	return p.unified(file, 3), nil
+}
+
+func (r *GoGitRepository) StagedDiffUnified(file string) (string, error) {
+	p, err := r.filePatch(file)
+	if err != nil {
+		return r.ExecRepository.StagedDiffUnified(file)
+	}
+	return p.unified(file, 0), nil
+}
+
+func (r *GoGitRepository) StagedDiffNumstat(file string) (string, error) {
+	p, err := r.filePatch(file)
+	if err != nil {
+		return r.ExecRepository.StagedDiffNumstat(file)
+	}
+	if p == nil {
+		return "", nil
+	}
+	if p.binary {
+		return fmt.Sprintf("-\t-\t%s", file), nil
+	}
+	added, deleted := 0, 0
+	for _, l := range p.lines {
+		switch l.op {
+		case '+':
+			added++
+		case '-':
+			deleted++
+		}
+	}
+	return fmt.Sprintf("%d\t%d\t%s", added, deleted, file), nil
+}
+
+// filePatch is the staged change of one file.
+type filePatch struct {
+	// from and to are nil for added and deleted files.
+	from, to *entry
+	binary   bool
+	lines    []diffLine
+}
+
+// diffLine is a line of a diff: op is ' ', '+' or '-'.
+type diffLine struct {
+	op    byte
+	text  string
+	noEOL bool
+}
+
+// filePatch diffs the HEAD and staged versions of file. It returns nil when
+// the file has no staged change.
+func (r *GoGitRepository) filePatch(file string) (*filePatch, error) {
+	head, staged, err := r.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	h, inHead := head[file]
+	s, inIndex := staged[file]
+	if inHead == inIndex && h == s {
+		return nil, nil
+	}
+	if inIndex && s.Mode == filemode.Empty {
+		return nil, fmt.Errorf("%s is unmerged", file)
+	}
+
+	p := &filePatch{}
+	var fromContent, toContent string
+	if inHead {
+		p.from = &h
+		if fromContent, p.binary, err = r.content(h); err != nil {
+			return nil, err
+		}
+	}
+	if inIndex {
+		p.to = &s
+		var binary bool
+		if toContent, binary, err = r.content(s); err != nil {
+			return nil, err
+		}
+		p.binary = p.binary || binary
+	}
+	if p.binary {
+		return p, nil
+	}
+
+	// diff.Do compares whole lines, so every chunk is a run of lines.
+	for _, d := range diff.Do(fromContent, toContent) {
+		op := byte(' ')
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			op = '+'
+		case diffmatchpatch.DiffDelete:
+			op = '-'
+		}
+		text := d.Text
+		for text != "" {
+			l, rest, found := strings.Cut(text, "\n")
+			p.lines = append(p.lines, diffLine{op: op, text: l, noEOL: !found})
+			text = rest
+		}
+	}
+	return p, nil
+}
+
+// binaryProbe is how much of a blob is searched for a NUL byte, like git.
+const binaryProbe = 8000
+
+// content returns the text of a blob, or reports it as binary. Submodules
+// read as git shows them in a diff.
+func (r *GoGitRepository) content(e entry) (string, bool, error) {
+	if e.Mode == filemode.Submodule {
+		return fmt.Sprintf("Subproject commit %s\n", e.Hash), false, nil
+	}
+	blob, err := r.repo.BlobObject(e.Hash)
+	if err != nil {
+		return "", false, err
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return "", false, err
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", false, err
+	}
+	if bytes.IndexByte(data[:min(len(data), binaryProbe)], 0) >= 0 {
+		return "", true, nil
+	}
+	return string(data), false, nil
+}
+
+// unified formats the patch like "git diff" with context lines around each
+// change, without the function name git appends to hunk headers.
+func (p *filePatch) unified(file string, context int) string {
+	if p == nil {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\n", file, file)
+	from, to := "a/"+file, "b/"+file
+	switch {
+	case p.from == nil:
+		fmt.Fprintf(&b, "new file mode %s\n", modeString(p.to.Mode))
+		fmt.Fprintf(&b, "index %s..%s\n", short(plumbing.ZeroHash), short(p.to.Hash))
+		from = "/dev/null"
+	case p.to == nil:
+		fmt.Fprintf(&b, "deleted file mode %s\n", modeString(p.from.Mode))
+		fmt.Fprintf(&b, "index %s..%s\n", short(p.from.Hash), short(plumbing.ZeroHash))
+		to = "/dev/null"
+	case p.from.Mode != p.to.Mode:
+		fmt.Fprintf(&b, "old mode %s\nnew mode %s\n", modeString(p.from.Mode), modeString(p.to.Mode))
+		if p.from.Hash == p.to.Hash {
+			return strings.TrimSuffix(b.String(), "\n")
+		}
+		fmt.Fprintf(&b, "index %s..%s\n", short(p.from.Hash), short(p.to.Hash))
+	default:
+		fmt.Fprintf(&b, "index %s..%s %s\n", short(p.from.Hash), short(p.to.Hash), modeString(p.to.Mode))
+	}
+	if p.binary {
+		fmt.Fprintf(&b, "Binary files %s and %s differ", from, to)
+		return b.String()
+	}
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", from, to)
+	p.writeHunks(&b, context)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// writeHunks writes the changed lines in hunks with up to context unchanged
+// lines around them. Changes closer than twice the context share a hunk.
+func (p *filePatch) writeHunks(b *strings.Builder, context int) {
+	lines := p.lines
+	// oldAt and newAt are the line numbers before lines[i].
+	oldAt, newAt := make([]int, len(lines)+1), make([]int, len(lines)+1)
+	for i, l := range lines {
+		oldAt[i+1], newAt[i+1] = oldAt[i], newAt[i]
+		if l.op != '+' {
+			oldAt[i+1]++
+		}
+		if l.op != '-' {
+			newAt[i+1]++
+		}
+	}
+
+	for i := 0; i < len(lines); {
+		if lines[i].op == ' ' {
+			i++
+			continue
+		}
+		start := max(0, i-context)
+		end := i // one past the last change of the hunk
+		for j := i; j < len(lines); j++ {
+			if lines[j].op != ' ' {
+				if j-end > 2*context {
+					break
+				}
+				end = j + 1
+			}
+		}
+		stop := min(len(lines), end+context)
+
+		fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkSide(oldAt[start], oldAt[stop]-oldAt[start]), hunkSide(newAt[start], newAt[stop]-newAt[start]))
+		for _, l := range lines[start:stop] {
+			b.WriteByte(l.op)
+			b.WriteString(l.text)
+			b.WriteByte('\n')
+			if l.noEOL {
+				b.WriteString("\\ No newline at end of file\n")
+			}
+		}
+		i = stop
+	}
+}
+
+// hunkSide formats one side of a hunk header: the first line and the line
+// count, which git leaves out when it is 1. An empty side names the line
+// before it.
+func hunkSide(before, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", before)
+	case 1:
+		return fmt.Sprintf("%d", before+1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+func modeString(m filemode.FileMode) string { return fmt.Sprintf("%06o", uint32(m)) }
+
+func short(h plumbing.Hash) string { return h.String()[:7] }
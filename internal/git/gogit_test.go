@@ -0,0 +1,252 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes name → content pairs below dir.
+func writeFiles(t testing.TB, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGoGitMatchesExec(t *testing.T) {
+	dir := t.TempDir()
+	newRepo(t, dir)
+	writeFiles(t, dir, map[string]string{
+		"edit.txt":    "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\n",
+		"gone.txt":    "to be deleted\n",
+		"bin.dat":     "\x00\x01\x02",
+		"no-eol.txt":  "a\nb",
+		"src/keep.go": "package src\n",
+	})
+	run(t, dir, "add", "-A")
+	run(t, dir, "commit", "-q", "-m", "files")
+
+	writeFiles(t, dir, map[string]string{
+		"edit.txt":   "one\ntwo\nTHREE\nfour\nfive\nsix\nseven\neight\nnine\n",
+		"new/add.go": "package add\n\nfunc Add() {}\n",
+		"bin.dat":    "\x00\x03",
+		"no-eol.txt": "a\nc",
+	})
+	if err := os.Remove(filepath.Join(dir, "gone.txt")); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, "add", "-A")
+
+	cli := NewExecRepository(dir)
+	gg, err := NewGoGitRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, _ := cli.StagedNameStatus()
+	got, err := gg.StagedNameStatus()
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("StagedNameStatus = %v, %v; want %v", got, err, want)
+	}
+	files, _ := gg.StagedFiles()
+	if wantFiles, _ := cli.StagedFiles(); !reflect.DeepEqual(files, wantFiles) {
+		t.Errorf("StagedFiles = %v; want %v", files, wantFiles)
+	}
+
+	for _, f := range files {
+		wantDiff, _ := cli.StagedDiffUnified(f)
+		gotDiff, err := gg.StagedDiffUnified(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := ParseAddedLines(gotDiff), ParseAddedLines(wantDiff); !sameAdded(got, want) {
+			t.Errorf("%s: added lines %+v; want %+v\n%s", f, got, want, gotDiff)
+		}
+		wantStat, _ := cli.StagedDiffNumstat(f)
+		if gotStat, _ := gg.StagedDiffNumstat(f); gotStat != wantStat {
+			t.Errorf("%s: numstat %q; want %q", f, gotStat, wantStat)
+		}
+		if diff, _ := gg.StagedDiff(f); f == "edit.txt" && !strings.Contains(diff, " two\n-three\n+THREE\n four") {
+			t.Errorf("%s: diff lacks context:\n%s", f, diff)
+		}
+	}
+
+	// Staging and committing through git is picked up.
+	writeFiles(t, dir, map[string]string{"src/keep.go": "package src // kept\n"})
+	run(t, dir, "add", "src/keep.go")
+	if got, _ := gg.StagedNameStatus(); got["src/keep.go"] != "M" {
+		t.Errorf("src/keep.go status = %q after staging", got["src/keep.go"])
+	}
+	run(t, dir, "commit", "-q", "-m", "changes")
+	if files, _ := gg.StagedFiles(); len(files) != 0 {
+		t.Errorf("StagedFiles after commit = %v", files)
+	}
+}
+
+func TestGoGitUnbornBranch(t *testing.T) {
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Skipf("git init failed: %v: %s", err, out)
+	}
+	writeFiles(t, dir, map[string]string{"a.txt": "a\n"})
+	run(t, dir, "add", "a.txt")
+
+	gg, err := NewGoGitRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := gg.StagedNameStatus(); !reflect.DeepEqual(got, map[string]string{"a.txt": "A"}) {
+		t.Errorf("StagedNameStatus = %v", got)
+	}
+}
+
+func TestGoGitRereadsSameSizeIndex(t *testing.T) {
+	dir := t.TempDir()
+	newRepo(t, dir)
+	writeFiles(t, dir, map[string]string{"a.txt": "aaa\n", "b.txt": "bbb\n"})
+	run(t, dir, "add", "a.txt")
+
+	gg, err := NewGoGitRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := gg.StagedFiles(); !reflect.DeepEqual(got, []string{"a.txt"}) {
+		t.Fatalf("StagedFiles = %v", got)
+	}
+
+	// Swap which file is staged; the index keeps its size, and its
+	// modification time is put back as if both writes fell in one tick.
+	index := filepath.Join(dir, ".git", "index")
+	info, err := os.Stat(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, "reset", "-q", "HEAD", "--", "a.txt")
+	run(t, dir, "add", "b.txt")
+	if after, err := os.Stat(index); err != nil || after.Size() != info.Size() {
+		t.Fatalf("index size changed: %v", err)
+	}
+	if err := os.Chtimes(index, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := gg.StagedFiles(); !reflect.DeepEqual(got, []string{"b.txt"}) {
+		t.Errorf("StagedFiles after restaging = %v; want [b.txt]", got)
+	}
+}
+
+func TestGoGitHonorsIndexFile(t *testing.T) {
+	dir := t.TempDir()
+	newRepo(t, dir)
+	writeFiles(t, dir, map[string]string{"a.txt": "a\n", "b.txt": "b\n"})
+	run(t, dir, "add", "a.txt")
+
+	gg, err := NewGoGitRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := gg.StagedFiles(); !reflect.DeepEqual(got, []string{"a.txt"}) {
+		t.Fatalf("StagedFiles = %v", got)
+	}
+
+	// A temporary index, as "git commit b.txt" builds for its hooks.
+	tmp := filepath.Join(t.TempDir(), "next-index")
+	for _, args := range [][]string{{"read-tree", "HEAD"}, {"add", "b.txt"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_INDEX_FILE="+tmp)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	t.Setenv("GIT_INDEX_FILE", tmp)
+
+	want, _ := NewExecRepository(dir).StagedFiles()
+	if got, _ := gg.StagedFiles(); !reflect.DeepEqual(got, want) || !reflect.DeepEqual(got, []string{"b.txt"}) {
+		t.Errorf("StagedFiles with GIT_INDEX_FILE = %v; want %v", got, want)
+	}
+	if diff, _ := gg.StagedDiffUnified("b.txt"); !strings.Contains(diff, "+b") {
+		t.Errorf("StagedDiffUnified(b.txt) = %q", diff)
+	}
+}
+
+func sameAdded(a, b []AddedLine) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].File != b[i].File || a[i].Line != b[i].Line || a[i].Content != b[i].Content {
+			return false
+		}
+	}
+	return true
+}
+
+// BenchmarkStagedDiffs reads what a commit needs of a 1,000-file change: the
+// staged files, their statuses, and each file's diff for the summary and for
+// the secret scan.
+func BenchmarkStagedDiffs(b *testing.B) {
+	dir := b.TempDir()
+	newRepo(b, dir)
+	const n = 1000
+	files := make(map[string]string, n)
+	for i := 0; i < n; i++ {
+		var lines []string
+		for l := 0; l < 40; l++ {
+			lines = append(lines, fmt.Sprintf("line %d of file %d", l, i))
+		}
+		files[fmt.Sprintf("pkg%02d/file%04d.txt", i%20, i)] = strings.Join(lines, "\n") + "\n"
+	}
+	writeFiles(b, dir, files)
+	run(b, dir, "add", "-A")
+	run(b, dir, "commit", "-q", "-m", "files")
+	for name, content := range files {
+		files[name] = strings.Replace(content, "line 20 ", "changed line 20 ", 1) + "appended\n"
+	}
+	writeFiles(b, dir, files)
+	run(b, dir, "add", "-A")
+
+	backends := []struct {
+		name string
+		open func() (Repository, error)
+	}{
+		{BackendExec, func() (Repository, error) { return NewExecRepository(dir), nil }},
+		{BackendGoGit, func() (Repository, error) { return NewGoGitRepository(dir) }},
+	}
+	for _, backend := range backends {
+		b.Run(backend.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				repo, err := backend.open()
+				if err != nil {
+					b.Fatal(err)
+				}
+				staged, err := repo.StagedFiles()
+				if err != nil || len(staged) != n {
+					b.Fatalf("StagedFiles = %d files, %v", len(staged), err)
+				}
+				if _, err := repo.StagedNameStatus(); err != nil {
+					b.Fatal(err)
+				}
+				for _, f := range staged {
+					if _, err := repo.StagedDiff(f); err != nil {
+						b.Fatal(err)
+					}
+					if _, err := repo.StagedDiffUnified(f); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package git
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Hunk is a parsed "@@ -a,b +c,d @@ section" header.
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	// Header is the full header line, including any function context git
+	// appends after the closing @@.
+	Header string
+}
+
+// AddedLine is a line added by a diff, numbered in the post-image file.
+type AddedLine struct {
+	File    string
+	Line    int
+	Content string
+	Hunk    Hunk
+}
+
+// ParseHunkHeader parses a unified diff hunk header.
+func ParseHunkHeader(header string) (Hunk, bool) {
+	if !strings.HasPrefix(header, "@@ ") {
+		return Hunk{}, false
+	}
+	end := strings.Index(header[3:], " @@")
+	if end < 0 {
+		return Hunk{}, false
+	}
+	fields := strings.Fields(header[3 : 3+end])
+	if len(fields) != 2 || !strings.HasPrefix(fields[0], "-") || !strings.HasPrefix(fields[1], "+") {
+		return Hunk{}, false
+	}
+
+	h := Hunk{Header: header}
+	var ok1, ok2 bool
+	h.OldStart, h.OldLines, ok1 = parseRange(fields[0][1:])
+	h.NewStart, h.NewLines, ok2 = parseRange(fields[1][1:])
+	return h, ok1 && ok2
+}
+
+// parseRange parses "start,count" or "start", where count defaults to 1.
+func parseRange(s string) (int, int, bool) {
+	startStr, countStr, hasCount := strings.Cut(s, ",")
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return 0, 0, false
+	}
+	count := 1
+	if hasCount {
+		if count, err = strconv.Atoi(countStr); err != nil {
+			return 0, 0, false
+		}
+	}
+	return start, count, true
+}
+
+// ParseAddedLines returns every added line of a unified diff with its real
+// line number in the new version of the file. Diffs covering several files
+// are supported; File is taken from the "+++ b/path" header.
+func ParseAddedLines(diff string) []AddedLine {
+	var added []AddedLine
+	var file string
+	var hunk Hunk
+	inHunk := false
+	newLine := 0
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			inHunk = false
+		case !inHunk && strings.HasPrefix(line, "+++ "):
+			file = diffPath(line[4:])
+		case strings.HasPrefix(line, "@@ "):
+			h, ok := ParseHunkHeader(line)
+			if !ok {
+				inHunk = false
+				continue
+			}
+			hunk, inHunk, newLine = h, true, h.NewStart
+		case !inHunk:
+			continue
+		case strings.HasPrefix(line, "+"):
+			added = append(added, AddedLine{File: file, Line: newLine, Content: line[1:], Hunk: hunk})
+			newLine++
+		case strings.HasPrefix(line, " "), line == "":
+			// Context line; an empty line is a context line whose leading
+			// space was trimmed.
+			newLine++
+		}
+		// "-" lines and "\ No newline at end of file" do not advance the
+		// post-image line number.
+	}
+	return added
+}
+
+// diffPath strips the b/ prefix and any quoting from a +++ header path.
+func diffPath(p string) string {
+	p = strings.TrimSuffix(p, "\t")
+	if p == "/dev/null" {
+		return ""
+	}
+	if strings.HasPrefix(p, `"`) {
+		if unquoted, err := strconv.Unquote(p); err == nil {
+			p = unquoted
+		}
+	}
+	return strings.TrimPrefix(p, "b/")
+}
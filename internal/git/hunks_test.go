@@ -0,0 +1,75 @@
+package git
+
+import "testing"
+
+func TestParseHunkHeader(t *testing.T) {
+	tests := []struct {
+		header string
+		want   Hunk
+		ok     bool
+	}{
+		{"@@ -10,2 +12,3 @@ func main() {", Hunk{OldStart: 10, OldLines: 2, NewStart: 12, NewLines: 3}, true},
+		{"@@ -1 +1 @@", Hunk{OldStart: 1, OldLines: 1, NewStart: 1, NewLines: 1}, true},
+		{"@@ -0,0 +1,5 @@", Hunk{OldStart: 0, OldLines: 0, NewStart: 1, NewLines: 5}, true},
+		{"@@ bogus @@", Hunk{}, false},
+		{"+++ b/file", Hunk{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseHunkHeader(tt.header)
+		if ok != tt.ok {
+			t.Errorf("ParseHunkHeader(%q) ok = %v; want %v", tt.header, ok, tt.ok)
+			continue
+		}
+		got.Header = ""
+		if ok && got != tt.want {
+			t.Errorf("ParseHunkHeader(%q) = %+v; want %+v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestParseAddedLines(t *testing.T) {
+	diff := `diff --git a/config.go b/config.go
+index 83db48f..bf269f4 100644
+--- a/config.go
++++ b/config.go
+@@ -3,0 +4,2 @@ import (
++	"os"
++	"strings"
+@@ -20,3 +22,3 @@ func Load() {
+ 	a := 1
+-	b := 2
++	b := 3
+ 	c := 4
+diff --git a/new.txt b/new.txt
+new file mode 100644
+--- /dev/null
++++ b/new.txt
+@@ -0,0 +1,2 @@
++first
++++second
+\ No newline at end of file`
+
+	got := ParseAddedLines(diff)
+	want := []struct {
+		file    string
+		line    int
+		content string
+	}{
+		{"config.go", 4, "\t\"os\""},
+		{"config.go", 5, "\t\"strings\""},
+		{"config.go", 23, "\tb := 3"},
+		{"new.txt", 1, "first"},
+		{"new.txt", 2, "++second"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ParseAddedLines() = %d lines; want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].File != w.file || got[i].Line != w.line || got[i].Content != w.content {
+			t.Errorf("line %d = %s:%d %q; want %s:%d %q", i, got[i].File, got[i].Line, got[i].Content, w.file, w.line, w.content)
+		}
+	}
+	if got[2].Hunk.Header != "@@ -20,3 +22,3 @@ func Load() {" {
+		t.Errorf("hunk header = %q", got[2].Hunk.Header)
+	}
+}
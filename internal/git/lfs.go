@@ -0,0 +1,39 @@
+package git
+
+import "strings"
+
+// IsLFSInstalled reports whether the git-lfs extension is available.
+func IsLFSInstalled(cwd string) bool {
+	_, err := RunGitCommand(cwd, "lfs", "version")
+	return err == nil
+}
+
+// IsLFSTracked reports whether .gitattributes routes path through the LFS
+// filter, in which case only a small pointer is committed.
+func IsLFSTracked(cwd string, path string) bool {
+	output, err := RunGitCommand(cwd, "check-attr", "filter", "--", path)
+	return err == nil && strings.HasSuffix(output, ": filter: lfs")
+}
+
+// LFSTrack adds patterns to .gitattributes with "git lfs track".
+func LFSTrack(cwd string, patterns []string) error {
+	if len(patterns) == 0 {
+		return nil
+	}
+	args := append([]string{"lfs", "track"}, patterns...)
+	_, err := RunGitCommand(cwd, args...)
+	return err
+}
+
+// RestageFiles drops files from the index and adds them again so that newly
+// configured filters (such as LFS) are applied to the staged content.
+func RestageFiles(cwd string, files []string) error {
+	if len(files) == 0 {
+		return nil
+	}
+	args := append([]string{"rm", "--cached", "-q", "--"}, files...)
+	if _, err := RunGitCommand(cwd, args...); err != nil {
+		return err
+	}
+	return StageFiles(cwd, files)
+}
@@ -0,0 +1,31 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsLFSTracked(t *testing.T) {
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Skipf("git init failed: %v: %s", err, out)
+	}
+	attrs := "*.psd filter=lfs diff=lfs merge=lfs -text\n"
+	if err := os.WriteFile(filepath.Join(dir, ".gitattributes"), []byte(attrs), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]bool{
+		"art/cover.psd": true,
+		"cover.psd":     true,
+		"cover.png":     false,
+		"psd":           false,
+	}
+	for path, want := range tests {
+		if got := IsLFSTracked(dir, path); got != want {
+			t.Errorf("IsLFSTracked(%q) = %v; want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package git
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Repository is a working tree the commit workflow stages, inspects, commits
+// and pushes. ExecRepository runs the git binary; FakeRepository keeps
+// everything in memory for tests.
+type Repository interface {
+	// Root is the top level directory of the working tree.
+	Root() string
+	// Worktree reads working tree files by slash-separated path relative to
+	// Root.
+	Worktree() fs.FS
+	// WriteFile writes a working tree file.
+	WriteFile(name string, data []byte) error
+
+	StagedFiles() ([]string, error)
+	// StagedNameStatus maps staged files to their status letter (A, M, D...).
+	StagedNameStatus() (map[string]string, error)
+	UnstagedFiles() ([]string, error)
+	UntrackedFiles() ([]string, error)
+	// Stage stages the given paths, including deletions.
+	Stage(files []string) error
+	// Unstage resets the given paths in the index to HEAD.
+	Unstage(files []string) error
+
+	// StagedDiff is the staged diff of file with extra context, for
+	// summaries.
+	StagedDiff(file string) (string, error)
+	StagedDiffNumstat(file string) (string, error)
+	// StagedDiffUnified is the staged diff of file without extra context,
+	// for line-accurate scanning.
+	StagedDiffUnified(file string) (string, error)
+
+	Commit(message string) error
+	// AmendAuthor rewrites the author of the last commit.
+	AmendAuthor(name, email string) error
+	HeadCommit() (string, error)
+	CurrentBranch() (string, error)
+	// RecentCommitMessages returns the messages of the last n non-merge
+	// commits, newest first.
+	RecentCommitMessages(n int) ([]string, error)
+	Push() error
+
+	// LocalIdentity is the configured user.name and user.email.
+	LocalIdentity() (name, email string)
+	// HistoryIdentity is the author of the last commit.
+	HistoryIdentity() (name, email string)
+	// SetLocalIdentity writes user.name and user.email to the repository
+	// config; empty values are left alone.
+	SetLocalIdentity(name, email string) error
+	// RemoteOwner and RepoName are parsed from the origin URL.
+	RemoteOwner() string
+	RepoName() string
+
+	// LFSInstalled reports whether the git-lfs extension is available.
+	LFSInstalled() bool
+	// LFSTrack adds patterns to .gitattributes with "git lfs track".
+	LFSTrack(patterns []string) error
+	// IsLFSTracked reports whether .gitattributes routes path through the
+	// LFS filter.
+	IsLFSTracked(path string) bool
+	// Restage drops files from the index and stages them again, so that
+	// newly configured filters apply to the staged content.
+	Restage(files []string) error
+	// StagedSize is the size in bytes of the staged blob of path.
+	StagedSize(path string) (int64, error)
+}
+
+// ExecRepository is the Repository of a working tree on disk, driven through
+// the git binary.
+type ExecRepository struct {
+	root string
+}
+
+var _ Repository = (*ExecRepository)(nil)
+
+// NewExecRepository returns the repository whose working tree is root. An
+// empty root is the current directory.
+func NewExecRepository(root string) *ExecRepository {
+	return &ExecRepository{root: root}
+}
+
+func (r *ExecRepository) Root() string { return r.root }
+
+func (r *ExecRepository) Worktree() fs.FS {
+	if r.root == "" {
+		return os.DirFS(".")
+	}
+	return os.DirFS(r.root)
+}
+
+func (r *ExecRepository) WriteFile(name string, data []byte) error {
+	return os.WriteFile(filepath.Join(r.root, filepath.FromSlash(name)), data, 0644)
+}
+
+func (r *ExecRepository) StagedFiles() ([]string, error) { return GetStagedFiles(r.root) }
+
+func (r *ExecRepository) StagedNameStatus() (map[string]string, error) {
+	return GetStagedNameStatus(r.root)
+}
+
+func (r *ExecRepository) UnstagedFiles() ([]string, error)  { return GetUnstagedFiles(r.root) }
+func (r *ExecRepository) UntrackedFiles() ([]string, error) { return GetUntrackedFiles(r.root) }
+func (r *ExecRepository) Stage(files []string) error        { return StageFiles(r.root, files) }
+
+func (r *ExecRepository) Unstage(files []string) error {
+	if len(files) == 0 {
+		return nil
+	}
+	_, err := RunGitCommand(r.root, append([]string{"reset", "-q", "HEAD", "--"}, files...)...)
+	return err
+}
+
+func (r *ExecRepository) StagedDiff(file string) (string, error) { return GetStagedDiff(r.root, file) }
+
+func (r *ExecRepository) StagedDiffNumstat(file string) (string, error) {
+	return GetStagedDiffNumstat(r.root, file)
+}
+
+func (r *ExecRepository) StagedDiffUnified(file string) (string, error) {
+	return GetStagedDiffUnified(r.root, file)
+}
+
+func (r *ExecRepository) Commit(message string) error { return CommitWithMessage(r.root, message) }
+
+func (r *ExecRepository) AmendAuthor(name, email string) error {
+	_, err := RunGitCommand(r.root, "commit", "--amend", "--no-edit", "--author", fmt.Sprintf("%s <%s>", name, email))
+	return err
+}
+
+func (r *ExecRepository) HeadCommit() (string, error)    { return GetHeadCommit(r.root) }
+func (r *ExecRepository) CurrentBranch() (string, error) { return GetCurrentBranch(r.root) }
+
+func (r *ExecRepository) RecentCommitMessages(n int) ([]string, error) {
+	return GetRecentCommitMessages(r.root, n)
+}
+
+func (r *ExecRepository) Push() error { return PushChanges(r.root) }
+
+func (r *ExecRepository) LocalIdentity() (string, string)   { return GetLocalIdentity(r.root) }
+func (r *ExecRepository) HistoryIdentity() (string, string) { return GetHistoryIdentity(r.root) }
+
+func (r *ExecRepository) SetLocalIdentity(name, email string) error {
+	return SyncLocalConfig(r.root, name, email)
+}
+
+func (r *ExecRepository) RemoteOwner() string { return GetRemoteOwner(r.root) }
+func (r *ExecRepository) RepoName() string    { return GetRepoName(r.root) }
+
+func (r *ExecRepository) LFSInstalled() bool                    { return IsLFSInstalled(r.root) }
+func (r *ExecRepository) LFSTrack(patterns []string) error      { return LFSTrack(r.root, patterns) }
+func (r *ExecRepository) IsLFSTracked(path string) bool         { return IsLFSTracked(r.root, path) }
+func (r *ExecRepository) Restage(files []string) error          { return RestageFiles(r.root, files) }
+func (r *ExecRepository) StagedSize(path string) (int64, error) { return GetBlobSize(r.root, "", path) }
@@ -0,0 +1,111 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Kinds of working trees.
+const (
+	KindRepository = "repository"
+	KindWorktree   = "worktree"
+	KindSubmodule  = "submodule"
+)
+
+// GetWorkTreeKind tells a regular repository from a linked worktree and a
+// submodule. Both of the latter have a .git file pointing at a git directory
+// kept elsewhere.
+func GetWorkTreeKind(cwd string) string {
+	if GetSuperproject(cwd) != "" {
+		return KindSubmodule
+	}
+	gitDir, err1 := RunGitCommand(cwd, "rev-parse", "--absolute-git-dir")
+	commonDir, err2 := RunGitCommand(cwd, "rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err1 == nil && err2 == nil && filepath.Clean(gitDir) != filepath.Clean(commonDir) {
+		return KindWorktree
+	}
+	return KindRepository
+}
+
+// GetSuperproject returns the working tree of the repository cwd is a
+// submodule of, or an empty string.
+func GetSuperproject(cwd string) string {
+	output, err := RunGitCommand(cwd, "rev-parse", "--show-superproject-working-tree")
+	if err != nil {
+		return ""
+	}
+	return output
+}
+
+// IsDetachedHead reports whether no branch is checked out, as is usual in
+// submodules.
+func IsDetachedHead(cwd string) bool {
+	_, err := RunGitCommand(cwd, "symbolic-ref", "-q", "HEAD")
+	return err != nil
+}
+
+// isBareRepository reports whether dir is the git directory of a bare
+// repository, which has no working tree to commit from.
+func isBareRepository(dir string) bool {
+	for _, name := range []string{"HEAD", "objects", "refs"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			return false
+		}
+	}
+	output, err := RunGitCommand(dir, "rev-parse", "--is-bare-repository")
+	return err == nil && output == "true"
+}
+
+// Submodule is a submodule as recorded by its superproject.
+type Submodule struct {
+	// Path is relative to the superproject.
+	Path string
+	// Commit is the commit checked out in the submodule.
+	Commit string
+	// Moved reports whether Commit differs from the commit the
+	// superproject's index records.
+	Moved bool
+	// Initialized is false for submodules that were never checked out.
+	Initialized bool
+}
+
+// submoduleStatusRegex parses "git submodule status" lines: a state flag,
+// the checked out commit, the path and an optional description.
+var submoduleStatusRegex = regexp.MustCompile(`^([ +\-U]?)([0-9a-f]+) (.+?)(?: \(.*\))?$`)
+
+// GetSubmodules returns the direct submodules of the repository at cwd.
+func GetSubmodules(cwd string) ([]Submodule, error) {
+	if _, err := os.Stat(filepath.Join(cwd, ".gitmodules")); err != nil {
+		return nil, nil
+	}
+	output, err := RunGitCommand(cwd, "submodule", "status")
+	if err != nil {
+		return nil, err
+	}
+	var subs []Submodule
+	for _, line := range strings.Split(output, "\n") {
+		m := submoduleStatusRegex.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		subs = append(subs, Submodule{
+			Path:        m[3],
+			Commit:      m[2],
+			Moved:       m[1] == "+",
+			Initialized: m[1] != "-",
+		})
+	}
+	return subs, nil
+}
+
+// GetGitlink returns the commit rev records for the submodule at path, or an
+// empty string when rev does not have it.
+func GetGitlink(cwd string, rev string, path string) string {
+	output, err := RunGitCommand(cwd, "rev-parse", "--verify", "-q", rev+":"+path)
+	if err != nil {
+		return ""
+	}
+	return output
+}
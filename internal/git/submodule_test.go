@@ -0,0 +1,108 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// run runs git in dir and fails the test on error.
+func run(t testing.TB, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "protocol.file.allow=always", "-c", "user.name=T", "-c", "user.email=t@example.com"}, args...)...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+}
+
+// newRepo creates a repository with one commit.
+func newRepo(t testing.TB, dir string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Skipf("git init failed: %v: %s", err, out)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, "add", "README")
+	run(t, dir, "commit", "-q", "-m", "init")
+}
+
+func TestDiscoverWorktreesAndSubmodules(t *testing.T) {
+	root := t.TempDir()
+	lib := filepath.Join(root, "lib")
+	app := filepath.Join(root, "app")
+	newRepo(t, lib)
+	newRepo(t, app)
+	run(t, app, "submodule", "add", "-q", lib, "vendor-lib")
+	run(t, app, "commit", "-q", "-m", "add lib")
+	run(t, app, "worktree", "add", "-q", filepath.Join(root, "app-feature"))
+	if out, err := exec.Command("git", "init", "-q", "--bare", filepath.Join(root, "mirror.git")).CombinedOutput(); err != nil {
+		t.Fatalf("git init --bare: %v: %s", err, out)
+	}
+
+	got := DiscoverRepositories(root)
+	want := []string{app, filepath.Join(root, "app-feature"), filepath.Join(app, "vendor-lib"), lib}
+	for i := range want {
+		want[i], _ = filepath.EvalSymlinks(want[i])
+	}
+	for i := range got {
+		got[i], _ = filepath.EvalSymlinks(got[i])
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiscoverRepositories = %v; want %v", got, want)
+	}
+
+	kinds := map[string]string{
+		app:                                KindRepository,
+		filepath.Join(root, "app-feature"): KindWorktree,
+		filepath.Join(app, "vendor-lib"):   KindSubmodule,
+	}
+	for dir, want := range kinds {
+		if got := GetWorkTreeKind(dir); got != want {
+			t.Errorf("GetWorkTreeKind(%s) = %s; want %s", dir, got, want)
+		}
+	}
+}
+
+func TestGetSubmodules(t *testing.T) {
+	root := t.TempDir()
+	lib := filepath.Join(root, "lib")
+	app := filepath.Join(root, "app")
+	newRepo(t, lib)
+	newRepo(t, app)
+	run(t, app, "submodule", "add", "-q", lib, "libs/my lib")
+	run(t, app, "commit", "-q", "-m", "add lib")
+	recorded := GetGitlink(app, "HEAD", "libs/my lib")
+
+	sub := filepath.Join(app, "libs", "my lib")
+	if err := os.WriteFile(filepath.Join(sub, "README"), []byte("y\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run(t, sub, "commit", "-q", "-am", "change")
+
+	subs, err := GetSubmodules(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 1 {
+		t.Fatalf("GetSubmodules = %+v; want one submodule", subs)
+	}
+	s := subs[0]
+	if s.Path != "libs/my lib" || !s.Moved || !s.Initialized || s.Commit == recorded || recorded == "" {
+		t.Errorf("GetSubmodules = %+v; recorded %s", s, recorded)
+	}
+	if IsDetachedHead(sub) {
+		t.Error("IsDetachedHead reported a branch checkout as detached")
+	}
+	run(t, sub, "checkout", "-q", "--detach")
+	if !IsDetachedHead(sub) {
+		t.Error("IsDetachedHead missed a detached HEAD")
+	}
+}
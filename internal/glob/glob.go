@@ -0,0 +1,70 @@
+// Package glob matches slash-separated paths against gitignore-like patterns.
+package glob
+
+import (
+	"path"
+	"strings"
+)
+
+// Match reports whether file matches pattern. Both use forward slashes and are
+// relative to the repository root.
+//
+//   - "*", "?" and "[...]" behave as in path.Match and never cross a "/".
+//   - "**" as a whole segment matches zero or more directories.
+//   - A pattern without a "/" matches the file name at any depth.
+//   - A trailing "/" matches everything below that directory.
+func Match(pattern, file string) bool {
+	pattern = strings.TrimPrefix(pattern, "./")
+	file = strings.TrimPrefix(file, "./")
+	if pattern == "" {
+		return false
+	}
+
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	if !strings.Contains(strings.TrimSuffix(pattern, "/**"), "/") {
+		pattern = "**/" + pattern
+	}
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(file, "/"))
+}
+
+// MatchAny reports whether file matches any of patterns.
+func MatchAny(patterns []string, file string) bool {
+	for _, p := range patterns {
+		if Match(p, file) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Collapse consecutive "**" and try every possible split.
+			for len(pattern) > 1 && pattern[1] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 1 {
+				return true
+			}
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], parts[0]); err != nil || !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
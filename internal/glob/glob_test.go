@@ -0,0 +1,34 @@
+package glob
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		file    string
+		want    bool
+	}{
+		{"config/prod/**", "config/prod/db.yaml", true},
+		{"config/prod/**", "config/prod/nested/keys.json", true},
+		{"config/prod/**", "config/production.yaml", false},
+		{"config/prod/**", "app/config/prod/db.yaml", false},
+		{"config/prod/", "config/prod/db.yaml", true},
+		{"**/secrets/*.yaml", "deploy/k8s/secrets/app.yaml", true},
+		{"**/secrets/*.yaml", "secrets/app.yaml", true},
+		{"**/secrets/*.yaml", "secrets/nested/app.yaml", false},
+		{"*.pem", "certs/server.pem", true},
+		{"*.pem", "server.pem", true},
+		{"*.pem", "server.pem.txt", false},
+		{"src/**/*.sql", "src/db/migrations/001.sql", true},
+		{"src/**/*.sql", "src/001.sql", true},
+		{"src/*.sql", "src/db/001.sql", false},
+		{"/internal/*.go", "internal/a.go", true},
+		{"data/[ab].csv", "data/a.csv", true},
+		{"", "anything", false},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.file); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v; want %v", tt.pattern, tt.file, got, tt.want)
+		}
+	}
+}
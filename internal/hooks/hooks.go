@@ -0,0 +1,156 @@
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nathfavour/autocommiter.go/internal/git"
+)
+
+// Hook types that autocommiter can install.
+const (
+	PrepareCommitMsg = "prepare-commit-msg"
+	CommitMsg        = "commit-msg"
+)
+
+// Marker identifies hook scripts written by autocommiter.
+const Marker = "# autocommiter-hook"
+
+// ChainSuffix is appended to a pre-existing hook that autocommiter moved aside.
+const ChainSuffix = ".pre-autocommiter"
+
+// Dir returns the directory git runs hooks from, honoring core.hooksPath.
+func Dir(repoRoot string) (string, error) {
+	if p, err := git.RunGitCommand(repoRoot, "config", "--get", "core.hooksPath"); err == nil && p != "" {
+		if strings.HasPrefix(p, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				p = filepath.Join(home, p[2:])
+			}
+		}
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(repoRoot, p)
+		}
+		return p, nil
+	}
+
+	p, err := git.RunGitCommand(repoRoot, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(repoRoot, p)
+	}
+	return p, nil
+}
+
+// IsInstalled reports whether the hook at path was written by autocommiter.
+func IsInstalled(path string) bool {
+	data, err := os.ReadFile(path)
+	return err == nil && strings.Contains(string(data), Marker)
+}
+
+// Install writes the hookType hook into the repository's hooks directory.
+// A foreign hook already in place is renamed with ChainSuffix and run first.
+// It returns the path of the installed hook.
+func Install(repoRoot, hookType, binary string) (string, error) {
+	dir, err := Dir(repoRoot)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	path := filepath.Join(dir, hookType)
+	if _, err := os.Lstat(path); err == nil && !IsInstalled(path) {
+		chained := path + ChainSuffix
+		if _, err := os.Lstat(chained); err == nil {
+			return "", fmt.Errorf("both %s and %s exist; refusing to overwrite", path, chained)
+		}
+		if err := os.Rename(path, chained); err != nil {
+			return "", fmt.Errorf("failed to move existing hook aside: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(Script(hookType, binary)), 0755); err != nil {
+		return "", fmt.Errorf("failed to write hook: %w", err)
+	}
+	return path, nil
+}
+
+// Uninstall removes the hookType hook and restores any chained hook. It
+// returns the removed path, or an empty string if no autocommiter hook was found.
+func Uninstall(repoRoot, hookType string) (string, error) {
+	dir, err := Dir(repoRoot)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, hookType)
+	if _, err := os.Lstat(path); err != nil {
+		return "", nil
+	}
+	if !IsInstalled(path) {
+		return "", fmt.Errorf("%s was not installed by autocommiter; leaving it alone", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return "", fmt.Errorf("failed to remove hook: %w", err)
+	}
+
+	chained := path + ChainSuffix
+	if _, err := os.Lstat(chained); err == nil {
+		if err := os.Rename(chained, path); err != nil {
+			return path, fmt.Errorf("failed to restore previous hook: %w", err)
+		}
+	}
+	return path, nil
+}
+
+// Script renders the shell hook that runs any chained hook and then hands
+// off to 'autocommiter hook <hookType>'. A failing autocommiter never blocks
+// the commit, except for the commit-msg validator rejecting the message.
+func Script(hookType, binary string) string {
+	run := fmt.Sprintf(`"$AUTOCOMMITER" hook %s "$@" || true`, hookType)
+	if hookType == CommitMsg {
+		// 127 means autocommiter is not installed.
+		run = fmt.Sprintf(`"$AUTOCOMMITER" hook %s "$@"
+status=$?
+[ $status -eq 127 ] && exit 0
+exit $status`, hookType)
+	}
+	return fmt.Sprintf(`#!/bin/sh
+%s: %s (remove with 'autocommiter uninstall-hook')
+hook_dir=$(dirname "$0")
+if [ -x "$hook_dir/%s%s" ]; then
+	"$hook_dir/%s%s" "$@" || exit $?
+fi
+
+AUTOCOMMITER=%s
+if [ ! -x "$AUTOCOMMITER" ]; then
+	AUTOCOMMITER=autocommiter
+fi
+%s
+`, Marker, hookType, hookType, ChainSuffix, hookType, ChainSuffix, shellQuote(binary), run)
+}
+
+// HasContent reports whether a commit message file contains anything besides
+// blank lines and comments starting with commentChar.
+func HasContent(message string, commentChar string) bool {
+	for _, line := range strings.Split(message, "\n") {
+		// Everything below the scissors line is the verbose diff.
+		if strings.HasPrefix(line, commentChar+" ------------------------ >8") {
+			break
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && !strings.HasPrefix(trimmed, commentChar) {
+			return true
+		}
+	}
+	return false
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
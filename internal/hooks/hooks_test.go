@@ -0,0 +1,125 @@
+package hooks
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func initRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Skipf("git init failed: %v: %s", err, out)
+	}
+	return dir
+}
+
+func TestInstallChainsAndUninstallRestores(t *testing.T) {
+	repo := initRepo(t)
+	hooksDir := filepath.Join(repo, ".githooks")
+	if out, err := exec.Command("git", "-C", repo, "config", "core.hooksPath", ".githooks").CombinedOutput(); err != nil {
+		t.Fatalf("git config: %v: %s", err, out)
+	}
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	existing := "#!/bin/sh\necho existing\n"
+	hookPath := filepath.Join(hooksDir, PrepareCommitMsg)
+	if err := os.WriteFile(hookPath, []byte(existing), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := Install(repo, PrepareCommitMsg, "/opt/bin/autocommiter")
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if path != hookPath {
+		t.Errorf("Install() path = %q; want %q (core.hooksPath)", path, hookPath)
+	}
+	if data, _ := os.ReadFile(hookPath + ChainSuffix); string(data) != existing {
+		t.Errorf("existing hook not moved aside, got %q", data)
+	}
+	if !IsInstalled(hookPath) {
+		t.Fatal("installed hook missing marker")
+	}
+
+	// Reinstalling must not chain the autocommiter hook to itself.
+	if _, err := Install(repo, PrepareCommitMsg, "/opt/bin/autocommiter"); err != nil {
+		t.Fatalf("second Install() error = %v", err)
+	}
+	if data, _ := os.ReadFile(hookPath + ChainSuffix); string(data) != existing {
+		t.Errorf("chained hook overwritten on reinstall, got %q", data)
+	}
+
+	if _, err := Uninstall(repo, PrepareCommitMsg); err != nil {
+		t.Fatalf("Uninstall() error = %v", err)
+	}
+	if data, _ := os.ReadFile(hookPath); string(data) != existing {
+		t.Errorf("existing hook not restored, got %q", data)
+	}
+	if _, err := Uninstall(repo, PrepareCommitMsg); err == nil {
+		t.Error("Uninstall() of a foreign hook should fail")
+	}
+}
+
+func TestScriptQuotesBinary(t *testing.T) {
+	s := Script(PrepareCommitMsg, "/it's here/autocommiter")
+	if !strings.Contains(s, `AUTOCOMMITER='/it'\''s here/autocommiter'`) {
+		t.Errorf("binary not shell-quoted:\n%s", s)
+	}
+	if !strings.Contains(s, `hook prepare-commit-msg "$@" || true`) {
+		t.Errorf("hook must never fail the commit:\n%s", s)
+	}
+}
+
+func TestCommitMsgScriptExitStatus(t *testing.T) {
+	dir := t.TempDir()
+	run := func(binary string) int {
+		t.Helper()
+		hook := filepath.Join(dir, CommitMsg)
+		if err := os.WriteFile(hook, []byte(Script(CommitMsg, binary)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		cmd := exec.Command(hook, "MSG")
+		cmd.Env = append(os.Environ(), "PATH="+dir+":/usr/bin:/bin")
+		err := cmd.Run()
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return exitErr.ExitCode()
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return 0
+	}
+
+	rejecting := filepath.Join(dir, "rejecting")
+	if err := os.WriteFile(rejecting, []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := run(rejecting); got != 1 {
+		t.Errorf("rejected message: exit %d; want 1", got)
+	}
+	if got := run(filepath.Join(dir, "missing")); got != 0 {
+		t.Errorf("missing autocommiter: exit %d; want 0", got)
+	}
+}
+
+func TestHasContent(t *testing.T) {
+	tests := []struct {
+		message string
+		want    bool
+	}{
+		{"", false},
+		{"\n# Please enter the commit message\n#\n", false},
+		{"Fix bug\n# comment\n", true},
+		{"# ------------------------ >8 ------------------------\ndiff --git a/x b/x\n", false},
+	}
+	for _, tt := range tests {
+		if got := HasContent(tt.message, "#"); got != tt.want {
+			t.Errorf("HasContent(%q) = %v; want %v", tt.message, got, tt.want)
+		}
+	}
+}
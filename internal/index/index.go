@@ -81,6 +81,11 @@ func InitDB() (*sql.DB, error) {
 		key TEXT PRIMARY KEY,
 		value TEXT
 	);
+	CREATE TABLE IF NOT EXISTS style_profile (
+		repo_path_hash TEXT PRIMARY KEY,
+		profile TEXT,
+		updated_at INTEGER
+	);
 	`
 	_, err = db.Exec(schema)
 	if err != nil {
@@ -142,6 +147,37 @@ func GetDefaultUser(repoRoot string) (string, error) {
 	return "", nil
 }
 
+// GetStyleProfile returns the cached commit style profile (as JSON) for the
+// repository if it is younger than maxAge.
+func GetStyleProfile(repoRoot string, maxAge time.Duration) (string, bool) {
+	db, err := InitDB()
+	if err != nil {
+		return "", false
+	}
+	defer db.Close()
+
+	var profile string
+	var updatedAt int64
+	err = db.QueryRow("SELECT profile, updated_at FROM style_profile WHERE repo_path_hash = ?", GetRepoHash(repoRoot)).Scan(&profile, &updatedAt)
+	if err != nil || time.Since(time.Unix(updatedAt, 0)) > maxAge {
+		return "", false
+	}
+	return profile, true
+}
+
+// SetStyleProfile caches the commit style profile (as JSON) for the repository.
+func SetStyleProfile(repoRoot string, profile string) error {
+	db, err := InitDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	_, err = db.Exec("INSERT OR REPLACE INTO style_profile (repo_path_hash, profile, updated_at) VALUES (?, ?, ?)",
+		GetRepoHash(repoRoot), profile, time.Now().Unix())
+	return err
+}
+
 func ListAllCache() {
 	db, err := InitDB()
 	if err != nil {
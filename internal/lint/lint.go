@@ -0,0 +1,364 @@
+// Package lint checks commit messages against configurable rules and fixes
+// the violations that can be fixed mechanically.
+package lint
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nathfavour/autocommiter.go/internal/conventional"
+)
+
+// Rule IDs, used in violations and Config.DisabledRules.
+const (
+	RuleType          = "type"
+	RuleScope         = "scope"
+	RuleSubjectLength = "subject-length"
+	RuleSubjectPeriod = "subject-period"
+	RuleBodyWrap      = "body-wrap"
+	RuleTrailers      = "trailers"
+)
+
+// Rules lists every rule ID.
+var Rules = []string{RuleType, RuleScope, RuleSubjectLength, RuleSubjectPeriod, RuleBodyWrap, RuleTrailers}
+
+// What generation does with a message that breaks the rules.
+const (
+	// OnViolationReprompt asks the model once more with the violations,
+	// then auto-fixes what is left.
+	OnViolationReprompt = "reprompt"
+	// OnViolationFix only auto-fixes.
+	OnViolationFix = "fix"
+	// OnViolationWarn keeps the message and prints the violations.
+	OnViolationWarn = "warn"
+)
+
+// Config selects the rules messages are held to. Zero values fall back to
+// Default.
+type Config struct {
+	Disabled bool `json:"disabled,omitempty"`
+	// Conventional requires Conventional Commits subjects. Otherwise the
+	// type and scope rules only check subjects that follow it.
+	Conventional bool `json:"conventional,omitempty"`
+	// Types are the allowed Conventional Commits types.
+	Types []string `json:"types,omitempty"`
+	// Scopes are the allowed scopes; any scope is allowed when empty.
+	Scopes           []string `json:"scopes,omitempty"`
+	MaxSubjectLength int      `json:"max_subject_length,omitempty"`
+	// BodyWrap is the longest allowed body line.
+	BodyWrap int `json:"body_wrap,omitempty"`
+	// Trailers are footer tokens every message must carry, e.g. "Signed-off-by".
+	Trailers      []string `json:"trailers,omitempty"`
+	DisabledRules []string `json:"disabled_rules,omitempty"`
+	// OnViolation is one of OnViolationReprompt, OnViolationFix or
+	// OnViolationWarn.
+	OnViolation string `json:"on_violation,omitempty"`
+}
+
+// Default returns the default rules: the standard Conventional Commits
+// types, 72-character subjects without a trailing period, and bodies wrapped
+// at 72 columns.
+func Default() Config {
+	return Config{
+		Types:            []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"},
+		MaxSubjectLength: 72,
+		BodyWrap:         72,
+		OnViolation:      OnViolationReprompt,
+	}
+}
+
+// WithDefaults fills unset fields of c from Default.
+func (c Config) WithDefaults() Config {
+	d := Default()
+	if len(c.Types) == 0 {
+		c.Types = d.Types
+	}
+	if c.MaxSubjectLength == 0 {
+		c.MaxSubjectLength = d.MaxSubjectLength
+	}
+	if c.BodyWrap == 0 {
+		c.BodyWrap = d.BodyWrap
+	}
+	if c.OnViolation == "" {
+		c.OnViolation = d.OnViolation
+	}
+	return c
+}
+
+// Validate reports unknown rule IDs and OnViolation values.
+func (c Config) Validate() error {
+	for _, id := range c.DisabledRules {
+		if !contains(Rules, id) {
+			return fmt.Errorf("unknown lint rule %q (use one of %s)", id, strings.Join(Rules, ", "))
+		}
+	}
+	switch c.OnViolation {
+	case "", OnViolationReprompt, OnViolationFix, OnViolationWarn:
+		return nil
+	}
+	return fmt.Errorf("invalid on_violation %q (use reprompt, fix or warn)", c.OnViolation)
+}
+
+func (c Config) enabled(rule string) bool {
+	return !contains(c.DisabledRules, rule)
+}
+
+// Violation is a broken rule.
+type Violation struct {
+	Rule string `json:"rule"`
+	// Line is the 1-based line of the message, 0 for the whole message.
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+	// Fixable reports whether Fix resolves the violation.
+	Fixable bool `json:"fixable"`
+}
+
+func (v Violation) String() string {
+	if v.Line > 0 {
+		return fmt.Sprintf("line %d: %s [%s]", v.Line, v.Message, v.Rule)
+	}
+	return fmt.Sprintf("%s [%s]", v.Message, v.Rule)
+}
+
+// Lint checks message against cfg. Merge, revert, fixup and squash messages
+// written by git are not checked.
+func Lint(message string, cfg Config) []Violation {
+	if cfg.Disabled {
+		return nil
+	}
+	cfg = cfg.WithDefaults()
+	message = normalize(message)
+	if message == "" || isAutomatic(message) {
+		return nil
+	}
+
+	c := conventional.Parse(message)
+	lines := strings.Split(message, "\n")
+	var vs []Violation
+
+	if cfg.enabled(RuleType) {
+		switch {
+		case c.Conventional && !contains(cfg.Types, c.Type):
+			vs = append(vs, Violation{Rule: RuleType, Line: 1, Message: fmt.Sprintf("type %q is not one of %s", c.Type, strings.Join(cfg.Types, ", "))})
+		case !c.Conventional && cfg.Conventional:
+			vs = append(vs, Violation{Rule: RuleType, Line: 1, Message: "subject does not follow Conventional Commits (type(scope): description)"})
+		}
+	}
+	if cfg.enabled(RuleScope) && c.Scope != "" && len(cfg.Scopes) > 0 {
+		for _, s := range strings.Split(c.Scope, ",") {
+			if s = strings.TrimSpace(s); !contains(cfg.Scopes, s) {
+				vs = append(vs, Violation{Rule: RuleScope, Line: 1, Message: fmt.Sprintf("scope %q is not one of %s", s, strings.Join(cfg.Scopes, ", "))})
+			}
+		}
+	}
+	if n := utf8.RuneCountInString(c.Subject); cfg.enabled(RuleSubjectLength) && n > cfg.MaxSubjectLength {
+		vs = append(vs, Violation{Rule: RuleSubjectLength, Line: 1, Message: fmt.Sprintf("subject is %d characters long; the limit is %d", n, cfg.MaxSubjectLength)})
+	}
+	if cfg.enabled(RuleSubjectPeriod) && endsWithPeriod(c.Subject) {
+		vs = append(vs, Violation{Rule: RuleSubjectPeriod, Line: 1, Message: "subject ends with a period", Fixable: true})
+	}
+	if cfg.enabled(RuleBodyWrap) {
+		if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+			vs = append(vs, Violation{Rule: RuleBodyWrap, Line: 2, Message: "subject and body are not separated by a blank line", Fixable: true})
+		}
+		last := len(lines)
+		if len(c.Footers) > 0 {
+			// Trailers are never wrapped.
+			last = 1
+			if i := strings.LastIndex(message, "\n\n"); i >= 0 {
+				last = strings.Count(message[:i], "\n") + 1
+			}
+		}
+		for i := 1; i < last; i++ {
+			if n := utf8.RuneCountInString(lines[i]); n > cfg.BodyWrap && wrappable(lines[i]) {
+				vs = append(vs, Violation{Rule: RuleBodyWrap, Line: i + 1, Message: fmt.Sprintf("body line is %d characters long; wrap at %d", n, cfg.BodyWrap), Fixable: true})
+			}
+		}
+	}
+	if cfg.enabled(RuleTrailers) {
+		for _, token := range cfg.Trailers {
+			if _, ok := c.Footer(token); !ok {
+				vs = append(vs, Violation{Rule: RuleTrailers, Message: fmt.Sprintf("missing %q trailer", token)})
+			}
+		}
+	}
+	return vs
+}
+
+// endsWithPeriod reports whether s ends with a single full stop. An
+// ellipsis is deliberate and does not count.
+func endsWithPeriod(s string) bool {
+	return strings.HasSuffix(s, ".") && !strings.HasSuffix(s, "..")
+}
+
+// Fix resolves the fixable violations of message: it drops a trailing
+// period from the subject, separates the body from the subject and rewraps
+// long body paragraphs. Other violations are left alone.
+func Fix(message string, cfg Config) string {
+	if cfg.Disabled {
+		return message
+	}
+	cfg = cfg.WithDefaults()
+	message = normalize(message)
+	if message == "" || isAutomatic(message) {
+		return message
+	}
+
+	subject, rest, _ := strings.Cut(message, "\n")
+	if cfg.enabled(RuleSubjectPeriod) {
+		subject = strings.TrimRight(subject, " ")
+		if endsWithPeriod(subject) {
+			subject = strings.TrimSuffix(subject, ".")
+		}
+	}
+	if !cfg.enabled(RuleBodyWrap) || rest == "" {
+		return strings.TrimSpace(subject + "\n" + rest)
+	}
+	rest = strings.Trim(rest, "\n")
+
+	paragraphs := strings.Split(rest, "\n\n")
+	wrapUntil := len(paragraphs)
+	if len(conventional.Parse(message).Footers) > 0 {
+		wrapUntil--
+	}
+	for i := 0; i < wrapUntil; i++ {
+		paragraphs[i] = wrapParagraph(paragraphs[i], cfg.BodyWrap)
+	}
+	return subject + "\n\n" + strings.Join(paragraphs, "\n\n")
+}
+
+// Clean removes the comment lines git adds to a commit message file, and
+// everything below the scissors line, the way git's default cleanup does.
+func Clean(message, commentChar string) string {
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if strings.HasPrefix(line, commentChar+" ------------------------ >8") {
+			break
+		}
+		if strings.HasPrefix(line, commentChar) {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t\r"))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// wrapParagraph rewraps the lines of a paragraph that are longer than width.
+// List items are wrapped separately with a hanging indent; lines that are
+// not wrappable are kept as they are.
+func wrapParagraph(paragraph string, width int) string {
+	var out []string
+	var item []string
+	indent := ""
+	flush := func() {
+		if len(item) == 0 {
+			return
+		}
+		out = append(out, wrapWords(strings.Join(item, " "), width, indent)...)
+		item, indent = nil, ""
+	}
+
+	lines := strings.Split(paragraph, "\n")
+	long := false
+	for _, line := range lines {
+		if utf8.RuneCountInString(line) > width && wrappable(line) {
+			long = true
+		}
+	}
+	if !long {
+		return paragraph
+	}
+
+	for _, line := range lines {
+		switch {
+		case !wrappable(line):
+			flush()
+			out = append(out, line)
+		case listMarker(line) != "":
+			flush()
+			indent = strings.Repeat(" ", len(listMarker(line)))
+			item = append(item, line)
+		default:
+			item = append(item, strings.TrimSpace(line))
+		}
+	}
+	flush()
+	return strings.Join(out, "\n")
+}
+
+// wrapWords breaks text into lines of at most width runes, indenting
+// continuation lines. A word longer than width gets a line of its own.
+func wrapWords(text string, width int, indent string) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		prefix := indent
+		if len(lines) == 0 {
+			prefix = ""
+		}
+		switch {
+		case line == "":
+			line = prefix + word
+		case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = indent + word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// listMarker returns the "- ", "* " or "1. " that starts a list item.
+func listMarker(line string) string {
+	if strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") {
+		return line[:2]
+	}
+	i := 0
+	for i < len(line) && line[i] >= '0' && line[i] <= '9' {
+		i++
+	}
+	if i > 0 && strings.HasPrefix(line[i:], ". ") {
+		return line[:i+2]
+	}
+	return ""
+}
+
+// wrappable reports whether a line may be rewrapped. Indented lines are
+// code or quoted output, and single words such as URLs cannot be shortened.
+func wrappable(line string) bool {
+	if strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "    ") {
+		return false
+	}
+	return len(strings.Fields(line)) > 1
+}
+
+func normalize(message string) string {
+	lines := strings.Split(strings.ReplaceAll(message, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func isAutomatic(message string) bool {
+	for _, prefix := range []string{"Merge ", "Revert \"", "fixup! ", "squash! ", "amend! "} {
+		if strings.HasPrefix(message, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package lint
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func rules(vs []Violation) []string {
+	var ids []string
+	for _, v := range vs {
+		ids = append(ids, v.Rule)
+	}
+	return ids
+}
+
+func TestLint(t *testing.T) {
+	long := "This paragraph explains the change in far more words than fit on a single wrapped line."
+	tests := []struct {
+		name    string
+		message string
+		cfg     Config
+		want    []string
+	}{
+		{"clean", "feat(api): add pagination\n\nPages are 50 items long.", Config{}, nil},
+		{"freeform allowed", "Add pagination", Config{}, nil},
+		{"freeform required conventional", "Add pagination", Config{Conventional: true}, []string{RuleType}},
+		{"unknown type", "feature: add pagination", Config{}, []string{RuleType}},
+		{"custom types", "feat: add pagination", Config{Types: []string{"add", "fix"}}, []string{RuleType}},
+		{"gitmoji type", "✨ feat(api): add pagination", Config{}, nil},
+		{"scope whitelist", "fix(ui,db): clamp page size", Config{Scopes: []string{"api", "ui"}}, []string{RuleScope}},
+		{"subject length", "fix: " + strings.Repeat("x", 70), Config{}, []string{RuleSubjectLength}},
+		{"custom subject length", "fix: clamp page size", Config{MaxSubjectLength: 10}, []string{RuleSubjectLength}},
+		{"trailing period", "fix: clamp page size.", Config{}, []string{RuleSubjectPeriod}},
+		{"trailing ellipsis", "fix: retry until the lock frees...", Config{}, nil},
+		{"body wrap", "fix: clamp page size\n\n" + long, Config{}, []string{RuleBodyWrap}},
+		{"no blank line", "fix: clamp page size\nbecause it overflowed", Config{}, []string{RuleBodyWrap}},
+		{"long url", "fix: clamp page size\n\nhttps://example.com/" + strings.Repeat("a", 80), Config{}, nil},
+		{"long trailer", "fix: clamp page size\n\nReviewed-by: " + long, Config{}, nil},
+		{"trailer directly after subject", "fix: clamp page size\nRefs: #12", Config{}, []string{RuleBodyWrap}},
+		{"missing trailer", "fix: clamp page size", Config{Trailers: []string{"Signed-off-by"}}, []string{RuleTrailers}},
+		{"trailer present", "fix: clamp page size\n\nSigned-off-by: A <a@example.com>", Config{Trailers: []string{"signed-off-by"}}, nil},
+		{"disabled rule", "fix: clamp page size.", Config{DisabledRules: []string{RuleSubjectPeriod}}, nil},
+		{"disabled", "feature: clamp page size.", Config{Disabled: true}, nil},
+		{"merge", "Merge branch 'main' into feature.", Config{Conventional: true}, nil},
+		{"fixup", "fixup! fix: clamp page size", Config{Conventional: true}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rules(Lint(tt.message, tt.cfg)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lint = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLintLines(t *testing.T) {
+	msg := "fix: y\n\nshort line\n" + strings.Repeat("word ", 20)
+	vs := Lint(msg, Config{})
+	if len(vs) != 1 || vs[0].Line != 4 || !vs[0].Fixable {
+		t.Errorf("Lint = %+v; want one fixable violation on line 4", vs)
+	}
+}
+
+func TestFix(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{"period", "fix: clamp page size.", "fix: clamp page size"},
+		{"ellipsis", "fix: retry until the lock frees...", "fix: retry until the lock frees..."},
+		{"blank line", "fix: clamp page size\nIt overflowed.", "fix: clamp page size\n\nIt overflowed."},
+		{
+			"wrap paragraph",
+			"docs: explain setup\n\nThis paragraph explains the change in far more words than fit on a single\nwrapped line.",
+			"docs: explain setup\n\nThis paragraph explains the change in far more words than fit on a\nsingle wrapped line.",
+		},
+		{
+			"wrap list item",
+			"docs: explain setup\n\n- first item that is long enough to need wrapping once it reaches the end\n- second",
+			"docs: explain setup\n\n- first item that is long enough to need wrapping once it reaches the\n  end\n- second",
+		},
+		{
+			"keep code and trailers",
+			"docs: explain setup\n\n    go run ./cmd/autocommiter --with --a --very --long --command --line --here\n\nReviewed-by: " + strings.Repeat("Name ", 16),
+			"docs: explain setup\n\n    go run ./cmd/autocommiter --with --a --very --long --command --line --here\n\nReviewed-by: " + strings.TrimSpace(strings.Repeat("Name ", 16)),
+		},
+		{"short paragraph untouched", "docs: x\n\nline one\nline two", "docs: x\n\nline one\nline two"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Fix(tt.message, Config{})
+			if got != tt.want {
+				t.Errorf("Fix =\n%q\nwant\n%q", got, tt.want)
+			}
+			for _, v := range Lint(got, Config{}) {
+				if v.Fixable {
+					t.Errorf("fixed message still has %v", v)
+				}
+			}
+		})
+	}
+}
+
+func TestClean(t *testing.T) {
+	msg := "fix: y\n\nbody\n# Please enter the commit message\n# ------------------------ >8 ------------------------\ndiff --git a/x b/x\n"
+	if got := Clean(msg, "#"); got != "fix: y\n\nbody" {
+		t.Errorf("Clean = %q", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := (Config{DisabledRules: []string{"subject-case"}}).Validate(); err == nil {
+		t.Error("Validate accepted an unknown rule")
+	}
+	if err := (Config{OnViolation: "abort"}).Validate(); err == nil {
+		t.Error("Validate accepted an unknown on_violation")
+	}
+	if err := Default().Validate(); err != nil {
+		t.Errorf("Validate(Default()) = %v", err)
+	}
+}
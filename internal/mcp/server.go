@@ -0,0 +1,226 @@
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ProtocolVersion is the MCP revision implemented by Server.
+const ProtocolVersion = "2024-11-05"
+
+// JSON-RPC 2.0 error codes.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// Tool is a callable exposed through tools/list and tools/call.
+type Tool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"inputSchema"`
+	// Handler receives the raw "arguments" object. A string result is sent as
+	// text; anything else is sent as JSON text plus structured content.
+	Handler func(args json.RawMessage) (interface{}, error) `json:"-"`
+}
+
+// Request is a JSON-RPC 2.0 request or notification.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a JSON-RPC 2.0 response.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is a JSON-RPC 2.0 error object.
+type Error struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// Content is a single item of a tool call result.
+type Content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// ToolResult is the result of tools/call.
+type ToolResult struct {
+	Content           []Content   `json:"content"`
+	StructuredContent interface{} `json:"structuredContent,omitempty"`
+	IsError           bool        `json:"isError,omitempty"`
+}
+
+// Server dispatches MCP requests to a fixed set of tools.
+type Server struct {
+	name    string
+	version string
+	tools   []Tool
+	byName  map[string]Tool
+}
+
+// NewServer creates a server advertising itself as name/version.
+func NewServer(name, version string, tools []Tool) *Server {
+	s := &Server{name: name, version: version, tools: tools, byName: make(map[string]Tool)}
+	for _, t := range tools {
+		s.byName[t.Name] = t
+	}
+	return s
+}
+
+// Serve reads newline-delimited JSON-RPC messages from in and writes responses
+// to out until in is exhausted.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	var mu sync.Mutex
+	enc := json.NewEncoder(out)
+	write := func(resp *Response) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return enc.Encode(resp)
+	}
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req Request
+		if err := json.Unmarshal(line, &req); err != nil {
+			if werr := write(errorResponse(json.RawMessage("null"), CodeParseError, "parse error: "+err.Error())); werr != nil {
+				return werr
+			}
+			continue
+		}
+
+		if resp := s.Handle(req); resp != nil {
+			if err := write(resp); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// Handle processes one request. It returns nil for notifications.
+func (s *Server) Handle(req Request) *Response {
+	isNotification := len(req.ID) == 0
+
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		if isNotification {
+			return nil
+		}
+		return errorResponse(req.ID, CodeInvalidRequest, "invalid JSON-RPC 2.0 request")
+	}
+
+	var result interface{}
+	var rpcErr *Error
+
+	switch req.Method {
+	case "initialize":
+		result = map[string]interface{}{
+			"protocolVersion": ProtocolVersion,
+			"capabilities": map[string]interface{}{
+				"tools": map[string]interface{}{},
+			},
+			"serverInfo": map[string]string{
+				"name":    s.name,
+				"version": s.version,
+			},
+		}
+	case "ping":
+		result = map[string]interface{}{}
+	case "tools/list":
+		result = map[string]interface{}{"tools": s.tools}
+	case "tools/call":
+		result, rpcErr = s.callTool(req.Params)
+	default:
+		if isNotification {
+			// notifications/initialized and friends need no reply.
+			return nil
+		}
+		rpcErr = &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+	}
+
+	if isNotification {
+		return nil
+	}
+	if rpcErr != nil {
+		return &Response{JSONRPC: "2.0", ID: req.ID, Error: rpcErr}
+	}
+	return &Response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+func (s *Server) callTool(params json.RawMessage) (interface{}, *Error) {
+	var call struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &call); err != nil || call.Name == "" {
+		return nil, &Error{Code: CodeInvalidParams, Message: "tools/call requires a tool name"}
+	}
+
+	tool, ok := s.byName[call.Name]
+	if !ok {
+		return nil, &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("unknown tool: %s", call.Name)}
+	}
+
+	if len(call.Arguments) == 0 || string(call.Arguments) == "null" {
+		call.Arguments = json.RawMessage("{}")
+	}
+
+	out, err := tool.Handler(call.Arguments)
+	if err != nil {
+		// Tool failures are reported in the result so the client can show
+		// them to the model instead of treating them as protocol errors.
+		return ToolResult{
+			Content:           []Content{{Type: "text", Text: err.Error()}},
+			StructuredContent: map[string]string{"error": err.Error()},
+			IsError:           true,
+		}, nil
+	}
+
+	return NewToolResult(out), nil
+}
+
+// NewToolResult wraps a handler's return value in a ToolResult.
+func NewToolResult(out interface{}) ToolResult {
+	switch v := out.(type) {
+	case string:
+		return ToolResult{Content: []Content{{Type: "text", Text: v}}}
+	case json.RawMessage:
+		var structured interface{}
+		_ = json.Unmarshal(v, &structured)
+		return ToolResult{Content: []Content{{Type: "text", Text: string(v)}}, StructuredContent: structured}
+	}
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		return ToolResult{Content: []Content{{Type: "text", Text: err.Error()}}, IsError: true}
+	}
+	return ToolResult{Content: []Content{{Type: "text", Text: string(data)}}, StructuredContent: out}
+}
+
+func errorResponse(id json.RawMessage, code int, message string) *Response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &Response{JSONRPC: "2.0", ID: id, Error: &Error{Code: code, Message: message}}
+}
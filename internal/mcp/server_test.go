@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testServer() *Server {
+	return NewServer("test", "0.0.1", []Tool{
+		{
+			Name:        "echo",
+			Description: "Echo the text argument",
+			InputSchema: json.RawMessage(`{"type":"object","properties":{"text":{"type":"string"}}}`),
+			Handler: func(args json.RawMessage) (interface{}, error) {
+				var p struct {
+					Text string `json:"text"`
+				}
+				if err := json.Unmarshal(args, &p); err != nil {
+					return nil, err
+				}
+				return map[string]string{"text": p.Text}, nil
+			},
+		},
+		{
+			Name:        "fail",
+			Description: "Always fails",
+			InputSchema: json.RawMessage(`{"type":"object"}`),
+			Handler: func(args json.RawMessage) (interface{}, error) {
+				return nil, errors.New(`bad "quoted" thing`)
+			},
+		},
+	})
+}
+
+func TestServe(t *testing.T) {
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"fail"}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"missing"}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"bogus"}`,
+		`not json`,
+	}, "\n")
+
+	var out bytes.Buffer
+	if err := testServer().Serve(strings.NewReader(in), &out); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("got %d responses; want 7:\n%s", len(lines), out.String())
+	}
+
+	type response struct {
+		ID     json.RawMessage `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *Error          `json:"error"`
+	}
+	var responses []response
+	for _, l := range lines {
+		var r response
+		if err := json.Unmarshal([]byte(l), &r); err != nil {
+			t.Fatalf("invalid response %q: %v", l, err)
+		}
+		responses = append(responses, r)
+	}
+
+	if !strings.Contains(string(responses[0].Result), `"protocolVersion":"2024-11-05"`) {
+		t.Errorf("initialize result = %s", responses[0].Result)
+	}
+	if !strings.Contains(string(responses[1].Result), `"name":"echo"`) {
+		t.Errorf("tools/list result = %s", responses[1].Result)
+	}
+	if !strings.Contains(string(responses[2].Result), `"structuredContent":{"text":"hi"}`) {
+		t.Errorf("echo result = %s", responses[2].Result)
+	}
+
+	var failed ToolResult
+	if err := json.Unmarshal(responses[3].Result, &failed); err != nil || !failed.IsError || failed.Content[0].Text != `bad "quoted" thing` {
+		t.Errorf("fail result = %s", responses[3].Result)
+	}
+
+	if responses[4].Error == nil || responses[4].Error.Code != CodeInvalidParams {
+		t.Errorf("unknown tool error = %+v", responses[4].Error)
+	}
+	if responses[5].Error == nil || responses[5].Error.Code != CodeMethodNotFound {
+		t.Errorf("unknown method error = %+v", responses[5].Error)
+	}
+	if responses[6].Error == nil || responses[6].Error.Code != CodeParseError || string(responses[6].ID) != "null" {
+		t.Errorf("parse error = %+v (id %s)", responses[6].Error, responses[6].ID)
+	}
+}
@@ -0,0 +1,294 @@
+package offline
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/nathfavour/autocommiter.go/internal/summarizer"
+)
+
+// MaxSubjectLength is the longest subject GenerateMessage will produce.
+const MaxSubjectLength = 72
+
+// Change categories, named after the Conventional Commits type they map to.
+const (
+	categoryTest  = "test"
+	categoryDocs  = "docs"
+	categoryCI    = "ci"
+	categoryBuild = "build"
+	categoryChore = "chore"
+	categoryCode  = "code"
+)
+
+var dependencyFiles = map[string]bool{
+	"go.mod":            true,
+	"go.sum":            true,
+	"go.work":           true,
+	"go.work.sum":       true,
+	"package.json":      true,
+	"package-lock.json": true,
+	"yarn.lock":         true,
+	"pnpm-lock.yaml":    true,
+	"bun.lockb":         true,
+	"cargo.toml":        true,
+	"cargo.lock":        true,
+	"requirements.txt":  true,
+	"pyproject.toml":    true,
+	"poetry.lock":       true,
+	"pipfile":           true,
+	"pipfile.lock":      true,
+	"gemfile":           true,
+	"gemfile.lock":      true,
+	"composer.json":     true,
+	"composer.lock":     true,
+	"pom.xml":           true,
+	"build.gradle":      true,
+}
+
+var docExtensions = map[string]bool{
+	".md":       true,
+	".mdx":      true,
+	".rst":      true,
+	".adoc":     true,
+	".txt":      true,
+	".markdown": true,
+}
+
+var choreExtensions = map[string]bool{
+	".yml":  true,
+	".yaml": true,
+	".toml": true,
+	".ini":  true,
+	".cfg":  true,
+	".json": true,
+}
+
+// Directories that only group code and make poor scopes on their own.
+var containerDirs = map[string]bool{
+	"internal": true,
+	"cmd":      true,
+	"pkg":      true,
+	"src":      true,
+	"lib":      true,
+	"app":      true,
+	"apps":     true,
+	"packages": true,
+	"services": true,
+	"crates":   true,
+	"modules":  true,
+}
+
+// GenerateMessage builds a deterministic Conventional Commits message from the
+// staged file changes without contacting any model. scopes are the monorepo
+// scopes the changes touch: a single one is used as the scope, several mean
+// no scope, and none falls back to the directory of the changes.
+func GenerateMessage(changes []summarizer.FileChange, scopes []string) string {
+	if len(changes) == 0 {
+		return "chore: update files"
+	}
+
+	byCategory := make(map[string][]summarizer.FileChange)
+	for _, fc := range changes {
+		c := Categorize(fc.File)
+		byCategory[c] = append(byCategory[c], fc)
+	}
+
+	commitType, relevant := pickType(byCategory)
+	scope := inferScope(commitType, relevant, scopes)
+
+	header := commitType
+	if scope != "" {
+		header += "(" + scope + ")"
+	}
+	header += ": "
+
+	subject := header + describe(relevant, MaxSubjectLength-len(header))
+
+	if len(changes) == 1 {
+		return subject
+	}
+
+	var body []string
+	sorted := append([]summarizer.FileChange(nil), changes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].File < sorted[j].File })
+	for i, fc := range sorted {
+		if i >= 10 {
+			body = append(body, fmt.Sprintf("- and %d more files", len(sorted)-i))
+			break
+		}
+		body = append(body, fmt.Sprintf("- %s %s", verbFor(fc.Status), fc.File))
+	}
+
+	return subject + "\n\n" + strings.Join(body, "\n")
+}
+
+// Categorize classifies a repository-relative path.
+func Categorize(file string) string {
+	lower := strings.ToLower(file)
+	base := path.Base(lower)
+	ext := path.Ext(base)
+
+	switch {
+	case strings.HasPrefix(lower, ".github/workflows/"),
+		strings.HasPrefix(lower, ".circleci/"),
+		strings.HasPrefix(lower, ".buildkite/"),
+		base == ".gitlab-ci.yml",
+		base == ".travis.yml",
+		base == "jenkinsfile",
+		base == "azure-pipelines.yml":
+		return categoryCI
+	case dependencyFiles[base]:
+		return categoryBuild
+	case strings.HasSuffix(base, "_test.go"),
+		strings.Contains(base, ".test."),
+		strings.Contains(base, ".spec."),
+		strings.HasPrefix(base, "test_") && ext == ".py",
+		hasDir(lower, "test", "tests", "__tests__", "testdata", "spec"):
+		return categoryTest
+	case docExtensions[ext],
+		strings.HasPrefix(base, "license"),
+		strings.HasPrefix(base, "changelog"),
+		hasDir(lower, "docs", "doc"):
+		return categoryDocs
+	case choreExtensions[ext],
+		strings.HasPrefix(base, ".git"),
+		strings.HasPrefix(base, ".editorconfig"),
+		base == "makefile",
+		base == "dockerfile":
+		return categoryChore
+	}
+	return categoryCode
+}
+
+// pickType chooses the commit type and the changes that justify it.
+func pickType(byCategory map[string][]summarizer.FileChange) (string, []summarizer.FileChange) {
+	if code, ok := byCategory[categoryCode]; ok {
+		// New code is treated as a feature; edits and removals as refactors
+		// since a diff alone cannot tell a fix from a restructuring.
+		for _, fc := range code {
+			if fc.Status == "A" {
+				return "feat", code
+			}
+		}
+		return "refactor", code
+	}
+
+	// Without code changes the most significant remaining category wins.
+	for _, c := range []string{categoryBuild, categoryCI, categoryTest, categoryDocs, categoryChore} {
+		if files, ok := byCategory[c]; ok {
+			return c, files
+		}
+	}
+	return categoryChore, nil
+}
+
+func inferScope(commitType string, changes []summarizer.FileChange, scopes []string) string {
+	switch commitType {
+	case categoryBuild:
+		return "deps"
+	case categoryCI, categoryDocs:
+		return ""
+	}
+	switch len(scopes) {
+	case 0:
+	case 1:
+		return scopes[0]
+	default:
+		return ""
+	}
+
+	scope := ""
+	for i, fc := range changes {
+		s := Scope(fc.File)
+		if i == 0 {
+			scope = s
+		} else if s != scope {
+			return ""
+		}
+	}
+	return scope
+}
+
+// Scope returns the most specific meaningful directory of file.
+func Scope(file string) string {
+	dir := path.Dir(file)
+	if dir == "." {
+		return ""
+	}
+	parts := strings.Split(dir, "/")
+	for _, p := range parts {
+		if p == "" || strings.HasPrefix(p, ".") || containerDirs[strings.ToLower(p)] {
+			continue
+		}
+		return strings.ToLower(p)
+	}
+	return ""
+}
+
+func describe(changes []summarizer.FileChange, maxLen int) string {
+	verb := ""
+	for i, fc := range changes {
+		v := verbFor(fc.Status)
+		if i == 0 {
+			verb = v
+		} else if v != verb {
+			verb = "update"
+		}
+	}
+	if verb == "" {
+		verb = "update"
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, fc := range changes {
+		name := path.Base(fc.File)
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for shown := len(names); shown >= 1; shown-- {
+		s := verb + " " + joinNames(names[:shown], len(names)-shown)
+		if len(s) <= maxLen {
+			return s
+		}
+	}
+	return fmt.Sprintf("%s %d files", verb, len(names))
+}
+
+func joinNames(names []string, more int) string {
+	if more > 0 {
+		return fmt.Sprintf("%s and %d more files", strings.Join(names, ", "), more)
+	}
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+func verbFor(status string) string {
+	switch status {
+	case "A":
+		return "add"
+	case "D":
+		return "remove"
+	}
+	return "update"
+}
+
+func hasDir(file string, dirs ...string) bool {
+	parts := strings.Split(path.Dir(file), "/")
+	for _, p := range parts {
+		for _, d := range dirs {
+			if p == d {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package offline
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/autocommiter.go/internal/summarizer"
+)
+
+func TestGenerateMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes []summarizer.FileChange
+		scopes  []string
+		subject string
+	}{
+		{
+			name:    "new code file",
+			changes: []summarizer.FileChange{{File: "internal/offline/offline.go", Status: "A"}},
+			subject: "feat(offline): add offline.go",
+		},
+		{
+			name:    "modified code file",
+			changes: []summarizer.FileChange{{File: "internal/api/client.go", Status: "M"}},
+			subject: "refactor(api): update client.go",
+		},
+		{
+			name: "dependency bump",
+			changes: []summarizer.FileChange{
+				{File: "go.mod", Status: "M"},
+				{File: "go.sum", Status: "M"},
+			},
+			subject: "build(deps): update go.mod and go.sum",
+		},
+		{
+			name:    "tests only",
+			changes: []summarizer.FileChange{{File: "internal/git/git_test.go", Status: "A"}},
+			subject: "test(git): add git_test.go",
+		},
+		{
+			name:    "docs only",
+			changes: []summarizer.FileChange{{File: "README.md", Status: "M"}},
+			subject: "docs: update README.md",
+		},
+		{
+			name:    "ci workflow",
+			changes: []summarizer.FileChange{{File: ".github/workflows/release.yml", Status: "D"}},
+			subject: "ci: remove release.yml",
+		},
+		{
+			name: "code wins over docs",
+			changes: []summarizer.FileChange{
+				{File: "internal/git/git.go", Status: "M"},
+				{File: "README.md", Status: "M"},
+			},
+			subject: "refactor(git): update git.go",
+		},
+		{
+			name: "mixed directories drop scope",
+			changes: []summarizer.FileChange{
+				{File: "internal/git/git.go", Status: "M"},
+				{File: "internal/api/client.go", Status: "M"},
+			},
+			subject: "refactor: update client.go and git.go",
+		},
+		{
+			name:    "resolved scope replaces the directory",
+			changes: []summarizer.FileChange{{File: "services/billing/main.go", Status: "M"}},
+			scopes:  []string{"billing-api"},
+			subject: "refactor(billing-api): update main.go",
+		},
+		{
+			name: "several resolved scopes drop scope",
+			changes: []summarizer.FileChange{
+				{File: "services/billing/main.go", Status: "M"},
+				{File: "services/billing/web/app.go", Status: "M"},
+			},
+			scopes:  []string{"billing-api", "billing-web"},
+			subject: "refactor: update app.go and main.go",
+		},
+		{
+			name:    "dependency scope wins over resolved scope",
+			changes: []summarizer.FileChange{{File: "services/billing/go.mod", Status: "M"}},
+			scopes:  []string{"billing-api"},
+			subject: "build(deps): update go.mod",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GenerateMessage(tt.changes, tt.scopes)
+			subject := strings.SplitN(got, "\n", 2)[0]
+			if subject != tt.subject {
+				t.Errorf("subject = %q; want %q", subject, tt.subject)
+			}
+		})
+	}
+}
+
+func TestGenerateMessageSubjectLength(t *testing.T) {
+	var changes []summarizer.FileChange
+	for _, f := range []string{"alpha_handler.go", "beta_handler.go", "gamma_handler.go", "delta_handler.go", "epsilon_handler.go"} {
+		changes = append(changes, summarizer.FileChange{File: "internal/handlers/" + f, Status: "M"})
+	}
+
+	got := GenerateMessage(changes, nil)
+	subject := strings.SplitN(got, "\n", 2)[0]
+	if len(subject) > MaxSubjectLength {
+		t.Errorf("subject %q is %d chars; want <= %d", subject, len(subject), MaxSubjectLength)
+	}
+	if !strings.Contains(got, "\n\n- update internal/handlers/alpha_handler.go") {
+		t.Errorf("expected file list in body, got %q", got)
+	}
+}
@@ -11,6 +11,7 @@ import (
 )
 
 type AccountManager struct {
+	repo          git.Repository
 	repoRoot      string
 	result        chan error
 	TargetAccount string
@@ -19,9 +20,10 @@ type AccountManager struct {
 	IsSingle      bool
 }
 
-func NewAccountManager(repoRoot string) *AccountManager {
+func NewAccountManager(repo git.Repository) *AccountManager {
 	return &AccountManager{
-		repoRoot: repoRoot,
+		repo:     repo,
+		repoRoot: repo.Root(),
 		result:   make(chan error, 1),
 	}
 }
@@ -89,10 +91,10 @@ func (m *AccountManager) discover() error {
 	}
 
 	// 3.2 Local Git Config/History
-	_, localEmail := git.GetLocalIdentity(m.repoRoot)
-	_, histEmail := git.GetHistoryIdentity(m.repoRoot)
+	_, localEmail := m.repo.LocalIdentity()
+	_, histEmail := m.repo.HistoryIdentity()
 	
-	owner := git.GetRemoteOwner(m.repoRoot)
+	owner := m.repo.RemoteOwner()
 
 	// Try to match emails/owner to an account
 	activeUser := auth.GetGithubUser()
@@ -159,9 +161,9 @@ func (m *AccountManager) Sync() error {
 	}
 
 	// Sync local git config if it differs
-	_, currentEmail := git.GetLocalIdentity(m.repoRoot)
+	_, currentEmail := m.repo.LocalIdentity()
 	if m.TargetEmail != "" && currentEmail != m.TargetEmail {
-		return git.SyncLocalConfig(m.repoRoot, m.TargetName, m.TargetEmail)
+		return m.repo.SetLocalIdentity(m.TargetName, m.TargetEmail)
 	}
 
 	return nil
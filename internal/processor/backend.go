@@ -0,0 +1,59 @@
+package processor
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/nathfavour/autocommiter.go/internal/config"
+	"github.com/nathfavour/autocommiter.go/internal/git"
+)
+
+// ValidateGitBackend returns an error for unknown backend names.
+func ValidateGitBackend(backend string) error {
+	for _, b := range git.Backends() {
+		if b == backend {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown git backend %q (supported: %s)", backend, strings.Join(git.Backends(), ", "))
+}
+
+// ResolveGitBackend returns the configured git backend, exec by default.
+func ResolveGitBackend(cfg config.Config) (string, error) {
+	backend := ""
+	if cfg.GitBackend != nil {
+		backend = strings.ToLower(strings.TrimSpace(*cfg.GitBackend))
+	}
+	if backend == "" {
+		backend = git.BackendExec
+	}
+	return backend, ValidateGitBackend(backend)
+}
+
+// OpenRepository opens the repository at repoRoot with the backend its
+// merged config selects.
+func OpenRepository(repoRoot string) git.Repository {
+	cfg, _ := config.LoadMergedConfig(repoRoot)
+	return openRepository(repoRoot, cfg, color.Output)
+}
+
+// openRepository falls back to the git binary when the backend is unknown or
+// go-git cannot open the repository, so a bad setting never blocks a commit.
+// Fallbacks are reported to w.
+func openRepository(repoRoot string, cfg config.Config, w io.Writer) git.Repository {
+	backend, err := ResolveGitBackend(cfg)
+	if err != nil {
+		color.New(color.FgYellow).Fprintf(w, "⚠️  %v; using %s\n", err, git.BackendExec)
+		return git.NewExecRepository(repoRoot)
+	}
+	if backend == git.BackendGoGit {
+		repo, err := git.NewGoGitRepository(repoRoot)
+		if err == nil {
+			return repo
+		}
+		color.New(color.FgYellow).Fprintf(w, "⚠️  go-git could not open %s (%v); using %s\n", repoRoot, err, git.BackendExec)
+	}
+	return git.NewExecRepository(repoRoot)
+}
@@ -0,0 +1,36 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nathfavour/autocommiter.go/internal/config"
+	"github.com/nathfavour/autocommiter.go/internal/git"
+)
+
+func TestResolveGitBackend(t *testing.T) {
+	if b, err := ResolveGitBackend(config.Config{}); b != git.BackendExec || err != nil {
+		t.Errorf("default = %q, %v", b, err)
+	}
+	goGit := " Go-Git "
+	if b, err := ResolveGitBackend(config.Config{GitBackend: &goGit}); b != git.BackendGoGit || err != nil {
+		t.Errorf("go-git = %q, %v", b, err)
+	}
+	libgit := "libgit2"
+	if _, err := ResolveGitBackend(config.Config{GitBackend: &libgit}); err == nil {
+		t.Error("ResolveGitBackend accepted an unknown backend")
+	}
+}
+
+func TestOpenRepositoryFallsBack(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	// go-git cannot open a directory that is not a repository.
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ".autocommiter.json"), []byte(`{"git_backend": "go-git"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if repo, ok := OpenRepository(root).(*git.ExecRepository); !ok || repo.Root() != root {
+		t.Errorf("OpenRepository = %T; want the exec backend for %s", repo, root)
+	}
+}
@@ -0,0 +1,478 @@
+package processor
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/nathfavour/autocommiter.go/internal/config"
+	"github.com/nathfavour/autocommiter.go/internal/git"
+)
+
+// DefaultJobs is how many repositories a batch prepares at once. Preparing
+// mostly waits on git and the AI provider, so it does not follow the CPU
+// count.
+const DefaultJobs = 4
+
+// Formats of the batch summary.
+const (
+	ReportTable = "table"
+	ReportJSON  = "json"
+)
+
+// Statuses of a repository in a batch.
+const (
+	StatusCommitted = "committed"
+	StatusClean     = "clean"   // nothing to commit
+	StatusSkipped   = "skipped" // left out at review
+	StatusFailed    = "failed"
+	statusReady     = "ready"
+)
+
+// Push results of a committed repository.
+const (
+	PushDone    = "pushed"
+	PushSkipped = "skipped"
+	PushFailed  = "failed"
+)
+
+// RepoResult is the outcome of one repository of a batch.
+type RepoResult struct {
+	Repo    string `json:"repo"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+	Commit  string `json:"commit,omitempty"`
+	Push    string `json:"push,omitempty"`
+	Error   string `json:"error,omitempty"`
+	// Kind is git.KindWorktree or git.KindSubmodule for those.
+	Kind string `json:"kind,omitempty"`
+	// Pointers is the commit recording the new commits of submodules, under
+	// the recurse submodule policy.
+	Pointers string `json:"pointers,omitempty"`
+	// HeldBack are the moved submodules whose pointers were not recorded
+	// because their own commit or push failed.
+	HeldBack []string `json:"held_back,omitempty"`
+	// Leaks are the security findings that failed the repository and
+	// Warnings those that did not, with their secrets masked.
+	Leaks    []LeakMatch `json:"leaks,omitempty"`
+	Warnings []LeakMatch `json:"warnings,omitempty"`
+
+	cfg   config.Config
+	files int
+}
+
+// ValidateReport returns an error for unknown summary formats.
+func ValidateReport(report string) error {
+	switch report {
+	case "", ReportTable, ReportJSON:
+		return nil
+	}
+	return fmt.Errorf("invalid report format %q (use table or json)", report)
+}
+
+// RunBatch commits several repositories. Staging, the security check and
+// message generation run for up to opts.Jobs repositories at once; the
+// messages are then reviewed together, and commits and pushes run one
+// repository at a time. It ends with a summary in the opts.Report format.
+func RunBatch(repos []string, opts CommitOptions) error {
+	if err := ValidateReport(opts.Report); err != nil {
+		return err
+	}
+	jobs := opts.Jobs
+	if jobs <= 0 {
+		jobs = DefaultJobs
+	}
+	if jobs > len(repos) {
+		jobs = len(repos)
+	}
+
+	color.Cyan("⚙️ Preparing %d repositories (%d at a time)...", len(repos), jobs)
+	results := prepareBatch(repos, opts, jobs)
+
+	if reviewBatch(results, opts.Force) {
+		commitBatch(results, opts)
+	} else {
+		color.Red("❌ Cancelled.\n")
+		for i := range results {
+			if results[i].Status == statusReady {
+				results[i].Status = StatusSkipped
+			}
+		}
+	}
+
+	if opts.Report == ReportJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+	printBatchReport(color.Output, results)
+	return nil
+}
+
+// prepareBatch prepares repos with a pool of jobs workers. Results keep the
+// order of repos. Nested repositories, such as submodules, are prepared by
+// the same worker as the repository containing them, one after the other,
+// so git never works on both at once. Workers share the terminal, so they
+// report through their results and print only a line of progress each.
+func prepareBatch(repos []string, opts CommitOptions, jobs int) []RepoResult {
+	results := make([]RepoResult, len(repos))
+	out := color.Output
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan []int)
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for group := range queue {
+				for _, i := range group {
+					results[i] = prepareBatchRepo(repos[i], opts)
+					mu.Lock()
+					printBatchProgress(out, results[i])
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, group := range nestedGroups(repos) {
+		queue <- group
+	}
+	close(queue)
+	wg.Wait()
+	return results
+}
+
+// nestedGroups groups the indexes of repos by the outermost repository
+// containing them, keeping their order.
+func nestedGroups(repos []string) [][]int {
+	var groups [][]int
+	index := make(map[string]int)
+	for i, r := range repos {
+		outer := r
+		for _, other := range repos {
+			if within(r, other) && (outer == r || within(outer, other)) {
+				outer = other
+			}
+		}
+		g, ok := index[outer]
+		if !ok {
+			g = len(groups)
+			index[outer] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+	return groups
+}
+
+// prepareBatchRepo stages, checks and generates the message of one
+// repository without prompting.
+func prepareBatchRepo(repoRoot string, opts CommitOptions) RepoResult {
+	r := RepoResult{Repo: repoRoot}
+	r.cfg, _ = config.LoadMergedConfig(repoRoot)
+	if kind := git.GetWorkTreeKind(repoRoot); kind != git.KindRepository {
+		r.Kind = kind
+	}
+
+	repo := openRepository(repoRoot, r.cfg, io.Discard)
+	stagedFiles, report, err := prepareRepo(repo, r.cfg, opts, false, io.Discard)
+	r.Leaks, r.Warnings = maskLeaks(report.leaks), maskLeaks(report.warnings)
+	if err != nil {
+		r.Status, r.Error = StatusFailed, err.Error()
+		return r
+	}
+	if len(stagedFiles) == 0 {
+		r.Status = StatusClean
+		return r
+	}
+	r.files = len(stagedFiles)
+	if r.Kind == git.KindSubmodule && git.IsDetachedHead(repoRoot) {
+		r.Status, r.Error = StatusFailed, "submodule is on a detached HEAD; check out a branch in it first"
+		return r
+	}
+
+	r.Message = opts.Message
+	if r.Message == "" {
+		r.Message, err = generateMessage(repo, nil, MessageOptions{Offline: opts.Offline, Log: io.Discard})
+		if err != nil {
+			r.Status, r.Error = StatusFailed, err.Error()
+			return r
+		}
+	}
+	r.Status = statusReady
+	return r
+}
+
+func printBatchProgress(w io.Writer, r RepoResult) {
+	switch r.Status {
+	case statusReady:
+		if r.Kind != "" {
+			color.New(color.FgGreen).Fprintf(w, "  ✓ %s (%s)\n", r.Repo, r.Kind)
+			return
+		}
+		color.New(color.FgGreen).Fprintf(w, "  ✓ %s\n", r.Repo)
+	case StatusClean:
+		color.New(color.Faint).Fprintf(w, "  - %s: nothing to commit\n", r.Repo)
+	default:
+		color.New(color.FgRed).Fprintf(w, "  ✗ %s: %s\n", r.Repo, r.Error)
+	}
+}
+
+// reviewBatch shows the prepared messages on one screen, where messages can
+// be edited and repositories left out. It reports false when the batch is
+// cancelled. Nothing is asked when force is set or every prepared repository
+// skips confirmation.
+func reviewBatch(results []RepoResult, force bool) bool {
+	ready := 0
+	confirm := false
+	for _, r := range results {
+		if r.Status != statusReady {
+			continue
+		}
+		ready++
+		if r.cfg.SkipConfirmation == nil || !*r.cfg.SkipConfirmation {
+			confirm = true
+		}
+	}
+	if ready == 0 || force || !confirm {
+		return true
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		color.New(color.FgCyan, color.Bold).Println("\n📋 Batch review:")
+		for i, r := range results {
+			if r.Status != statusReady {
+				continue
+			}
+			subject, _, _ := strings.Cut(r.Message, "\n")
+			fmt.Fprintf(color.Output, "  %s %s %s\n      %s\n",
+				color.New(color.Bold).Sprintf("%2d.", i+1),
+				filepath.Base(r.Repo),
+				color.New(color.Faint).Sprintf("(%d files)", r.files),
+				color.New(color.Italic).Sprint(subject))
+			printLeaks(color.Output, r.Warnings)
+		}
+		fmt.Fprint(color.Output, color.CyanString("🤔 [y]es commit all, [e N] edit, [x N] leave out, [n]o cancel: "))
+		input, err := reader.ReadString('\n')
+		if err != nil && input == "" {
+			return false
+		}
+
+		fields := strings.Fields(strings.ToLower(input))
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		case "e", "x":
+			i, ok := batchIndex(results, fields)
+			if !ok {
+				color.Yellow("⚠️ Pick a repository by its number, e.g. '%s 1'.", fields[0])
+				continue
+			}
+			if fields[0] == "x" {
+				results[i].Status = StatusSkipped
+				continue
+			}
+			edited, err := editMessage(results[i].Repo, results[i].Message)
+			if err != nil {
+				color.Red("✗ %v", err)
+				continue
+			}
+			if edited != "" {
+				results[i].Message = edited
+			}
+		default:
+			color.Yellow("⚠️ Unknown choice %q.", fields[0])
+		}
+	}
+}
+
+// batchIndex returns the result picked by the number in fields.
+func batchIndex(results []RepoResult, fields []string) (int, bool) {
+	if len(fields) < 2 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(fields[1])
+	if err != nil || n < 1 || n > len(results) || results[n-1].Status != statusReady {
+		return 0, false
+	}
+	return n - 1, true
+}
+
+// commitBatch commits and pushes the ready repositories one at a time, so
+// account discovery never switches accounts under another push. Submodules
+// come before their superproject, which then records their new commits
+// under the recurse policy.
+func commitBatch(results []RepoResult, opts CommitOptions) {
+	for i := range results {
+		r := &results[i]
+		recurse := false
+		if r.Status == statusReady || r.Status == StatusClean {
+			policy, _ := ResolveSubmodulePolicy(r.cfg, opts.Submodules)
+			recurse = policy == SubmodulesRecurse
+		}
+		if r.Status != statusReady && !recurse {
+			continue
+		}
+		if r.Status == statusReady {
+			color.Cyan("✍️ Committing %s...", r.Repo)
+		}
+		var heldBack []string
+		if recurse {
+			heldBack = unpushedSubmodules(results[:i], r.Repo, !opts.NoPush)
+		}
+		commitBatchRepo(r, opts, recurse, heldBack)
+		if r.Status == StatusFailed {
+			color.Red("  ✗ %s", r.Error)
+		}
+	}
+}
+
+// unpushedSubmodules returns the paths, relative to super, of the submodules
+// among results whose new commits did not reach their remote. Recording them
+// would point collaborators at commits they cannot fetch. Skipped pushes only
+// count when the superproject itself is pushed.
+func unpushedSubmodules(results []RepoResult, super string, pushing bool) []string {
+	var paths []string
+	for _, r := range results {
+		if r.Kind != git.KindSubmodule || !within(r.Repo, super) {
+			continue
+		}
+		if r.Status == StatusFailed || r.Push == PushFailed || (pushing && r.Push == PushSkipped) {
+			rel, _ := filepath.Rel(super, r.Repo)
+			paths = append(paths, filepath.ToSlash(rel))
+		}
+	}
+	return paths
+}
+
+func commitBatchRepo(r *RepoResult, opts CommitOptions, recurse bool, heldBack []string) {
+	repo := git.NewExecRepository(r.Repo)
+	if r.Status == statusReady {
+		if err := repo.Commit(r.Message); err != nil {
+			r.Status, r.Error = StatusFailed, err.Error()
+			return
+		}
+		r.Status = StatusCommitted
+		r.Commit, _ = repo.HeadCommit()
+	}
+	if recurse {
+		message, held, err := commitSubmodulePointers(r.Repo, r.cfg, heldBack)
+		r.HeldBack = held
+		if err != nil {
+			r.Error = err.Error()
+			if r.Status != StatusCommitted {
+				r.Status = StatusFailed
+				return
+			}
+		}
+		if message != "" {
+			sha, _ := repo.HeadCommit()
+			if r.Status == StatusCommitted {
+				r.Pointers = sha
+			} else {
+				r.Status, r.Message, r.Commit = StatusCommitted, message, sha
+			}
+		}
+	}
+	if r.Status != StatusCommitted {
+		return
+	}
+
+	if opts.NoPush {
+		r.Push = PushSkipped
+		return
+	}
+	if err := pushWithDiscovery(repo); err != nil {
+		r.Push, r.Error = PushFailed, err.Error()
+		return
+	}
+	r.Push = PushDone
+	syncForkIfEnabled(r.Repo, r.cfg)
+}
+
+// printBatchReport writes the summary table of a batch, followed by the
+// security findings of its repositories.
+func printBatchReport(w io.Writer, results []RepoResult) {
+	width := len("REPOSITORY")
+	for _, r := range results {
+		if n := len(filepath.Base(r.Repo)); n > width {
+			width = n
+		}
+	}
+
+	color.New(color.Bold).Fprintf(w, "\n%-*s  %-9s  %-7s  %-7s  %s\n", width, "REPOSITORY", "STATUS", "COMMIT", "PUSH", "MESSAGE")
+	for _, r := range results {
+		commit := short(r.Commit)
+		detail, _, _ := strings.Cut(r.Message, "\n")
+		if r.Pointers != "" {
+			detail += fmt.Sprintf(" (+ submodule pointers %s)", short(r.Pointers))
+		}
+		if len(r.HeldBack) > 0 {
+			detail += fmt.Sprintf(" (held back unpushed submodules: %s)", strings.Join(r.HeldBack, ", "))
+		}
+		if r.Error != "" {
+			detail = r.Error
+		}
+		line := fmt.Sprintf("%-*s  %-9s  %-7s  %-7s  %s", width, filepath.Base(r.Repo), r.Status, orDash(commit), orDash(r.Push), detail)
+		switch {
+		case r.Status == StatusFailed || r.Push == PushFailed:
+			color.New(color.FgRed).Fprintln(w, line)
+		case r.Status == StatusCommitted:
+			color.New(color.FgGreen).Fprintln(w, line)
+		default:
+			fmt.Fprintln(w, line)
+		}
+	}
+	printBatchFindings(w, results)
+}
+
+// printBatchFindings lists the security findings of each repository under
+// the summary table.
+func printBatchFindings(w io.Writer, results []RepoResult) {
+	for _, r := range results {
+		if len(r.Leaks) == 0 && len(r.Warnings) == 0 {
+			continue
+		}
+		color.New(color.Bold).Fprintf(w, "\n%s\n", filepath.Base(r.Repo))
+		if len(r.Leaks) > 0 {
+			color.New(color.FgRed).Fprintln(w, "  🚨 Potential PII or secrets:")
+			printLeaks(w, r.Leaks)
+		}
+		if len(r.Warnings) > 0 {
+			color.New(color.FgYellow).Fprintln(w, "  ⚠️  Possible secrets (high-entropy strings, not blocking):")
+			printLeaks(w, r.Warnings)
+		}
+	}
+}
+
+// maskLeaks returns copies of leaks whose secrets are masked, for results
+// that outlive the run.
+func maskLeaks(leaks []LeakMatch) []LeakMatch {
+	var masked []LeakMatch
+	for _, leak := range leaks {
+		leak.Content = maskSecret(leak.Content, leak.Secret)
+		masked = append(masked, leak)
+	}
+	return masked
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
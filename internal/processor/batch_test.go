@@ -0,0 +1,148 @@
+package processor
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/autocommiter.go/internal/git"
+)
+
+func TestPrepareBatchKeepsOrder(t *testing.T) {
+	var repos []string
+	for i := 0; i < 5; i++ {
+		repos = append(repos, t.TempDir())
+	}
+	results := prepareBatch(repos, CommitOptions{Offline: true}, 2)
+	if len(results) != len(repos) {
+		t.Fatalf("got %d results; want %d", len(results), len(repos))
+	}
+	for i, r := range results {
+		if r.Repo != repos[i] {
+			t.Errorf("results[%d].Repo = %s; want %s", i, r.Repo, repos[i])
+		}
+		// None of them is a git repository.
+		if r.Status != StatusFailed || r.Error == "" {
+			t.Errorf("results[%d] = %+v; want a failure", i, r)
+		}
+	}
+}
+
+func TestPrepareBatchCollectsFindings(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	files := []string{
+		`const owner = "jane.doe@corp-mail.io"` + "\n",
+		`const salt = "q8Zt3LmR9vXw2KpN7yHc4BfJ"` + "\n",
+	}
+	var repos []string
+	for _, content := range files {
+		dir := t.TempDir()
+		if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+			t.Skipf("git init failed: %v: %s", err, out)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\n"+content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if out, err := exec.Command("git", "-C", dir, "add", "main.go").CombinedOutput(); err != nil {
+			t.Fatalf("git add: %v: %s", err, out)
+		}
+		repos = append(repos, dir)
+	}
+
+	results := prepareBatch(repos, CommitOptions{Offline: true}, 2)
+	if r := results[0]; r.Status != StatusFailed || len(r.Leaks) != 1 || r.Leaks[0].Line != 3 {
+		t.Errorf("PII repo = %+v; want a failure with its leak", r)
+	}
+	if r := results[1]; r.Status != statusReady || len(r.Warnings) != 1 || len(r.Leaks) != 0 {
+		t.Errorf("entropy repo = %+v; want ready with a warning", r)
+	}
+	if w := results[1].Warnings; len(w) == 1 && strings.Contains(w[0].Content, w[0].Secret) {
+		t.Errorf("warning content %q is not masked", w[0].Content)
+	}
+
+	var buf bytes.Buffer
+	printBatchReport(&buf, results)
+	for _, want := range []string{"main.go:3", "not blocking"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("report lacks %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestBatchIndex(t *testing.T) {
+	results := []RepoResult{{Status: statusReady}, {Status: StatusClean}, {Status: statusReady}}
+	tests := []struct {
+		input string
+		want  int
+		ok    bool
+	}{
+		{"e 1", 0, true},
+		{"x 3", 2, true},
+		{"e 2", 0, false},
+		{"e 4", 0, false},
+		{"e", 0, false},
+		{"e one", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := batchIndex(results, strings.Fields(tt.input))
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("batchIndex(%q) = %d, %v; want %d, %v", tt.input, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPrintBatchReport(t *testing.T) {
+	var buf bytes.Buffer
+	printBatchReport(&buf, []RepoResult{
+		{Repo: "/src/api", Status: StatusCommitted, Message: "feat: add pagination\n\nbody", Commit: "0123456789abcdef", Push: PushDone},
+		{Repo: "/src/web", Status: StatusFailed, Error: "security check failed"},
+		{Repo: "/src/app", Status: StatusClean, HeldBack: []string{"libs/ui"}},
+	})
+	out := buf.String()
+	for _, want := range []string{"0123456", "feat: add pagination", "pushed", "security check failed", "held back unpushed submodules: libs/ui"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "0123456789") || strings.Contains(out, "body") {
+		t.Errorf("report shows more than the short SHA and subject:\n%s", out)
+	}
+}
+
+func TestUnpushedSubmodules(t *testing.T) {
+	results := []RepoResult{
+		{Repo: "/w/app/libs/ui", Kind: git.KindSubmodule, Status: StatusCommitted, Push: PushFailed},
+		{Repo: "/w/app/libs/core", Kind: git.KindSubmodule, Status: StatusCommitted, Push: PushDone},
+		{Repo: "/w/app/libs/net", Kind: git.KindSubmodule, Status: StatusFailed},
+		{Repo: "/w/app/libs/db", Kind: git.KindSubmodule, Status: StatusCommitted, Push: PushSkipped},
+		{Repo: "/w/app/libs/log", Kind: git.KindSubmodule, Status: StatusClean},
+		{Repo: "/w/other/lib", Kind: git.KindSubmodule, Status: StatusFailed},
+	}
+	tests := []struct {
+		pushing bool
+		want    []string
+	}{
+		{true, []string{"libs/ui", "libs/net", "libs/db"}},
+		{false, []string{"libs/ui", "libs/net"}},
+	}
+	for _, tt := range tests {
+		if got := unpushedSubmodules(results, "/w/app", tt.pushing); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("unpushedSubmodules(pushing=%v) = %v; want %v", tt.pushing, got, tt.want)
+		}
+	}
+}
+
+func TestValidateReport(t *testing.T) {
+	for _, report := range []string{"", ReportTable, ReportJSON} {
+		if err := ValidateReport(report); err != nil {
+			t.Errorf("ValidateReport(%q) = %v", report, err)
+		}
+	}
+	if err := ValidateReport("csv"); err == nil {
+		t.Error("ValidateReport accepted csv")
+	}
+}
@@ -0,0 +1,162 @@
+package processor
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/nathfavour/autocommiter.go/internal/config"
+	"github.com/nathfavour/autocommiter.go/internal/git"
+)
+
+// Policies for staged binaries above the bulky threshold.
+const (
+	BulkyIgnore = "ignore" // unstage and add to .gitignore
+	BulkyLFS    = "lfs"    // track with Git LFS and restage
+	BulkyBlock  = "block"  // abort the commit
+)
+
+// BulkyPolicies returns the supported bulky file policies.
+func BulkyPolicies() []string {
+	return []string{BulkyIgnore, BulkyLFS, BulkyBlock}
+}
+
+// ValidateBulkyPolicy returns an error for unknown policy names.
+func ValidateBulkyPolicy(policy string) error {
+	for _, p := range BulkyPolicies() {
+		if p == policy {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown bulky file policy %q (supported: %s)", policy, strings.Join(BulkyPolicies(), ", "))
+}
+
+// ResolveBulkyPolicy returns the configured bulky file policy.
+func ResolveBulkyPolicy(cfg config.Config) (string, error) {
+	policy := BulkyIgnore
+	if cfg.BulkyFilePolicy != nil && *cfg.BulkyFilePolicy != "" {
+		policy = strings.ToLower(strings.TrimSpace(*cfg.BulkyFilePolicy))
+	}
+	return policy, ValidateBulkyPolicy(policy)
+}
+
+// BulkyThreshold returns the size in bytes above which a binary is bulky.
+func BulkyThreshold(cfg config.Config) int64 {
+	if cfg.BulkyThresholdMB != nil && *cfg.BulkyThresholdMB > 0 {
+		return int64(*cfg.BulkyThresholdMB) * 1024 * 1024
+	}
+	return BinaryThreshold
+}
+
+// handleBulkyFiles applies the configured policy to staged bulky binaries. It
+// returns the files that were removed from staging.
+func handleBulkyFiles(repo git.Repository, cfg config.Config, files []string, w io.Writer) ([]string, error) {
+	policy, err := ResolveBulkyPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+	lfsInstalled := repo.LFSInstalled()
+
+	color.New(color.FgYellow).Fprintf(w, "⚠️  SECURE_MODE: Detected bulky binaries staged for commit (> %dMB):\n", BulkyThreshold(cfg)/(1024*1024))
+	for _, f := range files {
+		color.New(color.FgRed).Fprintf(w, "   - %s\n", f)
+	}
+
+	switch policy {
+	case BulkyLFS:
+		if !lfsInstalled {
+			color.New(color.FgCyan).Fprintln(w, "👉 Install Git LFS (https://git-lfs.com) and run 'git lfs install', or choose another policy with 'autocommiter set-bulky-policy'")
+			return nil, fmt.Errorf("bulky file policy is lfs but git-lfs is not installed")
+		}
+		patterns := lfsPatterns(files)
+		color.New(color.FgCyan).Fprintf(w, "📦 Tracking %s with Git LFS and restaging... ", strings.Join(patterns, ", "))
+		if err := repo.LFSTrack(patterns); err != nil {
+			color.New(color.FgRed).Fprintf(w, "Failed: %v\n", err)
+			return nil, err
+		}
+		err := repo.Restage(files)
+		if err == nil {
+			err = repo.Stage([]string{".gitattributes"})
+		}
+		if err == nil {
+			err = checkLFSPointers(repo, files)
+		}
+		if err != nil {
+			color.New(color.FgRed).Fprintf(w, "Failed: %v\n", err)
+			return nil, err
+		}
+		color.New(color.FgGreen).Fprintln(w, "Done!")
+		return nil, nil
+
+	case BulkyBlock:
+		color.New(color.FgCyan).Fprintln(w, "👉 Unstage them, track them with Git LFS, or raise bulky_threshold_mb")
+		return nil, fmt.Errorf("security check failed: bulky binaries staged")
+	}
+
+	if lfsInstalled {
+		color.New(color.FgCyan).Fprintln(w, "💡 Git LFS is installed. Run 'autocommiter set-bulky-policy lfs' to version files like these instead of ignoring them.")
+	}
+	color.New(color.FgCyan).Fprint(w, "🛡️  Adding these to .gitignore and unstaging them... ")
+	if err := handleInsecureFiles(repo, files); err != nil {
+		color.New(color.FgRed).Fprintf(w, "Failed: %v\n", err)
+		return nil, err
+	}
+	color.New(color.FgGreen).Fprintln(w, "Done!")
+	return files, nil
+}
+
+// lfsPointerMaxSize bounds the size of a staged LFS pointer file; pointers
+// are around 130 bytes.
+const lfsPointerMaxSize = 1024
+
+// checkLFSPointers returns an error unless the index holds an LFS pointer for
+// every file, so that a pattern which failed to match never lets the content
+// through.
+func checkLFSPointers(repo git.Repository, files []string) error {
+	for _, f := range files {
+		if !repo.IsLFSTracked(f) {
+			return fmt.Errorf("%s is not matched by the Git LFS patterns in .gitattributes", f)
+		}
+		if size, err := repo.StagedSize(f); err != nil || size > lfsPointerMaxSize {
+			return fmt.Errorf("%s was staged without the Git LFS filter", f)
+		}
+	}
+	return nil
+}
+
+// lfsPatterns returns the "git lfs track" patterns covering files: one per
+// extension, or the path itself for files without one. Attribute patterns are
+// case-sensitive, so the extension keeps its case.
+func lfsPatterns(files []string) []string {
+	seen := make(map[string]bool)
+	var patterns []string
+	for _, f := range files {
+		pattern := filepath.ToSlash(f)
+		if ext := filepath.Ext(f); ext != "" {
+			pattern = "*" + ext
+		}
+		if !seen[pattern] {
+			seen[pattern] = true
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// bulkyCheck reports whether a file above the threshold should count as a
+// bulky binary. Files stored through LFS are fine once the index holds the
+// pointer rather than the content, i.e. they were staged after being tracked.
+func bulkyCheck(repo git.Repository, file string, threshold int64) func() bool {
+	return func() bool {
+		if !isBinary(repo.Worktree(), file) {
+			return false
+		}
+		if repo.IsLFSTracked(file) {
+			size, err := repo.StagedSize(file)
+			return err != nil || size > threshold
+		}
+		return true
+	}
+}
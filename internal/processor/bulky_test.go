@@ -0,0 +1,149 @@
+package processor
+
+import (
+	"io"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/autocommiter.go/internal/config"
+	"github.com/nathfavour/autocommiter.go/internal/git"
+)
+
+func TestHandleBulkyFiles(t *testing.T) {
+	const file = "assets/Logo.PNG"
+	tests := []struct {
+		policy string
+		lfs    bool
+		// wantErr is a substring of the expected error.
+		wantErr string
+		removed []string
+		// pointer is whether the index should hold an LFS pointer for file.
+		pointer bool
+		ignored bool
+	}{
+		{policy: BulkyLFS, lfs: true, pointer: true},
+		{policy: BulkyLFS, wantErr: "git-lfs is not installed"},
+		{policy: BulkyBlock, lfs: true, wantErr: "bulky binaries staged"},
+		{policy: BulkyIgnore, removed: []string{file}, ignored: true},
+		{policy: "archive", wantErr: "unknown bulky file policy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			repo := git.NewFakeRepository(t.TempDir(), nil)
+			repo.LFSAvailable = tt.lfs
+			write(repo, file, strings.Repeat("\x00PNG", 300*1024))
+			repo.Stage([]string{file})
+			threshold := 1
+			cfg := config.Config{BulkyFilePolicy: &tt.policy, BulkyThresholdMB: &threshold}
+
+			removed, err := handleBulkyFiles(repo, cfg, []string{file}, io.Discard)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v; want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(removed, tt.removed) {
+				t.Errorf("removed = %v; want %v", removed, tt.removed)
+			}
+
+			size, staged := repo.StagedSize(file)
+			if tt.ignored {
+				if staged == nil {
+					t.Errorf("%s is still staged", file)
+				}
+				ignore, _ := fs.ReadFile(repo.Worktree(), ".gitignore")
+				if !strings.Contains(string(ignore), file) {
+					t.Errorf(".gitignore = %q", ignore)
+				}
+				return
+			}
+			if staged != nil {
+				t.Fatalf("%s is no longer staged", file)
+			}
+			if pointer := size <= lfsPointerMaxSize; pointer != tt.pointer {
+				t.Errorf("staged %d bytes; want pointer = %v", size, tt.pointer)
+			}
+			if tt.pointer {
+				if bulkyCheck(repo, file, BulkyThreshold(cfg))() {
+					t.Error("file is still bulky after LFS tracking")
+				}
+				if _, ok := repo.Staged(".gitattributes"); !ok {
+					t.Error(".gitattributes is not staged")
+				}
+			}
+		})
+	}
+}
+
+func TestCheckLFSPointers(t *testing.T) {
+	repo := git.NewFakeRepository(t.TempDir(), nil)
+	repo.LFSAvailable = true
+	write(repo, "video.mp4", strings.Repeat("\x00", 4096))
+	repo.Stage([]string{"video.mp4"})
+
+	if err := checkLFSPointers(repo, []string{"video.mp4"}); err == nil {
+		t.Error("untracked file passed the pointer check")
+	}
+	// Tracked, but the index still holds the content staged before.
+	write(repo, ".gitattributes", "*.mp4 filter=lfs diff=lfs merge=lfs -text\n")
+	if err := checkLFSPointers(repo, []string{"video.mp4"}); err == nil {
+		t.Error("content staged before tracking passed the pointer check")
+	}
+	repo.Restage([]string{"video.mp4"})
+	if err := checkLFSPointers(repo, []string{"video.mp4"}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLFSPatterns(t *testing.T) {
+	tests := []struct {
+		files []string
+		want  []string
+	}{
+		{[]string{"a/Logo.PNG", "b/icon.png", "c/photo.PNG"}, []string{"*.PNG", "*.png"}},
+		{[]string{"bin/tool", "data/model.Bin"}, []string{"bin/tool", "*.Bin"}},
+		{[]string{"dist/app", "dist/app"}, []string{"dist/app"}},
+	}
+	for _, tt := range tests {
+		if got := lfsPatterns(tt.files); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lfsPatterns(%v) = %v; want %v", tt.files, got, tt.want)
+		}
+	}
+}
+
+func TestResolveBulkyPolicy(t *testing.T) {
+	tests := []struct {
+		policy  string
+		want    string
+		wantErr bool
+	}{
+		{"", BulkyIgnore, false},
+		{" LFS ", BulkyLFS, false},
+		{"block", BulkyBlock, false},
+		{"delete", "delete", true},
+	}
+	for _, tt := range tests {
+		cfg := config.Config{BulkyFilePolicy: &tt.policy}
+		got, err := ResolveBulkyPolicy(cfg)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ResolveBulkyPolicy(%q) = %q, %v; want %q, error %v", tt.policy, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestBulkyThreshold(t *testing.T) {
+	zero, two := 0, 2
+	if got := BulkyThreshold(config.Config{}); got != BinaryThreshold {
+		t.Errorf("default = %d; want %d", got, BinaryThreshold)
+	}
+	if got := BulkyThreshold(config.Config{BulkyThresholdMB: &zero}); got != BinaryThreshold {
+		t.Errorf("zero = %d; want the default", got)
+	}
+	if got := BulkyThreshold(config.Config{BulkyThresholdMB: &two}); got != 2*1024*1024 {
+		t.Errorf("2MB = %d", got)
+	}
+}
@@ -0,0 +1,108 @@
+package processor
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/nathfavour/autocommiter.go/internal/api"
+	"github.com/nathfavour/autocommiter.go/internal/changelog"
+	"github.com/nathfavour/autocommiter.go/internal/config"
+	"github.com/nathfavour/autocommiter.go/internal/git"
+)
+
+// ChangelogOptions selects the commits of a changelog and how it is written.
+type ChangelogOptions struct {
+	// From is the previous release tag; defaults to the latest tag before To.
+	// The whole history is used when there is none.
+	From string
+	// To defaults to HEAD.
+	To string
+	// Notes asks the LLM to rewrite the changelog as release notes.
+	Notes   bool
+	Offline bool
+	Model   string
+}
+
+// GenerateChangelog builds the changelog of the commits between two tags.
+func GenerateChangelog(repoRoot string, opts ChangelogOptions) (*changelog.Release, error) {
+	to := opts.To
+	if to == "" {
+		to = "HEAD"
+	}
+	from := opts.From
+	if from == "" {
+		// Start from the tag before To, so that a tagged To gets its own
+		// changelog rather than an empty one.
+		from, _ = git.GetLatestTag(repoRoot, to+"^")
+	}
+
+	revRange := to
+	if from != "" {
+		revRange = from + ".." + to
+	}
+	entries, err := git.GetRangeLog(repoRoot, revRange)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := make([]changelog.Commit, 0, len(entries))
+	for _, e := range entries {
+		commits = append(commits, changelog.Commit{Hash: e.Hash, Message: e.Message})
+	}
+	release := changelog.Build(from, to, commits)
+	release.Version, _ = git.GetExactTag(repoRoot, to)
+	release.Date = releaseDate(repoRoot, opts.To, release.Version)
+
+	if !opts.Notes || len(commits) == 0 {
+		return release, nil
+	}
+	if opts.Offline {
+		color.New(color.FgYellow).Fprintln(os.Stderr, "📴 Offline: printing the changelog instead of release notes")
+		return release, nil
+	}
+
+	cfg, _ := config.LoadMergedConfig(repoRoot)
+	provider, err := ResolveProvider(cfg)
+	if err == nil {
+		var notes string
+		notes, err = tryAPIReleaseNotes(provider, cfg, release, opts)
+		if err == nil {
+			release.Notes = notes
+			return release, nil
+		}
+	}
+
+	if !offlineFallback(os.Stderr, cfg, err, "the changelog") {
+		return nil, err
+	}
+	return release, nil
+}
+
+// releaseDate is the commit date of the release's last commit, or today when
+// the changelog runs up to an untagged HEAD.
+func releaseDate(repoRoot, to, version string) string {
+	if to != "" || version != "" {
+		if to == "" {
+			to = "HEAD"
+		}
+		if date, err := git.GetCommitDate(repoRoot, to); err == nil && date != "" {
+			return date
+		}
+	}
+	return time.Now().Format("2006-01-02")
+}
+
+func tryAPIReleaseNotes(provider api.Provider, cfg config.Config, release *changelog.Release, opts ChangelogOptions) (string, error) {
+	model := resolveModel(provider, cfg, opts.Model)
+
+	color.New(color.FgCyan).Fprint(os.Stderr, "🤖 Writing release notes with model: ")
+	color.New(color.FgCyan, color.Faint).Fprintln(os.Stderr, model, "("+provider.Name()+") ...")
+
+	notes, err := api.GenerateReleaseNotes(provider, release.Markdown(), model)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(notes), nil
+}
@@ -0,0 +1,75 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nathfavour/autocommiter.go/internal/config"
+	"github.com/nathfavour/autocommiter.go/internal/git"
+	"github.com/nathfavour/autocommiter.go/internal/hooks"
+	"github.com/nathfavour/autocommiter.go/internal/lint"
+)
+
+// RunPrepareCommitMsgHook fills msgFile with a generated message when git is
+// about to open the editor on an empty message. source is the commit message
+// source git passes as the hook's second argument; messages given with -m/-F,
+// merges, squashes and amends are left untouched.
+func RunPrepareCommitMsgHook(repoPath, msgFile, source string) error {
+	if source != "" && source != "template" {
+		return nil
+	}
+
+	data, err := os.ReadFile(msgFile)
+	if err != nil {
+		return fmt.Errorf("failed to read commit message file: %w", err)
+	}
+
+	if hooks.HasContent(string(data), commentChar(repoPath)) {
+		return nil
+	}
+
+	repoRoot, err := git.GetRepoRoot(repoPath)
+	if err != nil {
+		return err
+	}
+	cfg, _ := config.LoadMergedConfig(repoRoot)
+	fileChanges, err := StagedFileChanges(OpenRepository(repoRoot), cfg, nil, os.Stderr)
+	if err != nil {
+		return err
+	}
+	if len(fileChanges) == 0 {
+		return nil
+	}
+
+	message, err := GenerateMessageForChanges(repoRoot, cfg, fileChanges, MessageOptions{})
+	if err != nil {
+		return err
+	}
+
+	// Keep git's status comments below the generated message.
+	content := strings.TrimSpace(message) + "\n" + string(data)
+	return os.WriteFile(msgFile, []byte(content), 0644)
+}
+
+// RunCommitMsgHook lints the message git is about to commit. An empty
+// message is left for git to reject.
+func RunCommitMsgHook(repoPath, msgFile string) (LintResult, error) {
+	data, err := os.ReadFile(msgFile)
+	if err != nil {
+		return LintResult{}, fmt.Errorf("failed to read commit message file: %w", err)
+	}
+	repoRoot, err := git.GetRepoRoot(repoPath)
+	if err != nil {
+		return LintResult{}, err
+	}
+	return LintMessage(repoRoot, lint.Clean(string(data), commentChar(repoPath))), nil
+}
+
+// commentChar returns the character git starts commit message comments with.
+func commentChar(repoPath string) string {
+	if c, err := git.RunGitCommand(repoPath, "config", "--get", "core.commentChar"); err == nil && c != "" && c != "auto" {
+		return c
+	}
+	return "#"
+}
@@ -0,0 +1,110 @@
+package processor
+
+import (
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/nathfavour/autocommiter.go/internal/config"
+	"github.com/nathfavour/autocommiter.go/internal/git"
+	"github.com/nathfavour/autocommiter.go/internal/lint"
+	"github.com/nathfavour/autocommiter.go/internal/style"
+)
+
+// LintConfig returns the commit message rules of the repository. In a
+// repository whose learned style is "subsystem: message" the subsystem reads
+// as a Conventional Commits type, so the type whitelist only applies when
+// the types are configured explicitly.
+func LintConfig(repoRoot string, cfg config.Config) lint.Config {
+	var rules lint.Config
+	if cfg.CommitLint != nil {
+		rules = *cfg.CommitLint
+	}
+	if len(rules.Types) == 0 && !rules.Conventional && (cfg.LearnStyle == nil || *cfg.LearnStyle) {
+		if p, err := LoadStyleProfile(repoRoot, cfg, false); err == nil && p.Samples >= style.MinSamples && p.Convention == style.Subsystem {
+			rules.DisabledRules = append(rules.DisabledRules, lint.RuleType)
+		}
+	}
+	return rules.WithDefaults()
+}
+
+// enforceLint holds a generated message to the repository's lint rules.
+// Depending on on_violation the model gets one more attempt with the
+// violations as guidance, and what is left is fixed in place. Violations
+// that remain are printed to w; the author can still edit the message.
+func enforceLint(w io.Writer, message string, rules lint.Config, regenerate func(hint string) (string, error)) string {
+	violations := lint.Lint(message, rules)
+	if len(violations) == 0 {
+		return message
+	}
+
+	if rules.OnViolation == lint.OnViolationReprompt && regenerate != nil {
+		// Required trailers such as Signed-off-by carry details the model
+		// does not know; they are left to the author.
+		var problems []string
+		for _, v := range violations {
+			if v.Rule != lint.RuleTrailers {
+				problems = append(problems, v.String())
+			}
+		}
+		if len(problems) > 0 {
+			color.New(color.FgYellow).Fprintf(w, "🔁 Message breaks %d lint rule(s); asking the model to fix it\n", len(problems))
+			hint := "the previous attempt broke these commit message rules, follow them: " + strings.Join(problems, "; ")
+			if retry, err := regenerate(hint); err == nil {
+				message = retry
+			}
+		}
+	}
+	if rules.OnViolation != lint.OnViolationWarn {
+		message = lint.Fix(message, rules)
+	}
+
+	for _, v := range lint.Lint(message, rules) {
+		color.New(color.FgYellow).Fprintf(w, "⚠️ lint: %s\n", v)
+	}
+	return message
+}
+
+// LintResult is the outcome of linting one commit message.
+type LintResult struct {
+	Commit     string           `json:"commit,omitempty"`
+	Subject    string           `json:"subject"`
+	Violations []lint.Violation `json:"violations"`
+}
+
+// LintMessage checks message against the repository's rules.
+func LintMessage(repoRoot, message string) LintResult {
+	cfg, _ := config.LoadMergedConfig(repoRoot)
+	return lintResult("", message, LintConfig(repoRoot, cfg))
+}
+
+// LintCommits checks the messages of the commits in revRange, newest first.
+func LintCommits(repoRoot, revRange string) ([]LintResult, error) {
+	entries, err := git.GetRangeLog(repoRoot, revRange)
+	if err != nil {
+		return nil, err
+	}
+	cfg, _ := config.LoadMergedConfig(repoRoot)
+	rules := LintConfig(repoRoot, cfg)
+
+	results := make([]LintResult, 0, len(entries))
+	for _, e := range entries {
+		results = append(results, lintResult(e.Hash, e.Message, rules))
+	}
+	return results, nil
+}
+
+// FixMessage applies the repository's mechanical lint fixes to message.
+func FixMessage(repoRoot, message string) string {
+	cfg, _ := config.LoadMergedConfig(repoRoot)
+	return lint.Fix(message, LintConfig(repoRoot, cfg))
+}
+
+func lintResult(hash, message string, rules lint.Config) LintResult {
+	subject, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	violations := lint.Lint(message, rules)
+	if violations == nil {
+		violations = []lint.Violation{}
+	}
+	return LintResult{Commit: hash, Subject: subject, Violations: violations}
+}
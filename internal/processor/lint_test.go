@@ -0,0 +1,42 @@
+package processor
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/autocommiter.go/internal/lint"
+)
+
+func TestEnforceLint(t *testing.T) {
+	rules := lint.Config{Trailers: []string{"Signed-off-by"}}.WithDefaults()
+
+	var hints []string
+	regenerate := func(hint string) (string, error) {
+		hints = append(hints, hint)
+		return "fix: clamp page size.", nil
+	}
+	got := enforceLint(io.Discard, "feature: clamp page size", rules, regenerate)
+	if got != "fix: clamp page size" {
+		t.Errorf("enforceLint = %q; want the retried message with the period fixed", got)
+	}
+	if len(hints) != 1 || !strings.Contains(hints[0], "[type]") || strings.Contains(hints[0], "trailer") {
+		t.Errorf("hints = %q; want one hint naming the type rule but not the trailer", hints)
+	}
+
+	// A message that only lacks a trailer is not sent back to the model.
+	hints = nil
+	enforceLint(io.Discard, "fix: clamp page size", rules, regenerate)
+	if len(hints) != 0 {
+		t.Errorf("re-prompted for a missing trailer: %q", hints)
+	}
+
+	rules.OnViolation = lint.OnViolationWarn
+	if got := enforceLint(io.Discard, "fix: y.", rules, regenerate); got != "fix: y." {
+		t.Errorf("warn mode changed the message to %q", got)
+	}
+	rules.OnViolation = lint.OnViolationFix
+	if got := enforceLint(io.Discard, "fix: y.", rules, regenerate); got != "fix: y" || len(hints) != 0 {
+		t.Errorf("fix mode = %q, hints %q", got, hints)
+	}
+}
@@ -0,0 +1,231 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/nathfavour/autocommiter.go/internal/api"
+	"github.com/nathfavour/autocommiter.go/internal/config"
+	"github.com/nathfavour/autocommiter.go/internal/conventional"
+	"github.com/nathfavour/autocommiter.go/internal/git"
+	"github.com/nathfavour/autocommiter.go/internal/offline"
+	"github.com/nathfavour/autocommiter.go/internal/summarizer"
+)
+
+// PullRequest is a generated pull request title and description.
+type PullRequest struct {
+	Title    string     `json:"title"`
+	Summary  string     `json:"summary"`
+	Testing  stringList `json:"testing"`
+	Breaking stringList `json:"breaking_changes"`
+	Base     string     `json:"base"`
+	Head     string     `json:"head"`
+	Commits  []string   `json:"commits"`
+}
+
+// PROptions configures pull request generation.
+type PROptions struct {
+	// Base is the branch the pull request targets; defaults to the remote's
+	// default branch.
+	Base    string
+	Offline bool
+	Model   string
+}
+
+// Body renders the pull request description as Markdown.
+func (pr *PullRequest) Body() string {
+	var b strings.Builder
+	b.WriteString("## Summary\n\n")
+	b.WriteString(strings.TrimSpace(pr.Summary))
+	b.WriteString("\n\n## Testing\n\n")
+	for _, t := range pr.Testing {
+		fmt.Fprintf(&b, "- %s\n", t)
+	}
+	b.WriteString("\n## Breaking Changes\n\n")
+	if len(pr.Breaking) == 0 {
+		b.WriteString("None.\n")
+	}
+	for _, c := range pr.Breaking {
+		fmt.Fprintf(&b, "- %s\n", c)
+	}
+	return b.String()
+}
+
+// GeneratePullRequest describes the commits between the merge base with
+// opts.Base and HEAD, falling back to a description built from the commit
+// messages when the provider is unavailable.
+func GeneratePullRequest(repoRoot string, opts PROptions) (*PullRequest, error) {
+	cfg, _ := config.LoadMergedConfig(repoRoot)
+
+	base := opts.Base
+	if base == "" {
+		var err error
+		if base, err = git.GetDefaultBranch(repoRoot); err != nil {
+			return nil, err
+		}
+	}
+	mergeBase, err := git.GetMergeBase(repoRoot, base, "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("no common ancestor with %s: %w", base, err)
+	}
+	revRange := mergeBase + "..HEAD"
+
+	commits, err := git.GetRangeCommitMessages(repoRoot, revRange)
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("no commits between %s and HEAD", base)
+	}
+	fileChanges, err := RangeFileChanges(repoRoot, cfg, revRange, os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+
+	head, _ := git.GetCurrentBranch(repoRoot)
+	pr := offlinePullRequest(commits, fileChanges, changeScopes(repoRoot, cfg, fileChanges))
+	pr.Base = strings.TrimPrefix(base, "origin/")
+	pr.Head = head
+	if opts.Offline {
+		return pr, nil
+	}
+
+	provider, err := ResolveProvider(cfg)
+	if err == nil {
+		var generated *PullRequest
+		generated, err = tryAPIPullRequest(provider, cfg, pr, fileChanges, opts)
+		if err == nil {
+			pr.Title = generated.Title
+			pr.Summary = generated.Summary
+			if len(generated.Testing) > 0 {
+				pr.Testing = generated.Testing
+			}
+			// Breaking changes declared in commits are kept even if the
+			// model missed them.
+			pr.Breaking = mergeBreaking(pr.Breaking, generated.Breaking)
+			return pr, nil
+		}
+	}
+
+	if !offlineFallback(os.Stderr, cfg, err, "a description built from the commit messages") {
+		return nil, err
+	}
+	return pr, nil
+}
+
+func tryAPIPullRequest(provider api.Provider, cfg config.Config, pr *PullRequest, fileChanges []summarizer.FileChange, opts PROptions) (*PullRequest, error) {
+	model := resolveModel(provider, cfg, opts.Model)
+	fileChanges = ExcludeFileChanges(cfg, fileChanges, os.Stderr)
+
+	color.New(color.FgCyan).Fprint(os.Stderr, "🤖 Describing pull request with model: ")
+	color.New(color.FgCyan, color.Faint).Fprintln(os.Stderr, model, "("+provider.Name()+") ...")
+
+	var fileNames []string
+	for i, fc := range fileChanges {
+		if i >= 100 {
+			break
+		}
+		fileNames = append(fileNames, fc.File)
+	}
+	reply, err := api.GeneratePullRequest(provider, pr.Head, pr.Base,
+		strings.Join(pr.Commits, "\n---\n"), strings.Join(fileNames, "\n"),
+		summarizer.CompressToJSON(fileChanges, 12000), model)
+	if err != nil {
+		return nil, err
+	}
+	return parsePullRequest(reply)
+}
+
+// parsePullRequest extracts the JSON object from a model reply, tolerating
+// code fences and surrounding prose.
+func parsePullRequest(reply string) (*PullRequest, error) {
+	start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("model reply is not a JSON object")
+	}
+	var pr PullRequest
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &pr); err != nil {
+		return nil, fmt.Errorf("invalid pull request JSON from model: %w", err)
+	}
+	pr.Title = strings.TrimSpace(pr.Title)
+	if pr.Title == "" {
+		return nil, fmt.Errorf("model reply has no title")
+	}
+	return &pr, nil
+}
+
+// offlinePullRequest builds a description from the commit messages alone.
+// scopes are the monorepo scopes of fileChanges.
+func offlinePullRequest(commits []string, fileChanges []summarizer.FileChange, scopes []string) *PullRequest {
+	pr := &PullRequest{Commits: commits}
+
+	var subjects []string
+	for _, c := range commits {
+		subjects = append(subjects, subject(c))
+		pr.Breaking = mergeBreaking(pr.Breaking, conventional.Parse(c).BreakingChanges())
+	}
+	if len(commits) == 1 {
+		pr.Title = subjects[0]
+		pr.Summary = strings.TrimSpace(strings.TrimPrefix(commits[0], subjects[0]))
+	} else {
+		pr.Title = subject(offline.GenerateMessage(fileChanges, scopes))
+	}
+	if pr.Summary == "" {
+		pr.Summary = fmt.Sprintf("This pull request contains %d commits:\n\n- %s", len(commits), strings.Join(subjects, "\n- "))
+	}
+
+	var tests []string
+	for _, fc := range fileChanges {
+		if offline.Categorize(fc.File) == "test" {
+			tests = append(tests, "`"+fc.File+"`")
+		}
+	}
+	if len(tests) > 0 {
+		pr.Testing = stringList{"Run the updated tests: " + strings.Join(tests, ", ")}
+	} else {
+		pr.Testing = stringList{"Describe how these changes were tested."}
+	}
+	return pr
+}
+
+// mergeBreaking appends the entries of extra not already in base.
+func mergeBreaking(base, extra []string) stringList {
+	seen := make(map[string]bool)
+	var merged stringList
+	for _, c := range append(append([]string{}, base...), extra...) {
+		key := strings.ToLower(strings.TrimSpace(c))
+		if key != "" && !seen[key] {
+			seen[key] = true
+			merged = append(merged, strings.TrimSpace(c))
+		}
+	}
+	return merged
+}
+
+func subject(message string) string {
+	first, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	return strings.TrimSpace(first)
+}
+
+// stringList decodes from either a JSON array of strings or a single string,
+// since models do not always follow the requested shape.
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		if strings.TrimSpace(one) != "" {
+			*l = stringList{one}
+		}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*l = many
+	return nil
+}
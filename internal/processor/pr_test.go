@@ -0,0 +1,57 @@
+package processor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/autocommiter.go/internal/summarizer"
+)
+
+func TestParsePullRequest(t *testing.T) {
+	reply := "Here you go:\n```json\n{\"title\": \" Add login \", \"summary\": \"Adds it.\", \"testing\": \"run make test\", \"breaking_changes\": []}\n```"
+	pr, err := parsePullRequest(reply)
+	if err != nil {
+		t.Fatalf("parsePullRequest() error = %v", err)
+	}
+	if pr.Title != "Add login" || pr.Summary != "Adds it." {
+		t.Errorf("parsePullRequest() = %+v", pr)
+	}
+	if len(pr.Testing) != 1 || pr.Testing[0] != "run make test" {
+		t.Errorf("Testing = %q; want a single step", pr.Testing)
+	}
+
+	for _, bad := range []string{"no json here", `{"summary": "missing title"}`, `{"title": 3}`} {
+		if _, err := parsePullRequest(bad); err == nil {
+			t.Errorf("parsePullRequest(%q) succeeded; want error", bad)
+		}
+	}
+}
+
+func TestOfflinePullRequest(t *testing.T) {
+	commits := []string{"feat(auth)!: require tokens", "test: cover auth"}
+	changes := []summarizer.FileChange{{File: "auth/auth.go", Status: "M"}, {File: "auth/auth_test.go", Status: "A"}}
+
+	pr := offlinePullRequest(commits, changes, nil)
+	if pr.Title == "" {
+		t.Error("offline title is empty")
+	}
+	if !reflect.DeepEqual([]string(pr.Breaking), []string{"require tokens"}) {
+		t.Errorf("Breaking = %q", pr.Breaking)
+	}
+
+	body := pr.Body()
+	for _, want := range []string{"## Summary", "- feat(auth)!: require tokens", "## Testing", "`auth/auth_test.go`", "## Breaking Changes\n\n- require tokens"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Body() missing %q:\n%s", want, body)
+		}
+	}
+
+	single := offlinePullRequest([]string{"fix: handle nil config\n\nLoading an empty file crashed."}, nil, nil)
+	if single.Title != "fix: handle nil config" || single.Summary != "Loading an empty file crashed." {
+		t.Errorf("single-commit PR = %+v", single)
+	}
+	if !strings.Contains(single.Body(), "## Breaking Changes\n\nNone.") {
+		t.Errorf("Body() should state there are no breaking changes:\n%s", single.Body())
+	}
+}
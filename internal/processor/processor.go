@@ -1,8 +1,9 @@
 package processor
 
 import (
-	"bufio"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -14,18 +15,69 @@ import (
 	"github.com/nathfavour/autocommiter.go/internal/git"
 	"github.com/nathfavour/autocommiter.go/internal/gitmoji"
 	"github.com/nathfavour/autocommiter.go/internal/index"
+	"github.com/nathfavour/autocommiter.go/internal/offline"
 	"github.com/nathfavour/autocommiter.go/internal/summarizer"
+	"github.com/nathfavour/autocommiter.go/internal/ticket"
+	"github.com/nathfavour/autocommiter.go/internal/workspace"
 	"time"
 )
 
-func GenerateCommit(repoPath string, noPush bool, noSecure bool, force bool) error {
+// CommitOptions holds the per-run flags of the autocommit workflow.
+type CommitOptions struct {
+	NoPush   bool
+	NoSecure bool
+	Force    bool
+	Offline  bool
+	// Message, when set, is committed verbatim instead of generating one.
+	Message string
+	// Stage overrides the configured staging policy when nothing is staged.
+	Stage string
+	// Jobs limits how many repositories of a batch are prepared at once;
+	// DefaultJobs when zero.
+	Jobs int
+	// Report is ReportTable (default) or ReportJSON, the format of the batch
+	// summary.
+	Report string
+	// Filter narrows the discovered repositories by workspace tag and path.
+	Filter workspace.Filter
+	// Submodules overrides the configured submodule policy.
+	Submodules string
+}
+
+// MessageOptions tunes how a commit message is generated.
+type MessageOptions struct {
+	// Offline skips the LLM and uses the local heuristic generator.
+	Offline bool
+	// Model overrides the configured model for this generation.
+	Model string
+	// Hint is extra guidance from the author added to the prompt.
+	Hint string
+	// Log receives progress and warnings; os.Stderr when nil.
+	Log io.Writer
+}
+
+func (o MessageOptions) log() io.Writer {
+	if o.Log == nil {
+		return os.Stderr
+	}
+	return o.Log
+}
+
+func GenerateCommit(repoPath string, opts CommitOptions) error {
 	startDir := repoPath
 	if startDir == "" {
 		startDir = "."
 	}
 
 	color.Cyan("🪄 Autocommiter: Discovering repositories...")
-	repos := git.DiscoverRepositories(startDir)
+	repos, err := workspace.Discover(startDir, opts.Filter)
+	if err != nil {
+		return err
+	}
+	repos, err = orderRepositories(repos, opts.Submodules)
+	if err != nil {
+		return err
+	}
 
 	if len(repos) == 0 {
 		return fmt.Errorf("no git repositories found in %s", startDir)
@@ -35,10 +87,12 @@ func GenerateCommit(repoPath string, noPush bool, noSecure bool, force bool) err
 		color.Green("✓ Found %d repositories\n", len(repos))
 	}
 
-	for _, repo := range repos {
-		if err := ProcessSingleRepo(repo, noPush, noSecure, force); err != nil {
-			color.Red("✗ Error processing %s: %v\n", repo, err)
-		}
+	if len(repos) > 1 || opts.Report == ReportJSON {
+		return RunBatch(repos, opts)
+	}
+
+	if err := ProcessSingleRepo(OpenRepository(repos[0]), opts); err != nil {
+		color.Red("✗ Error processing %s: %v\n", repos[0], err)
 	}
 
 	color.New(color.FgGreen, color.Bold).Println("✨ All done!")
@@ -55,7 +109,10 @@ func SetupUser(repoPath string, user string) error {
 		startDir = "."
 	}
 
-	repos := git.DiscoverRepositories(startDir)
+	repos, err := workspace.Discover(startDir, workspace.Filter{})
+	if err != nil {
+		return err
+	}
 	if len(repos) == 0 {
 		return fmt.Errorf("no git repositories found in %s", startDir)
 	}
@@ -105,7 +162,10 @@ func AnalyzeRepo(repoPath string, applyChanges bool) error {
 		startDir = "."
 	}
 
-	repos := git.DiscoverRepositories(startDir)
+	repos, err := workspace.Discover(startDir, workspace.Filter{})
+	if err != nil {
+		return err
+	}
 	if len(repos) == 0 {
 		return fmt.Errorf("no git repositories found in %s", startDir)
 	}
@@ -114,35 +174,21 @@ func AnalyzeRepo(repoPath string, applyChanges bool) error {
 		repoRoot, _ = filepath.Abs(repoRoot)
 		color.New(color.FgCyan, color.Bold).Printf("\n🔍 Analysis for: %s\n", repoRoot)
 
-		// 1. Current State
-		curName, curEmail := git.GetLocalIdentity(repoRoot)
-		curGH := auth.GetGithubUser()
-
-		fmt.Printf("Current Setup:\n")
-		fmt.Printf("  - Git Name:  %s\n", color.YellowString(curName))
-		fmt.Printf("  - Git Email: %s\n", color.YellowString(curEmail))
-		fmt.Printf("  - GH Account: %s\n", color.YellowString(curGH))
-
-		// 2. Discovery
-		accMgr := NewAccountManager(repoRoot)
-		accMgr.StartDiscovery()
-		if err := accMgr.Wait(); err != nil {
-			return fmt.Errorf("discovery failed: %v", err)
+		analysis, accMgr, err := analyzeAccounts(repoRoot)
+		if err != nil {
+			return err
 		}
 
-		// To get name/email we might need to sync if they aren't in cache
-		// but we want to avoid switching GH account yet.
-		// Let's just report the handle first.
-		suggestedAcc := accMgr.TargetAccount
+		fmt.Printf("Current Setup:\n")
+		fmt.Printf("  - Git Name:  %s\n", color.YellowString(analysis.GitName))
+		fmt.Printf("  - Git Email: %s\n", color.YellowString(analysis.GitEmail))
+		fmt.Printf("  - GH Account: %s\n", color.YellowString(analysis.ActiveAccount))
 
 		fmt.Printf("\nSuggested Setup:\n")
-		fmt.Printf("  - GH Account: %s\n", color.GreenString(suggestedAcc))
-
-		// Check if changes are needed
-		needsSwitch := curGH != suggestedAcc
+		fmt.Printf("  - GH Account: %s\n", color.GreenString(analysis.SuggestedAccount))
 
 		// If they aren't matched, we should probably check what the identity would be
-		if needsSwitch {
+		if !analysis.Consistent {
 			color.Yellow("\n⚠️  Configuration mismatch detected.")
 			if applyChanges {
 				color.Cyan("🚀 Applying suggested changes...")
@@ -150,7 +196,7 @@ func AnalyzeRepo(repoPath string, applyChanges bool) error {
 					return fmt.Errorf("sync failed: %v", err)
 				}
 				newName, newEmail := git.GetLocalIdentity(repoRoot)
-				color.Green("✓ Successfully switched to %s", suggestedAcc)
+				color.Green("✓ Successfully switched to %s", analysis.SuggestedAccount)
 				color.Green("✓ Updated Git Identity to: %s <%s>", newName, newEmail)
 			} else {
 				color.Cyan("💡 Use 'analyze --apply' to automatically fix this.")
@@ -163,57 +209,60 @@ func AnalyzeRepo(repoPath string, applyChanges bool) error {
 	return nil
 }
 
-func ProcessSingleRepo(repoRoot string, noPush bool, noSecure bool, force bool) error {
-	color.Cyan("📂 Repository: %s", color.New(color.Bold).Sprint(repoRoot))
+// AccountAnalysis compares a repository's current identity with the account
+// autocommiter would pick for it.
+type AccountAnalysis struct {
+	RepoRoot         string `json:"repo_root"`
+	GitName          string `json:"git_name"`
+	GitEmail         string `json:"git_email"`
+	ActiveAccount    string `json:"active_account"`
+	SuggestedAccount string `json:"suggested_account"`
+	Consistent       bool   `json:"consistent"`
+}
 
-	// 1. Ensure gitignore safety (fast check)
-	color.Cyan("🛡️ Ensure .gitignore safety...")
-	if err := EnsureGitignoreSafety(repoRoot); err != nil {
-		return err
-	}
+// AnalyzeAccounts runs account discovery for repoRoot without changing anything.
+func AnalyzeAccounts(repoRoot string) (AccountAnalysis, error) {
+	analysis, _, err := analyzeAccounts(repoRoot)
+	return analysis, err
+}
 
-	// 2. Check for staged files
-	stagedFiles, err := git.GetStagedFiles(repoRoot)
-	if err != nil {
-		return err
+func analyzeAccounts(repoRoot string) (AccountAnalysis, *AccountManager, error) {
+	// 1. Current State
+	curName, curEmail := git.GetLocalIdentity(repoRoot)
+	analysis := AccountAnalysis{
+		RepoRoot:      repoRoot,
+		GitName:       curName,
+		GitEmail:      curEmail,
+		ActiveAccount: auth.GetGithubUser(),
 	}
 
-	if len(stagedFiles) == 0 {
-		color.Cyan("📦 No changes staged. Staging all changes...")
-		if err := git.StageAllChanges(repoRoot); err != nil {
-			return err
-		}
-		stagedFiles, err = git.GetStagedFiles(repoRoot)
-		if err != nil {
-			return err
-		}
-	} else {
-		color.Green("✓ Using %d already staged files", len(stagedFiles))
+	// 2. Discovery
+	accMgr := NewAccountManager(git.NewExecRepository(repoRoot))
+	accMgr.StartDiscovery()
+	if err := accMgr.Wait(); err != nil {
+		return analysis, nil, fmt.Errorf("discovery failed: %v", err)
 	}
 
-	// 3. SECURE_MODE: Check for sensitive/bulky files
-	cfg, _ := config.LoadMergedConfig(repoRoot)
-	isSecureEnabled := true
-	if cfg.SecureMode != nil {
-		isSecureEnabled = *cfg.SecureMode
-	}
+	// To get name/email we might need to sync if they aren't in cache
+	// but we want to avoid switching GH account yet.
+	// Let's just report the handle first.
+	analysis.SuggestedAccount = accMgr.TargetAccount
+	analysis.Consistent = analysis.ActiveAccount == analysis.SuggestedAccount
 
-	if isSecureEnabled && !noSecure {
-		color.Cyan("🔒 SECURE_MODE: Scanning staged files for security leaks...")
-		insecureFiles, err := RunSecurityCheck(repoRoot)
-		if err != nil {
-			return err
-		}
-		if len(insecureFiles) > 0 {
-			color.Green("✓ Security check completed. Insecure files removed from staging.")
-			// Re-fetch staged files after security check
-			stagedFiles, err = git.GetStagedFiles(repoRoot)
-			if err != nil {
-				return err
-			}
-		}
-	} else if noSecure {
-		color.Yellow("⚠️  SECURE_MODE: Skipped via --no-secure flag.")
+	return analysis, accMgr, nil
+}
+
+// ProcessSingleRepo stages, checks, commits and pushes the changes of repo.
+// Splitting by scope rewrites the index directly and always runs git in
+// repo.Root().
+func ProcessSingleRepo(repo git.Repository, opts CommitOptions) error {
+	repoRoot := repo.Root()
+	color.Cyan("📂 Repository: %s", color.New(color.Bold).Sprint(repoRoot))
+
+	cfg, _ := config.LoadMergedConfig(repoRoot)
+	stagedFiles, _, err := prepareRepo(repo, cfg, opts, !opts.Force, color.Output)
+	if err != nil {
+		return err
 	}
 
 	if len(stagedFiles) == 0 {
@@ -221,24 +270,38 @@ func ProcessSingleRepo(repoRoot string, noPush bool, noSecure bool, force bool)
 		return nil
 	}
 
+	if opts.Message == "" && shouldSplitByScope(repoRoot, cfg, stagedFiles, opts.Force) {
+		// The security check already ran on these files.
+		splitOpts := opts
+		splitOpts.NoSecure = true
+		return SplitCommit(repoRoot, splitOpts)
+	}
+
 	// 3. Generate message (Standard generation)
-	message, err := GenerateMessage(repoRoot, nil) // passing nil to skip proactive discovery
-	if err != nil {
-		return err
+	message := opts.Message
+	if message == "" {
+		message, err = generateMessage(repo, nil, MessageOptions{Offline: opts.Offline}) // passing nil to skip proactive discovery
+		if err != nil {
+			return err
+		}
 	}
 	color.Cyan("💬 Message: %s", color.New(color.Italic).Sprint(message))
 
-	// 4. Confirmation (if not forced)
+	// 4. Review (if not forced)
 	skipConf := false
 	if cfg.SkipConfirmation != nil {
 		skipConf = *cfg.SkipConfirmation
 	}
 
-	if !force && !skipConf {
-		fmt.Print(color.CyanString("\n🤔 Proceed with commit? (y/n): "))
-		reader := bufio.NewReader(os.Stdin)
-		input, _ := reader.ReadString('\n')
-		if !strings.EqualFold(strings.TrimSpace(input), "y") {
+	if !opts.Force && !skipConf {
+		generate := func(msgOpts MessageOptions) (string, error) {
+			return generateMessage(repo, nil, msgOpts)
+		}
+		message, err = ReviewMessage(repoRoot, message, MessageOptions{Offline: opts.Offline}, generate)
+		if err != nil {
+			return err
+		}
+		if message == "" {
 			color.Red("❌ Cancelled.\n")
 			return nil
 		}
@@ -246,58 +309,137 @@ func ProcessSingleRepo(repoRoot string, noPush bool, noSecure bool, force bool)
 
 	// 5. Initial Commit attempt
 	color.Cyan("✍️ Committing changes...")
-	if err := git.CommitWithMessage(repoRoot, message); err != nil {
+	if err := repo.Commit(message); err != nil {
 		return err
 	}
 	color.Green("✓ Commit successful!")
 
 	// 6. Push with reactive account discovery on failure
-	if !noPush {
-		color.Cyan("🚀 Pushing to remote...")
-		if err := git.PushChanges(repoRoot); err != nil {
-			// REACTIVE DISCOVERY: Only trigger discovery if the push fails
-			color.Yellow("⚠️ Initial push failed: %v", err)
-			color.Cyan("🔍 Attempting reactive account discovery...")
-
-			accMgr := NewAccountManager(repoRoot)
-			accMgr.StartDiscovery()
-			if waitErr := accMgr.Wait(); waitErr == nil {
-				if syncErr := accMgr.Sync(); syncErr == nil {
-					color.Green("✓ Switched to discovered account: %s", accMgr.TargetAccount)
-					// Retry push with the new account logic
-					if retryErr := PushWithRetry(repoRoot, accMgr); retryErr == nil {
-						color.Green("✓ Push successful after reactive discovery!")
-						goto end
-					} else {
-						return retryErr
-					}
-				}
-			}
-			return err // Return original error if discovery didn't help
+	if !opts.NoPush {
+		if err := pushWithDiscovery(repo); err != nil {
+			return err
 		}
-		color.Green("✓ Push successful!")
 	}
 
-end:
 	// Fork Sync (Optional)
-	if cfg.EnableForkSync != nil && *cfg.EnableForkSync {
-		targetUser := auth.GetGithubUser()
-		if cfg.ForkUsername != nil && *cfg.ForkUsername != "" {
-			targetUser = *cfg.ForkUsername
+	syncForkIfEnabled(repoRoot, cfg)
+
+	color.Green("✓ Done with this repository!\n")
+	return nil
+}
+
+// prepareRepo makes sure the .gitignore is safe, stages changes by policy
+// when nothing is staged and runs the security check, reporting to w. It
+// returns the files left staged and what the security check found, which is
+// also set when leaks fail it. interactive allows prompting for files to
+// stage.
+func prepareRepo(repo git.Repository, cfg config.Config, opts CommitOptions, interactive bool, w io.Writer) ([]string, securityReport, error) {
+	var report securityReport
+	cyan := color.New(color.FgCyan)
+
+	// 1. Ensure gitignore safety (fast check)
+	cyan.Fprintln(w, "🛡️ Ensure .gitignore safety...")
+	if err := EnsureGitignoreSafety(repo); err != nil {
+		return nil, report, err
+	}
+
+	// 2. Check for staged files
+	stagedFiles, err := repo.StagedFiles()
+	if err != nil {
+		return nil, report, err
+	}
+
+	if len(stagedFiles) == 0 {
+		policy, err := ResolveStagingPolicy(cfg, opts.Stage)
+		if err != nil {
+			return nil, report, err
 		}
+		cyan.Fprintln(w, "📦 No changes staged.")
+		if err := stageByPolicy(repo, policy, interactive, w); err != nil {
+			return nil, report, err
+		}
+		stagedFiles, err = repo.StagedFiles()
+		if err != nil {
+			return nil, report, err
+		}
+	} else {
+		color.New(color.FgGreen).Fprintf(w, "✓ Using %d already staged files\n", len(stagedFiles))
+	}
 
-		if targetUser != "" {
-			color.Cyan("🔄 Syncing fork for %s...", targetUser)
-			if err := SyncFork(repoRoot, targetUser); err != nil {
-				color.Yellow("⚠️ Fork sync failed: %v", err)
-			} else {
-				color.Green("✓ Fork synced successfully!")
+	// 3. SECURE_MODE: Check for sensitive/bulky files
+	isSecureEnabled := true
+	if cfg.SecureMode != nil {
+		isSecureEnabled = *cfg.SecureMode
+	}
+
+	if isSecureEnabled && !opts.NoSecure {
+		cyan.Fprintln(w, "🔒 SECURE_MODE: Scanning staged files for security leaks...")
+		report, err = securityCheck(repo, w)
+		if err != nil {
+			return nil, report, err
+		}
+		if len(report.removed) > 0 {
+			color.New(color.FgGreen).Fprintln(w, "✓ Security check completed. Insecure files removed from staging.")
+			// Re-fetch staged files after security check
+			stagedFiles, err = repo.StagedFiles()
+			if err != nil {
+				return nil, report, err
 			}
 		}
+	} else if opts.NoSecure {
+		color.New(color.FgYellow).Fprintln(w, "⚠️  SECURE_MODE: Skipped via --no-secure flag.")
 	}
+	return stagedFiles, report, nil
+}
 
-	color.Green("✓ Done with this repository!\n")
-	return nil
+func syncForkIfEnabled(repoRoot string, cfg config.Config) {
+	if cfg.EnableForkSync == nil || !*cfg.EnableForkSync {
+		return
+	}
+
+	targetUser := auth.GetGithubUser()
+	if cfg.ForkUsername != nil && *cfg.ForkUsername != "" {
+		targetUser = *cfg.ForkUsername
+	}
+
+	if targetUser != "" {
+		color.Cyan("🔄 Syncing fork for %s...", targetUser)
+		if err := SyncFork(repoRoot, targetUser); err != nil {
+			color.Yellow("⚠️ Fork sync failed: %v", err)
+		} else {
+			color.Green("✓ Fork synced successfully!")
+		}
+	}
+}
+
+// pushWithDiscovery pushes the current branch, retrying with a discovered
+// account when the first attempt fails.
+func pushWithDiscovery(repo git.Repository) error {
+	color.Cyan("🚀 Pushing to remote...")
+	err := repo.Push()
+	if err == nil {
+		color.Green("✓ Push successful!")
+		return nil
+	}
+
+	// REACTIVE DISCOVERY: Only trigger discovery if the push fails
+	color.Yellow("⚠️ Initial push failed: %v", err)
+	color.Cyan("🔍 Attempting reactive account discovery...")
+
+	accMgr := NewAccountManager(repo)
+	accMgr.StartDiscovery()
+	if waitErr := accMgr.Wait(); waitErr == nil {
+		if syncErr := accMgr.Sync(); syncErr == nil {
+			color.Green("✓ Switched to discovered account: %s", accMgr.TargetAccount)
+			// Retry push with the new account logic
+			if retryErr := PushWithRetry(repo, accMgr); retryErr != nil {
+				return retryErr
+			}
+			color.Green("✓ Push successful after reactive discovery!")
+			return nil
+		}
+	}
+	return err // Return original error if discovery didn't help
 }
 
 func SyncFork(repoRoot string, targetUser string) error {
@@ -309,8 +451,9 @@ func SyncFork(repoRoot string, targetUser string) error {
 	return git.SyncFork(repoRoot, target)
 }
 
-func PushWithRetry(repoRoot string, accMgr *AccountManager) error {
-	err := git.PushChanges(repoRoot)
+func PushWithRetry(repo git.Repository, accMgr *AccountManager) error {
+	repoRoot := repo.Root()
+	err := repo.Push()
 	if err == nil {
 		return nil
 	}
@@ -346,13 +489,12 @@ func PushWithRetry(repoRoot string, accMgr *AccountManager) error {
 			}
 
 			if name, email, login, identErr := auth.GetAccountIdentity(preferNoReply); identErr == nil {
-				_ = git.SyncLocalConfig(repoRoot, name, email)
+				_ = repo.SetLocalIdentity(name, email)
 
 				// Re-amend to fix authorship if we switched accounts
-				authorStr := fmt.Sprintf("%s <%s>", name, email)
-				_, _ = git.RunGitCommand(repoRoot, "commit", "--amend", "--no-edit", "--author", authorStr)
+				_ = repo.AmendAuthor(name, email)
 
-				if retryErr := git.PushChanges(repoRoot); retryErr == nil {
+				if retryErr := repo.Push(); retryErr == nil {
 					// Success! Cache this for next time
 					accMgr.CacheAccount(login, email, name)
 
@@ -379,7 +521,10 @@ func SyncRepoFork(repoPath string, targetUser string) error {
 		startDir = "."
 	}
 
-	repos := git.DiscoverRepositories(startDir)
+	repos, err := workspace.Discover(startDir, workspace.Filter{})
+	if err != nil {
+		return err
+	}
 	if len(repos) == 0 {
 		return fmt.Errorf("no git repositories found in %s", startDir)
 	}
@@ -412,13 +557,13 @@ func SyncRepoFork(repoPath string, targetUser string) error {
 	return nil
 }
 
-func GenerateMessage(repoRoot string, accMgr *AccountManager) (string, error) {
-	cfg, _ := config.LoadMergedConfig(repoRoot)
+func GenerateMessage(repoRoot string, accMgr *AccountManager, opts MessageOptions) (string, error) {
+	return generateMessage(OpenRepository(repoRoot), accMgr, opts)
+}
 
-	apiKey := ""
-	if cfg.APIKey != nil {
-		apiKey = *cfg.APIKey
-	}
+func generateMessage(repo git.Repository, accMgr *AccountManager, opts MessageOptions) (string, error) {
+	repoRoot := repo.Root()
+	cfg, _ := config.LoadMergedConfig(repoRoot)
 
 	// Wait for account discovery to finish (give it a bit of time but don't hang forever)
 	if accMgr != nil {
@@ -439,28 +584,131 @@ func GenerateMessage(repoRoot string, accMgr *AccountManager) (string, error) {
 		}
 	}
 
-	token := auth.GetToken(apiKey)
-	if token == "" {
-		return "", fmt.Errorf("authentication failed: please run 'gh auth login' or use 'autocommiter set-api-key'")
+	fileChanges, err := StagedFileChanges(repo, cfg, nil, opts.log())
+	if err != nil {
+		return "", err
 	}
 
-	return TryAPIGeneration(repoRoot, token, cfg)
+	return GenerateMessageForChanges(repoRoot, cfg, fileChanges, opts)
 }
 
-func TryAPIGeneration(repoRoot string, apiKey string, cfg config.Config) (string, error) {
-	model := "gpt-4o-mini"
-	if cfg.SelectedModel != nil {
-		model = *cfg.SelectedModel
+// GenerateMessageForChanges generates a message describing fileChanges, falling
+// back to the offline generator when the provider is unavailable.
+func GenerateMessageForChanges(repoRoot string, cfg config.Config, fileChanges []summarizer.FileChange, opts MessageOptions) (string, error) {
+	rules := LintConfig(repoRoot, cfg)
+	w := opts.log()
+	if opts.Offline {
+		return enforceLint(w, offlineMessage(repoRoot, cfg, fileChanges, w), rules, nil), nil
 	}
 
-	color.New(color.FgCyan).Fprint(os.Stderr, "🤖 Generating with model: ")
-	color.New(color.FgCyan, color.Faint).Fprintln(os.Stderr, model, "...")
+	provider, err := ResolveProvider(cfg)
+	if err == nil {
+		var message string
+		message, err = TryAPIGeneration(repoRoot, provider, cfg, fileChanges, opts)
+		if err == nil {
+			return enforceLint(w, message, rules, func(hint string) (string, error) {
+				retry := opts
+				retry.Hint = strings.TrimSpace(opts.Hint + "\n" + hint)
+				return TryAPIGeneration(repoRoot, provider, cfg, fileChanges, retry)
+			}), nil
+		}
+	}
 
-	branch, _ := git.GetCurrentBranch(repoRoot)
-	fileChanges, err := summarizer.BuildFileChanges(repoRoot)
-	if err != nil {
+	if !offlineFallback(w, cfg, err, "offline message generation") {
 		return "", err
 	}
+	return enforceLint(w, offlineMessage(repoRoot, cfg, fileChanges, w), rules, nil), nil
+}
+
+// offlineFallback reports whether offline_fallback allows replacing a failed
+// AI generation with the local result described by what, and says so on w
+// when it does.
+func offlineFallback(w io.Writer, cfg config.Config, err error, what string) bool {
+	if cfg.OfflineFallback != nil && !*cfg.OfflineFallback {
+		return false
+	}
+	color.New(color.FgYellow).Fprintf(w, "⚠️ AI generation unavailable (%v)\n", err)
+	color.New(color.FgYellow).Fprintln(w, "📴 Falling back to "+what)
+	return true
+}
+
+// ResolveProvider builds the LLM provider selected by cfg, resolving its credentials.
+func ResolveProvider(cfg config.Config) (api.Provider, error) {
+	name := api.ProviderGitHub
+	if cfg.Provider != nil && *cfg.Provider != "" {
+		name = strings.ToLower(*cfg.Provider)
+	}
+
+	baseURL := ""
+	if cfg.ProviderBaseURL != nil {
+		baseURL = *cfg.ProviderBaseURL
+	}
+
+	key := ""
+	if cfg.ProviderAPIKey != nil {
+		key = *cfg.ProviderAPIKey
+	}
+
+	switch name {
+	case api.ProviderGitHub:
+		if key == "" && cfg.APIKey != nil {
+			key = *cfg.APIKey
+		}
+		// The gh token only ever goes to GitHub Models.
+		custom := strings.TrimSuffix(strings.TrimSpace(baseURL), "/")
+		if custom != "" && custom != api.GitHubModelsURL {
+			if key == "" {
+				return nil, fmt.Errorf("authentication failed: set 'provider_api_key' for the custom provider_base_url %s", baseURL)
+			}
+		} else {
+			key = auth.GetToken(key)
+		}
+		if key == "" {
+			return nil, fmt.Errorf("authentication failed: please run 'gh auth login' or use 'autocommiter set-api-key'")
+		}
+	case api.ProviderOpenAI:
+		if key == "" {
+			key = os.Getenv("OPENAI_API_KEY")
+		}
+	case api.ProviderAnthropic:
+		if key == "" {
+			key = os.Getenv("ANTHROPIC_API_KEY")
+		}
+		if key == "" {
+			return nil, fmt.Errorf("authentication failed: set 'provider_api_key' or ANTHROPIC_API_KEY for the anthropic provider")
+		}
+	}
+
+	return api.NewProvider(name, baseURL, key)
+}
+
+// resolveModel returns override if set, otherwise the selected model when
+// provider serves it, otherwise the provider's default model.
+func resolveModel(provider api.Provider, cfg config.Config, override string) string {
+	if override != "" {
+		return override
+	}
+	selected := ""
+	if cfg.SelectedModel != nil {
+		selected = *cfg.SelectedModel
+	}
+	return api.ResolveModel(provider, selected)
+}
+
+func TryAPIGeneration(repoRoot string, provider api.Provider, cfg config.Config, fileChanges []summarizer.FileChange, opts MessageOptions) (string, error) {
+	model := resolveModel(provider, cfg, opts.Model)
+
+	w := opts.log()
+	fileChanges = ExcludeFileChanges(cfg, fileChanges, w)
+	if len(fileChanges) == 0 {
+		return "", fmt.Errorf("all staged files are excluded from AI generation")
+	}
+
+	color.New(color.FgCyan).Fprint(w, "🤖 Generating with model: ")
+	color.New(color.FgCyan, color.Faint).Fprintln(w, model, "("+provider.Name()+") ...")
+
+	branch, _ := git.GetCurrentBranch(repoRoot)
+	ref, ticketCfg := ticketRef(branch, cfg, w)
 
 	var fileNamesList []string
 	for i, fc := range fileChanges {
@@ -474,28 +722,55 @@ func TryAPIGeneration(repoRoot string, apiKey string, cfg config.Config) (string
 	// Increased limit from 400 to 12000 to give the LLM much more context
 	compressedJSON := summarizer.CompressToJSON(fileChanges, 12000)
 
-	message, err := api.GenerateCommitMessage(apiKey, branch, fileNames, compressedJSON, model)
+	promptCtx := api.PromptContext{
+		Branch: branch,
+		Style:  stylePromptRules(repoRoot, cfg),
+		Hint:   opts.Hint,
+		Scopes: changeScopes(repoRoot, cfg, fileChanges),
+	}
+	if ref != "" {
+		promptCtx.Ticket = ticket.Instruction(ref, ticketCfg)
+	}
+
+	message, err := api.GenerateCommitMessage(provider, promptCtx, fileNames, compressedJSON, model)
 	if err != nil {
 		return "", err
 	}
 
+	// The model may drop the reference; make sure it is there.
+	return ticket.Apply(applyGitmoji(message, cfg), ref, ticketCfg), nil
+}
+
+// offlineMessage generates a message locally, with the ticket reference of
+// the branch.
+func offlineMessage(repoRoot string, cfg config.Config, fileChanges []summarizer.FileChange, w io.Writer) string {
+	branch, _ := git.GetCurrentBranch(repoRoot)
+	ref, ticketCfg := ticketRef(branch, cfg, w)
+	message := offline.GenerateMessage(fileChanges, changeScopes(repoRoot, cfg, fileChanges))
+	return ticket.Apply(applyGitmoji(message, cfg), ref, ticketCfg)
+}
+
+func applyGitmoji(message string, cfg config.Config) string {
 	enableGitmoji := false
 	if cfg.EnableGitmoji != nil {
 		enableGitmoji = *cfg.EnableGitmoji
 	}
 
 	if enableGitmoji {
-		message = gitmoji.GetGitmojifiedMessage(message)
+		return gitmoji.GetGitmojifiedMessage(message)
 	}
-
-	return message, nil
+	return message
 }
 
 func GetSummarizedChanges(repoRoot string) (string, error) {
-	fileChanges, err := summarizer.BuildFileChanges(repoRoot)
+	// The summary is typically handed to an agent's model, so it gets the
+	// same treatment as our own prompts.
+	cfg, _ := config.LoadMergedConfig(repoRoot)
+	fileChanges, err := StagedFileChanges(OpenRepository(repoRoot), cfg, nil, os.Stderr)
 	if err != nil {
 		return "", err
 	}
+	fileChanges = ExcludeFileChanges(cfg, fileChanges, os.Stderr)
 	// Default to a large maxLen because the extension can handle it
 	return summarizer.CompressToJSON(fileChanges, 12000), nil
 }
@@ -548,8 +823,10 @@ func FixLastCommit(repoRoot string, targetUser string) error {
 	return nil
 }
 
-func EnsureGitignoreSafety(repoRoot string) error {
-	cfg, _ := config.LoadMergedConfig(repoRoot)
+// EnsureGitignoreSafety appends the configured gitignore patterns missing
+// from the .gitignore of repo, when update_gitignore is enabled.
+func EnsureGitignoreSafety(repo git.Repository) error {
+	cfg, _ := config.LoadMergedConfig(repo.Root())
 	shouldUpdate := false
 	if cfg.UpdateGitignore != nil {
 		shouldUpdate = *cfg.UpdateGitignore
@@ -559,8 +836,7 @@ func EnsureGitignoreSafety(repoRoot string) error {
 		return nil
 	}
 
-	gitignorePath := filepath.Join(repoRoot, ".gitignore")
-	existing, _ := os.ReadFile(gitignorePath)
+	existing, _ := fs.ReadFile(repo.Worktree(), ".gitignore")
 	content := string(existing)
 
 	lines := strings.Split(content, "\n")
@@ -586,7 +862,7 @@ func EnsureGitignoreSafety(repoRoot string) error {
 			newContent += "\n"
 		}
 		newContent += strings.Join(toAppend, "\n") + "\n"
-		return os.WriteFile(gitignorePath, []byte(newContent), 0644)
+		return repo.WriteFile(".gitignore", []byte(newContent))
 	}
 
 	return nil
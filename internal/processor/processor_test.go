@@ -0,0 +1,250 @@
+package processor
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/autocommiter.go/internal/git"
+	"github.com/nathfavour/autocommiter.go/internal/index"
+)
+
+func TestProcessSingleRepo(t *testing.T) {
+	token := "ghp_" + strings.Repeat("aB3dE5", 6)
+	tests := []struct {
+		name   string
+		config string
+		opts   CommitOptions
+		// edit changes the working tree of a repository holding main.go
+		// and README.md.
+		edit func(r *git.FakeRepository)
+		// pushErr makes every push fail.
+		pushErr error
+		wantErr string
+		// commits is how many commits the run adds.
+		commits int
+		// committed and left are the files the new commit changes and the
+		// files left out of it.
+		committed []string
+		left      []string
+		pushes    int
+	}{
+		{
+			name: "staged changes",
+			edit: func(r *git.FakeRepository) {
+				write(r, "main.go", "package main\n\nfunc main() {}\n")
+				r.Stage([]string{"main.go"})
+			},
+			commits:   1,
+			committed: []string{"main.go"},
+			pushes:    1,
+		},
+		{
+			name: "tracked policy leaves untracked files",
+			edit: func(r *git.FakeRepository) {
+				write(r, "README.md", "# Demo\n\nUsage.\n")
+				write(r, "notes.txt", "todo\n")
+			},
+			commits:   1,
+			committed: []string{"README.md"},
+			left:      []string{"notes.txt"},
+			pushes:    1,
+		},
+		{
+			name: "all policy with a deletion",
+			opts: CommitOptions{Stage: StageAll},
+			edit: func(r *git.FakeRepository) {
+				r.RemoveFile("README.md")
+				write(r, "notes.txt", "todo\n")
+			},
+			commits:   1,
+			committed: []string{"README.md", "notes.txt"},
+			pushes:    1,
+		},
+		{
+			name:   "abort policy",
+			opts:   CommitOptions{Stage: StageAbort},
+			edit:   func(r *git.FakeRepository) { write(r, "README.md", "# Demo\n\nUsage.\n") },
+			left:   []string{"README.md"},
+			pushes: 0,
+		},
+		{
+			name: "nothing to commit",
+			edit: func(r *git.FakeRepository) {},
+		},
+		{
+			name: "sensitive file is unstaged and ignored",
+			opts: CommitOptions{Stage: StageAll},
+			edit: func(r *git.FakeRepository) {
+				write(r, "main.go", "package main\n\nfunc main() {}\n")
+				write(r, ".env", "TOKEN=1\n")
+			},
+			commits:   1,
+			committed: []string{"main.go"},
+			left:      []string{".env", ".gitignore"},
+			pushes:    1,
+		},
+		{
+			name:    "leak blocks the commit",
+			edit:    func(r *git.FakeRepository) { write(r, "main.go", "package main\n\nvar token = \""+token+"\"\n") },
+			wantErr: "PII/Leaks detected",
+			left:    []string{"main.go"},
+		},
+		{
+			name:      "no-secure commits the leak",
+			opts:      CommitOptions{NoSecure: true, NoPush: true},
+			edit:      func(r *git.FakeRepository) { write(r, "main.go", "package main\n\nvar token = \""+token+"\"\n") },
+			commits:   1,
+			committed: []string{"main.go"},
+		},
+		{
+			name:      "gitignore safety",
+			config:    `{"update_gitignore": true, "gitignore_patterns": ["*.log"]}`,
+			opts:      CommitOptions{Stage: StageAll, NoPush: true},
+			edit:      func(r *git.FakeRepository) {},
+			commits:   1,
+			committed: []string{".gitignore"},
+		},
+		{
+			name:      "push failure",
+			edit:      func(r *git.FakeRepository) { write(r, "README.md", "# Demo\n\nUsage.\n") },
+			pushErr:   errors.New("remote rejected"),
+			wantErr:   "remote rejected",
+			commits:   1,
+			committed: []string{"README.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			// Keep push failures from looking up gh accounts.
+			if err := index.SetSingleAccountSentinel(true); err != nil {
+				t.Fatal(err)
+			}
+			root := t.TempDir()
+			if tt.config != "" {
+				if err := os.WriteFile(filepath.Join(root, ".autocommiter.json"), []byte(tt.config), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			repo := git.NewFakeRepository(root, map[string]string{
+				"main.go":   "package main\n",
+				"README.md": "# Demo\n",
+			})
+			repo.PushErr = tt.pushErr
+			tt.edit(repo)
+
+			opts := tt.opts
+			opts.Force, opts.Offline = true, true
+			err := ProcessSingleRepo(repo, opts)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("ProcessSingleRepo: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("ProcessSingleRepo = %v; want %q", err, tt.wantErr)
+			}
+
+			if got := len(repo.Commits) - 1; got != tt.commits {
+				t.Fatalf("made %d commits; want %d", got, tt.commits)
+			}
+			if tt.commits > 0 {
+				last := repo.Commits[len(repo.Commits)-1]
+				if strings.TrimSpace(last.Message) == "" {
+					t.Error("committed an empty message")
+				}
+				for _, f := range tt.committed {
+					if last.Files[f] == repo.Commits[0].Files[f] {
+						t.Errorf("%s is not part of the commit", f)
+					}
+				}
+			}
+			staged, _ := repo.StagedFiles()
+			untracked, _ := repo.UntrackedFiles()
+			unstaged, _ := repo.UnstagedFiles()
+			if len(staged) > 0 && tt.wantErr == "" {
+				t.Errorf("files left staged: %v", staged)
+			}
+			pending := strings.Join(append(append(staged, untracked...), unstaged...), " ")
+			for _, f := range tt.left {
+				if !strings.Contains(pending, f) {
+					t.Errorf("%s is not pending; pending: %s", f, pending)
+				}
+			}
+			if repo.Pushes != tt.pushes {
+				t.Errorf("pushed %d times; want %d", repo.Pushes, tt.pushes)
+			}
+		})
+	}
+}
+
+func TestProcessSingleRepoMessage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	repo := git.NewFakeRepository(t.TempDir(), map[string]string{"main.go": "package main\n"})
+	write(repo, "main.go", "package main\n\nfunc main() {}\n")
+
+	err := ProcessSingleRepo(repo, CommitOptions{Force: true, NoPush: true, Message: "feat: add main"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := repo.Commits[len(repo.Commits)-1].Message; got != "feat: add main" {
+		t.Errorf("message = %q; want the given one", got)
+	}
+}
+
+func TestSensitiveFileIsIgnored(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	repo := git.NewFakeRepository(t.TempDir(), nil)
+	write(repo, ".gitignore", "bin/")
+	write(repo, "id_rsa", "key\n")
+	repo.Stage([]string{"id_rsa"})
+
+	removed, err := RunSecurityCheck(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0] != "id_rsa" {
+		t.Errorf("RunSecurityCheck removed %v; want [id_rsa]", removed)
+	}
+	if _, staged := repo.Staged("id_rsa"); staged {
+		t.Error("id_rsa is still staged")
+	}
+	ignore, _ := fs.ReadFile(repo.Worktree(), ".gitignore")
+	if !strings.HasPrefix(string(ignore), "bin/\n") || !strings.HasSuffix(string(ignore), "\nid_rsa\n") {
+		t.Errorf(".gitignore = %q", ignore)
+	}
+}
+
+func write(r *git.FakeRepository, name, content string) {
+	_ = r.WriteFile(name, []byte(content))
+}
+
+func TestRunSecurityCheckSeverity(t *testing.T) {
+	token := "ghp_" + strings.Repeat("aB3dE5", 6)
+	tests := []struct {
+		name    string
+		line    string
+		wantErr bool
+	}{
+		{"secret blocks", `const token = "` + token + `"`, true},
+		{"pii blocks", `const owner = "jane.doe@corp-mail.io"`, true},
+		{"entropy warns", `const salt = "q8Zt3LmR9vXw2KpN7yHc4BfJ"`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			repo := git.NewFakeRepository(t.TempDir(), nil)
+			write(repo, "main.go", "package main\n\n"+tt.line+"\n")
+			repo.Stage([]string{"main.go"})
+
+			_, err := RunSecurityCheck(repo)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RunSecurityCheck() error = %v; want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/autocommiter.go/internal/api"
+	"github.com/nathfavour/autocommiter.go/internal/config"
+)
+
+func TestResolveProviderKeepsGhTokenOnGitHub(t *testing.T) {
+	const ghToken = "gho_usertoken"
+	const evil = "https://collector.example.com"
+	t.Setenv("GH_TOKEN", ghToken)
+	t.Setenv("GITHUB_TOKEN", "")
+
+	tests := []struct {
+		name string
+		// global and repo are the global and .autocommiter.json configs.
+		global, repo string
+		wantURL      string
+		wantKey      string
+		wantErr      bool
+	}{
+		{name: "default endpoint", wantURL: api.GitHubModelsURL, wantKey: ghToken},
+		{
+			name:    "repo base url ignored",
+			repo:    `{"provider_base_url": "` + evil + `"}`,
+			wantURL: api.GitHubModelsURL, wantKey: ghToken,
+		},
+		{
+			name:    "repo cannot switch provider",
+			repo:    `{"provider": "openai", "provider_base_url": "` + evil + `"}`,
+			wantURL: api.GitHubModelsURL, wantKey: ghToken,
+		},
+		{
+			name:    "global base url without key",
+			global:  `{"provider_base_url": "` + evil + `"}`,
+			wantErr: true,
+		},
+		{
+			name:    "global base url with own key",
+			global:  `{"provider_base_url": "` + evil + `", "provider_api_key": "sk-own"}`,
+			wantURL: evil, wantKey: "sk-own",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			if tt.global != "" {
+				dir := filepath.Join(home, ".autocommiter")
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(tt.global), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			repoRoot := t.TempDir()
+			if tt.repo != "" {
+				if err := os.WriteFile(filepath.Join(repoRoot, ".autocommiter.json"), []byte(tt.repo), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cfg, _ := config.LoadMergedConfig(repoRoot)
+			p, err := ResolveProvider(cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ResolveProvider succeeded; want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			op, ok := p.(*api.OpenAIProvider)
+			if !ok {
+				t.Fatalf("provider = %T (%s); want the github provider", p, p.Name())
+			}
+			if op.BaseURL != tt.wantURL || op.APIKey != tt.wantKey {
+				t.Errorf("provider = %s with key %q; want %s with key %q", op.BaseURL, op.APIKey, tt.wantURL, tt.wantKey)
+			}
+			if op.BaseURL != api.GitHubModelsURL && strings.Contains(op.APIKey, ghToken) {
+				t.Errorf("gh token sent to %s", op.BaseURL)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package processor
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"github.com/fatih/color"
+	"github.com/nathfavour/autocommiter.go/internal/config"
+	"github.com/nathfavour/autocommiter.go/internal/git"
+	"github.com/nathfavour/autocommiter.go/internal/glob"
+	"github.com/nathfavour/autocommiter.go/internal/secrets"
+	"github.com/nathfavour/autocommiter.go/internal/summarizer"
+)
+
+// StagedFileChanges summarizes the staged changes of files, or of every staged
+// file when files is nil. Unless redact_diffs is off, secrets, emails and
+// high-entropy strings are replaced with placeholders in the full diffs,
+// before they are truncated. This runs regardless of SECURE_MODE and
+// --no-secure. How many values were masked is reported to w.
+func StagedFileChanges(repo git.Repository, cfg config.Config, files []string, w io.Writer) ([]summarizer.FileChange, error) {
+	redactor, err := newPromptRedactor(repo.Root(), cfg)
+	if err != nil {
+		return nil, err
+	}
+	var fileChanges []summarizer.FileChange
+	if files == nil {
+		fileChanges, err = summarizer.BuildFileChanges(repo, redactor.redact())
+	} else {
+		fileChanges, err = summarizer.BuildFileChangesForFiles(repo, files, redactor.redact())
+	}
+	redactor.report(w)
+	return fileChanges, err
+}
+
+// RangeFileChanges is StagedFileChanges for the files changed in a revision
+// range.
+func RangeFileChanges(repoRoot string, cfg config.Config, revRange string, w io.Writer) ([]summarizer.FileChange, error) {
+	redactor, err := newPromptRedactor(repoRoot, cfg)
+	if err != nil {
+		return nil, err
+	}
+	fileChanges, err := summarizer.BuildRangeFileChanges(repoRoot, revRange, redactor.redact())
+	redactor.report(w)
+	return fileChanges, err
+}
+
+// ExcludeFileChanges drops the files matching llm_exclude_paths from file
+// changes bound for an LLM, reporting how many to w.
+func ExcludeFileChanges(cfg config.Config, fileChanges []summarizer.FileChange, w io.Writer) []summarizer.FileChange {
+	var result []summarizer.FileChange
+	excluded := 0
+	for _, fc := range fileChanges {
+		if glob.MatchAny(cfg.LLMExcludePaths, fc.File) {
+			excluded++
+			continue
+		}
+		result = append(result, fc)
+	}
+
+	if excluded > 0 {
+		color.New(color.FgCyan).Fprintf(w, "🚫 Withheld %d files from the AI prompt (llm_exclude_paths)\n", excluded)
+	}
+	return result
+}
+
+// promptRedactor counts the values it masks across the concurrent diff
+// summaries of one build. A nil promptRedactor redacts nothing.
+type promptRedactor struct {
+	redactor *secrets.Redactor
+	masked   atomic.Int64
+}
+
+func newPromptRedactor(repoRoot string, cfg config.Config) (*promptRedactor, error) {
+	if cfg.RedactDiffs != nil && !*cfg.RedactDiffs {
+		return nil, nil
+	}
+	detector, err := LoadSecretDetector(repoRoot, cfg)
+	if err != nil {
+		// Never fall back to sending unredacted diffs.
+		return nil, fmt.Errorf("cannot redact diffs: %w", err)
+	}
+	return &promptRedactor{redactor: secrets.NewRedactor(detector)}, nil
+}
+
+func (p *promptRedactor) redact() summarizer.Redact {
+	if p == nil {
+		return nil
+	}
+	return func(file, diff string) string {
+		redacted, n := p.redactor.Redact(file, diff)
+		p.masked.Add(int64(n))
+		return redacted
+	}
+}
+
+func (p *promptRedactor) report(w io.Writer) {
+	if p == nil {
+		return
+	}
+	if masked := p.masked.Load(); masked > 0 {
+		color.New(color.FgCyan).Fprintf(w, "🙈 Redacted %d sensitive values from the AI prompt\n", masked)
+	}
+}
@@ -0,0 +1,46 @@
+package processor
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/autocommiter.go/internal/config"
+	"github.com/nathfavour/autocommiter.go/internal/git"
+)
+
+func TestStagedFileChangesRedactsBeforeTruncation(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	token := "ghp_" + strings.Repeat("aB3dE5", 6)
+	tail := strings.Repeat("// filler line to make the diff large\n", 60)
+	content := func(pad int) string {
+		return "package main\n\n" + strings.Repeat(" ", pad) + `const token = "` + token + "\"\n" + tail
+	}
+
+	repo := git.NewFakeRepository(t.TempDir(), nil)
+	write(repo, "main.go", content(0))
+	repo.Stage([]string{"main.go"})
+
+	// Move the token so that the 1000-byte cut falls in its middle.
+	diff, _ := repo.StagedDiff("main.go")
+	write(repo, "main.go", content(1000-len(token)/2-strings.Index(diff, token)))
+	repo.Stage([]string{"main.go"})
+	diff, _ = repo.StagedDiff("main.go")
+	if i := strings.Index(diff, token); i >= 1000 || i+len(token) <= 1000 {
+		t.Fatalf("token at %d does not span the cut", i)
+	}
+
+	changes, err := StagedFileChanges(repo, config.Config{}, nil, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("got %d changes; want 1", len(changes))
+	}
+	if !strings.Contains(changes[0].Change, "(truncated)") {
+		t.Fatalf("diff was not truncated:\n%s", changes[0].Change)
+	}
+	if strings.Contains(changes[0].Change, "ghp_") {
+		t.Errorf("partial token leaked into the summary:\n%s", changes[0].Change)
+	}
+}
@@ -0,0 +1,222 @@
+package processor
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/nathfavour/autocommiter.go/internal/api"
+	"github.com/nathfavour/autocommiter.go/internal/config"
+	"github.com/nathfavour/autocommiter.go/internal/git"
+	"github.com/nathfavour/autocommiter.go/internal/models"
+)
+
+const (
+	defaultCandidates = 3
+	maxCandidates     = 10
+)
+
+// ReviewMessage lets the user accept, edit, regenerate or replace message
+// before committing. generate produces a new message for the given options.
+// It returns an empty message when the commit is cancelled.
+func ReviewMessage(repoRoot, message string, opts MessageOptions, generate func(MessageOptions) (string, error)) (string, error) {
+	reader := bufio.NewReader(os.Stdin)
+	ask := func(prompt string) string {
+		fmt.Print(color.CyanString(prompt))
+		input, _ := reader.ReadString('\n')
+		return strings.TrimSpace(input)
+	}
+
+	regenerate := func() {
+		next, err := generate(opts)
+		if err != nil {
+			color.Red("✗ Regeneration failed: %v", err)
+			return
+		}
+		message = next
+	}
+
+	for {
+		input := strings.ToLower(ask("\n🤔 [y] commit, [e] edit, [r] regenerate, [m] other model, [c] candidates, [h] hint, [n] cancel: "))
+		switch input {
+		case "y", "yes":
+			return message, nil
+
+		case "", "n", "no":
+			return "", nil
+
+		case "e":
+			edited, err := editMessage(repoRoot, message)
+			if err != nil {
+				color.Red("✗ %v", err)
+				continue
+			}
+			if edited == "" {
+				color.Yellow("⚠️ Empty message, keeping the previous one")
+				continue
+			}
+			message = edited
+
+		case "r":
+			if opts.Offline {
+				color.Yellow("⚠️ Offline messages are deterministic; use [m] or [h] to generate with AI")
+				continue
+			}
+			regenerate()
+
+		case "m":
+			model := pickModel(repoRoot, ask)
+			if model == "" {
+				continue
+			}
+			opts.Model = model
+			opts.Offline = false
+			regenerate()
+
+		case "h":
+			hint := ask("💡 Hint (e.g. mention the migration): ")
+			if hint == "" {
+				continue
+			}
+			opts.Hint = hint
+			opts.Offline = false
+			regenerate()
+
+		case "c":
+			if opts.Offline {
+				color.Yellow("⚠️ Offline messages are deterministic; use [m] or [h] to generate with AI")
+				continue
+			}
+			n := defaultCandidates
+			if s := ask(fmt.Sprintf("🔢 How many candidates? [%d]: ", n)); s != "" {
+				v, err := strconv.Atoi(s)
+				if err != nil || v < 1 || v > maxCandidates {
+					color.Red("✗ Enter a number between 1 and %d", maxCandidates)
+					continue
+				}
+				n = v
+			}
+			candidates := generateCandidates(n, opts, generate)
+			if len(candidates) == 0 {
+				continue
+			}
+			fmt.Println()
+			for i, c := range candidates {
+				fmt.Printf("%3d. %s\n", i+1, strings.ReplaceAll(c, "\n", "\n     "))
+			}
+			choice := ask(fmt.Sprintf("\n🤔 Pick one (1-%d), [Enter] keep current: ", len(candidates)))
+			if choice == "" {
+				continue
+			}
+			i, err := strconv.Atoi(choice)
+			if err != nil || i < 1 || i > len(candidates) {
+				color.Red("✗ Invalid choice: %s", choice)
+				continue
+			}
+			message = candidates[i-1]
+
+		default:
+			color.Red("✗ Unknown choice: %s", input)
+			continue
+		}
+
+		color.Cyan("💬 Message: %s", color.New(color.Italic).Sprint(message))
+	}
+}
+
+// generateCandidates asks for n messages, dropping duplicates and failures.
+func generateCandidates(n int, opts MessageOptions, generate func(MessageOptions) (string, error)) []string {
+	seen := make(map[string]bool)
+	var candidates []string
+	for i := 0; i < n; i++ {
+		message, err := generate(opts)
+		if err != nil {
+			color.Red("✗ Generation failed: %v", err)
+			break
+		}
+		if key := strings.TrimSpace(message); !seen[key] {
+			seen[key] = true
+			candidates = append(candidates, message)
+		}
+	}
+	return candidates
+}
+
+// pickModel asks for a model, listing the cached GitHub Models catalog when
+// that provider is in use. Any other name is passed through as typed.
+func pickModel(repoRoot string, ask func(string) string) string {
+	cfg, _ := config.LoadMergedConfig(repoRoot)
+	var available []models.ModelInfo
+	if cfg.Provider == nil || *cfg.Provider == "" || strings.EqualFold(*cfg.Provider, api.ProviderGitHub) {
+		available, _ = models.ListAvailableModels()
+	}
+
+	if len(available) == 0 {
+		return ask("🤖 Model name: ")
+	}
+	fmt.Println()
+	for i, m := range available {
+		fmt.Printf("%3d. %s\n", i+1, color.CyanString(m.Name))
+	}
+	input := ask(fmt.Sprintf("\n🤖 Model (1-%d or name): ", len(available)))
+	if i, err := strconv.Atoi(input); err == nil {
+		if i < 1 || i > len(available) {
+			color.Red("✗ Invalid choice: %s", input)
+			return ""
+		}
+		return available[i-1].ID
+	}
+	return input
+}
+
+// editMessage opens message in git's configured editor and returns the
+// result with comment lines and surrounding blank lines removed.
+func editMessage(repoRoot, message string) (string, error) {
+	path, err := git.RunGitCommand(repoRoot, "rev-parse", "--git-path", "AUTOCOMMITER_EDITMSG")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(repoRoot, path)
+	}
+
+	content := message + "\n\n# Edit the commit message. Lines starting with '#' are ignored;\n# an empty message keeps the previous one.\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return "", err
+	}
+	defer os.Remove(path)
+
+	editor := git.GetEditor(repoRoot)
+	// Like git, let the shell parse the editor so it may carry arguments.
+	cmd := exec.Command("sh", "-c", editor+` "$@"`, editor, path)
+	cmd.Dir = repoRoot
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return stripComments(string(edited)), nil
+}
+
+// stripComments drops '#' comment lines and trailing whitespace.
+func stripComments(message string) string {
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t\r"))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,33 @@
+package processor
+
+import "testing"
+
+func TestStripComments(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"feat: add x\n\n# comment\n", "feat: add x"},
+		{"fix: y  \n\nbody line\t\n# a\n# b\n\n", "fix: y\n\nbody line"},
+		{"# only comments\n\n", ""},
+		{"\n\nchore: z\n", "chore: z"},
+	}
+	for _, tt := range tests {
+		if got := stripComments(tt.in); got != tt.want {
+			t.Errorf("stripComments(%q) = %q; want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateCandidatesDropsDuplicates(t *testing.T) {
+	replies := []string{"feat: a", "feat: b", "feat: a\n", "feat: c"}
+	i := 0
+	generate := func(MessageOptions) (string, error) {
+		r := replies[i]
+		i++
+		return r, nil
+	}
+	got := generateCandidates(4, MessageOptions{}, generate)
+	if len(got) != 3 || got[0] != "feat: a" || got[1] != "feat: b" || got[2] != "feat: c" {
+		t.Errorf("generateCandidates() = %q", got)
+	}
+}
@@ -0,0 +1,269 @@
+package processor
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nathfavour/autocommiter.go/internal/config"
+	"github.com/nathfavour/autocommiter.go/internal/git"
+	"github.com/nathfavour/autocommiter.go/internal/sarif"
+	"github.com/nathfavour/autocommiter.go/internal/secrets"
+)
+
+// ScanOptions selects what Scan inspects. With neither field set, the staged
+// changes are scanned.
+type ScanOptions struct {
+	// Range is a revision range such as "main..HEAD"; a single revision is
+	// scanned up to HEAD.
+	Range string
+	// All scans every tracked file in the working tree.
+	All bool
+}
+
+// FileIssue is a file flagged by name or size rather than content.
+type FileIssue struct {
+	File     string `json:"file"`
+	Reason   string `json:"reason"`
+	Severity string `json:"severity"`
+}
+
+// ScanReport is the result of Scan.
+type ScanReport struct {
+	Target string      `json:"target"`
+	Files  []FileIssue `json:"files"`
+	Leaks  []LeakMatch `json:"leaks"`
+}
+
+// Scan runs the secret, PII and sensitive/bulky file checks without changing
+// the repository. Findings recorded in the baseline are omitted, and the
+// secret in each leak's Content is masked.
+func Scan(repoRoot string, opts ScanOptions) (*ScanReport, error) {
+	cfg, _ := config.LoadMergedConfig(repoRoot)
+	detector, err := LoadSecretDetector(repoRoot, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold := BulkyThreshold(cfg)
+	var report *ScanReport
+	switch {
+	case opts.All:
+		report, err = scanTree(repoRoot, detector, threshold)
+	case opts.Range != "":
+		report, err = scanRange(repoRoot, opts.Range, detector, threshold)
+	default:
+		report, err = scanStagedReport(repoRoot, detector, threshold)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	baseline, err := secrets.LoadBaseline(filepath.Join(repoRoot, secrets.BaselineFile))
+	if err != nil {
+		return nil, err
+	}
+	leaks := []LeakMatch{}
+	for _, leak := range report.Leaks {
+		if baseline.Contains(leak.Fingerprint) {
+			continue
+		}
+		leak.Content = maskSecret(leak.Content, leak.Secret)
+		leaks = append(leaks, leak)
+	}
+	report.Leaks = leaks
+	if report.Files == nil {
+		report.Files = []FileIssue{}
+	}
+	return report, nil
+}
+
+// MaxSeverity returns the highest severity in the report, or "" if clean.
+func (r *ScanReport) MaxSeverity() string {
+	max := ""
+	check := func(s string) {
+		if s == SeverityError || (s == SeverityWarning && max == "") {
+			max = s
+		}
+	}
+	for _, f := range r.Files {
+		check(f.Severity)
+	}
+	for _, l := range r.Leaks {
+		check(l.Severity)
+	}
+	return max
+}
+
+// SARIF converts the report to a SARIF 2.1.0 log.
+func (r *ScanReport) SARIF(toolVersion string) *sarif.Log {
+	log := sarif.New("autocommiter", toolVersion, "https://github.com/nathfavour/autocommiter.go")
+	for _, f := range r.Files {
+		ruleID := "sensitive-file"
+		if f.Reason == ReasonBulky {
+			ruleID = "bulky-file"
+		}
+		log.AddResult(ruleID, f.Reason, f.Severity, fmt.Sprintf("%s should not be committed (%s)", f.File, f.Reason), f.File, 0, 0, "")
+	}
+	for _, l := range r.Leaks {
+		log.AddResult(l.RuleID, l.Type, l.Severity, fmt.Sprintf("%s detected", l.Type), l.File, l.Line, l.Column, l.Fingerprint)
+	}
+	return log
+}
+
+func fileIssue(file, reason string) FileIssue {
+	severity := SeverityError
+	if reason == ReasonBulky {
+		severity = SeverityWarning
+	}
+	return FileIssue{File: file, Reason: reason, Severity: severity}
+}
+
+func scanStagedReport(repoRoot string, detector *secrets.Detector, threshold int64) (*ScanReport, error) {
+	files, err := git.GetStagedFiles(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ScanReport{Target: "staged"}
+	repo := git.NewExecRepository(repoRoot)
+	worktree := repo.Worktree()
+	for _, file := range files {
+		info, err := fs.Stat(worktree, file)
+		if err != nil {
+			continue
+		}
+		if reason := insecureReason(file, info.Size(), threshold, bulkyCheck(repo, file, threshold)); reason != "" {
+			report.Files = append(report.Files, fileIssue(file, reason))
+			continue
+		}
+		if info.Size() < MaxCodeFileSize {
+			leaks, _ := scanFileForLeaks(repo, file, detector)
+			report.Leaks = append(report.Leaks, leaks...)
+		}
+	}
+	return report, nil
+}
+
+func scanRange(repoRoot, revRange string, detector *secrets.Detector, threshold int64) (*ScanReport, error) {
+	if !strings.Contains(revRange, "..") {
+		revRange += "..HEAD"
+	}
+	end := revRange[strings.LastIndex(revRange, "..")+2:]
+	if end == "" {
+		end = "HEAD"
+	}
+
+	files, err := git.GetRangeFiles(repoRoot, revRange)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ScanReport{Target: revRange}
+	skip := make(map[string]bool)
+	for _, file := range files {
+		size, err := git.GetBlobSize(repoRoot, end, file)
+		if err != nil {
+			continue
+		}
+		// Anything this large in history is treated as bulky; reading the
+		// blob just to sniff for NUL bytes is not worth it.
+		if reason := insecureReason(file, size, threshold, func() bool { return true }); reason != "" {
+			report.Files = append(report.Files, fileIssue(file, reason))
+			skip[file] = true
+			continue
+		}
+		for _, f := range detector.ScanPath(file) {
+			report.Leaks = append(report.Leaks, newLeak(file, 0, file, f))
+		}
+		if size >= MaxCodeFileSize {
+			skip[file] = true
+		}
+	}
+
+	diff, err := git.GetRangeDiff(repoRoot, revRange)
+	if err != nil {
+		return nil, err
+	}
+	for _, added := range git.ParseAddedLines(diff) {
+		if added.File == "" || skip[added.File] {
+			continue
+		}
+		for _, f := range detector.ScanLine(added.File, added.Content) {
+			leak := newLeak(added.File, added.Line, added.Content, f)
+			leak.Hunk = added.Hunk.Header
+			report.Leaks = append(report.Leaks, leak)
+		}
+	}
+	return report, nil
+}
+
+func scanTree(repoRoot string, detector *secrets.Detector, threshold int64) (*ScanReport, error) {
+	files, err := git.GetTrackedFiles(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ScanReport{Target: "tree"}
+	repo := git.NewExecRepository(repoRoot)
+	worktree := repo.Worktree()
+	for _, file := range files {
+		fullPath := filepath.Join(repoRoot, file)
+		info, err := os.Stat(fullPath)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if reason := insecureReason(file, info.Size(), threshold, bulkyCheck(repo, file, threshold)); reason != "" {
+			report.Files = append(report.Files, fileIssue(file, reason))
+			continue
+		}
+		for _, f := range detector.ScanPath(file) {
+			report.Leaks = append(report.Leaks, newLeak(file, 0, file, f))
+		}
+		if info.Size() >= MaxCodeFileSize || isBinary(worktree, file) {
+			continue
+		}
+
+		leaks, err := scanFileContent(file, fullPath, detector)
+		if err != nil {
+			continue
+		}
+		report.Leaks = append(report.Leaks, leaks...)
+	}
+	return report, nil
+}
+
+func scanFileContent(file, fullPath string, detector *secrets.Detector) ([]LeakMatch, error) {
+	f, err := os.Open(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var leaks []LeakMatch
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), MaxCodeFileSize)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		for _, finding := range detector.ScanLine(file, line) {
+			leaks = append(leaks, newLeak(file, n, line, finding))
+		}
+	}
+	return leaks, scanner.Err()
+}
+
+// maskSecret hides all but the first four characters of secret in content so
+// reports can be shared without re-leaking it.
+func maskSecret(content, secret string) string {
+	if secret == "" {
+		return content
+	}
+	visible := 4
+	if len(secret) <= 8 {
+		visible = 0
+	}
+	return strings.ReplaceAll(content, secret, secret[:visible]+strings.Repeat("*", len(secret)-visible))
+}
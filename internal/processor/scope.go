@@ -0,0 +1,66 @@
+package processor
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/nathfavour/autocommiter.go/internal/config"
+	"github.com/nathfavour/autocommiter.go/internal/scope"
+	"github.com/nathfavour/autocommiter.go/internal/summarizer"
+)
+
+// ScopeResolver returns the monorepo scope resolver of the repository.
+func ScopeResolver(repoRoot string, cfg config.Config) *scope.Resolver {
+	var sc scope.Config
+	if cfg.Scope != nil {
+		sc = *cfg.Scope
+	}
+	return scope.NewResolver(repoRoot, sc)
+}
+
+// changeScopes returns the monorepo scopes fileChanges touch.
+func changeScopes(repoRoot string, cfg config.Config, fileChanges []summarizer.FileChange) []string {
+	files := make([]string, 0, len(fileChanges))
+	for _, fc := range fileChanges {
+		files = append(files, fc.File)
+	}
+	return ScopeResolver(repoRoot, cfg).Scopes(files)
+}
+
+// ResolveOnMultipleScopes returns what to do with changes spanning several
+// scopes.
+func ResolveOnMultipleScopes(cfg config.Config) string {
+	if cfg.Scope != nil && cfg.Scope.OnMultiple != "" {
+		return cfg.Scope.OnMultiple
+	}
+	return scope.OnMultipleWarn
+}
+
+// shouldSplitByScope reports whether staged files spanning several scopes
+// should be committed separately. Under the warn policy it only prints the
+// scopes; under split it asks first unless force is set.
+func shouldSplitByScope(repoRoot string, cfg config.Config, stagedFiles []string, force bool) bool {
+	policy := ResolveOnMultipleScopes(cfg)
+	if policy == scope.OnMultipleIgnore {
+		return false
+	}
+	scopes := ScopeResolver(repoRoot, cfg).Scopes(stagedFiles)
+	if len(scopes) < 2 {
+		return false
+	}
+
+	color.Yellow("⚠️ Staged files span %d scopes: %s", len(scopes), strings.Join(scopes, ", "))
+	if policy != scope.OnMultipleSplit {
+		color.New(color.Faint).Println("  Run 'autocommiter split' to commit each scope separately.")
+		return false
+	}
+	if force {
+		return true
+	}
+	fmt.Print(color.CyanString("🤔 Split into one commit per scope? (y/n): "))
+	input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.EqualFold(strings.TrimSpace(input), "y")
+}
@@ -1,246 +1,461 @@
 package processor
 
 import (
-        "bytes"
-        "fmt"
-        "io"
-        "os"
-        "path/filepath"
-        "strings"
-
-        "github.com/fatih/color"
-        "github.com/nathfavour/autocommiter.go/internal/config"
-        "github.com/nathfavour/autocommiter.go/internal/git"
-        "regexp"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/nathfavour/autocommiter.go/internal/config"
+	"github.com/nathfavour/autocommiter.go/internal/git"
+	"github.com/nathfavour/autocommiter.go/internal/secrets"
 )
 
 const (
-        MaxCodeFileSize = 2 * 1024 * 1024 // 2MB
-        BinaryThreshold = 5 * 1024 * 1024 // 5MB
+	MaxCodeFileSize = 2 * 1024 * 1024 // 2MB
+	BinaryThreshold = 5 * 1024 * 1024 // 5MB, default bulky threshold
+)
+
+// Finding severities, used for reports and scan exit codes.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
 )
 
 type LeakMatch struct {
-        File    string
-        Line    int
-        Content string
-        Type    string
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column,omitempty"`
+	Content  string `json:"content"`
+	Type     string `json:"type"`
+	RuleID   string `json:"rule_id"`
+	Severity string `json:"severity"`
+	// Hunk is the "@@ ... @@" header of the diff hunk containing the line.
+	Hunk        string `json:"hunk,omitempty"`
+	Fingerprint string `json:"fingerprint"`
+	// Secret is the matched value; it is never serialized.
+	Secret string `json:"-"`
 }
 
-var (
-        emailRegex    = regexp.MustCompile(`(?i)[A-Z0-9._%+-]+@[A-Z0-9.-]+\.[A-Z]{2,}`)
-        apiKeyRegex   = regexp.MustCompile(`(?i)(?:key|token|secret|password|auth|api)[a-z0-9_]*["']?\s*[:=]\s*["']?([a-zA-Z0-9-._~+/]{32,})["']?`)
-        awsKeyRegex   = regexp.MustCompile(`(A3T[A-Z0-9]|AKIA|AGPA|AIDA|AROA|AIPA|ANPA|ANVA|ASIA)[A-Z0-9]{16}`)
-        stripeKeyRegex = regexp.MustCompile(`sk_live_[0-9a-zA-Z]{24}`)
-)
+// newLeak converts a detector finding on line of file into a LeakMatch.
+// Findings tagged "pii" or "entropy" are warnings; everything else is an error.
+// Severity grades scan reports; a commit is still blocked by PII.
+func newLeak(file string, line int, content string, f secrets.Finding) LeakMatch {
+	severity := SeverityError
+	for _, t := range f.Tags {
+		if t == "pii" || t == "entropy" {
+			severity = SeverityWarning
+		}
+	}
+	return LeakMatch{
+		File:        file,
+		Line:        line,
+		Column:      f.Column,
+		Content:     content,
+		Type:        f.Description,
+		RuleID:      f.RuleID,
+		Severity:    severity,
+		Fingerprint: secrets.Fingerprint(file, f.RuleID, f.Secret),
+		Secret:      f.Secret,
+	}
+}
+
+// LoadSecretDetector builds the secret detector from the built-in rules, the
+// configured rules file and inline rules, with entropy scoring enabled.
+func LoadSecretDetector(repoRoot string, cfg config.Config) (*secrets.Detector, error) {
+	rules := secrets.DefaultRules()
+	allowlist := secrets.DefaultAllowlist()
+
+	if cfg.SecretRulesFile != nil && *cfg.SecretRulesFile != "" {
+		path := *cfg.SecretRulesFile
+		if strings.HasPrefix(path, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, path[2:])
+			}
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(repoRoot, path)
+		}
+		f, err := secrets.LoadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load secret rules from %s: %w", path, err)
+		}
+		rules = secrets.Merge(rules, f.Rules)
+		if f.Allowlist != nil {
+			allowlist.Paths = append(allowlist.Paths, f.Allowlist.Paths...)
+			allowlist.Regexes = append(allowlist.Regexes, f.Allowlist.Regexes...)
+			allowlist.StopWords = append(allowlist.StopWords, f.Allowlist.StopWords...)
+		}
+	}
+	rules = secrets.Merge(rules, cfg.SecretRules)
+
+	detector, err := secrets.NewDetector(rules, allowlist)
+	if err != nil {
+		return nil, err
+	}
+	entropy := secrets.EntropyConfig{}
+	if cfg.SecretEntropy != nil {
+		entropy = *cfg.SecretEntropy
+	}
+	detector.SetEntropy(entropy)
+	return detector, nil
+}
 
 var sensitiveExtensions = []string{
-        ".pem",
-        ".key",
-        ".p12",
-        ".pfx",
-        ".crt",
-        ".ca-bundle",
+	".pem",
+	".key",
+	".p12",
+	".pfx",
+	".crt",
+	".ca-bundle",
 }
 
 var sensitiveFileNames = []string{
-        ".env",
-        "id_rsa",
-        "id_dsa",
-        "id_ecdsa",
-        "id_ed25519",
-        "secrets.json",
-        "credentials.json",
-}
-
-func RunSecurityCheck(repoRoot string) ([]string, error) {
-        cfg, _ := config.LoadMergedConfig(repoRoot)
-        stagedFiles, err := git.GetStagedFiles(repoRoot)
-        if err != nil {
-                return nil, err
-        }
-
-        var insecureFiles []string
-        var leaks []LeakMatch
-
-        for _, file := range stagedFiles {
-                fullPath := filepath.Join(repoRoot, file)
-                info, err := os.Stat(fullPath)
-                if err != nil {
-                        continue
-                }
-
-                // 1. Check for sensitive/bulky files
-                detectBulky := true
-                if cfg.SecureDetectBulky != nil {
-                        detectBulky = *cfg.SecureDetectBulky
-                }
-
-                if detectBulky && isInsecure(file, fullPath, info) {
-                        insecureFiles = append(insecureFiles, file)
-                        continue
-                }
-
-                // 2. Check for PII/Leaks in code diffs
-                detectPII := true
-                if cfg.SecureDetectPII != nil {
-                        detectPII = *cfg.SecureDetectPII
-                }
-
-                if detectPII && info.Size() < MaxCodeFileSize {
-                        fileLeaks, _ := scanFileForLeaks(repoRoot, file)
-                        if len(fileLeaks) > 0 {
-                                leaks = append(leaks, fileLeaks...)
-                        }
-                }
-        }
-
-        if len(leaks) > 0 {
-                color.Red("\n🚨 SECURE_MODE: Potential PII or Secrets detected in code diffs:")
-                for _, leak := range leaks {
-                        color.Yellow("   - %s:%d [%s]: %s", leak.File, leak.Line, leak.Type, color.New(color.Faint).Sprint(leak.Content))
-                }
-                color.Cyan("\n🛡️  Action Required: Please review these lines for sensitive data.")
-                color.Cyan("👉 To skip this check for this run, use --no-secure")
-                color.Cyan("👉 To disable this permanently, use 'autocommiter toggle-secure-pii'")
-                return nil, fmt.Errorf("security check failed: PII/Leaks detected")
-        }
-
-        if len(insecureFiles) > 0 {
-                color.Yellow("⚠️  SECURE_MODE: Detected potentially insecure or bulky files staged for commit:")
-                for _, f := range insecureFiles {
-                        color.Red("   - %s", f)
-                }
-
-                fmt.Print(color.CyanString("🛡️  Adding these to .gitignore and unstaging them... "))
-                if err := handleInsecureFiles(repoRoot, insecureFiles); err != nil {
-                        fmt.Println(color.RedString("Failed: %v", err))
-                        return nil, err
-                }
-                fmt.Println(color.GreenString("Done!"))
-        }
-
-        return insecureFiles, nil
-}
-
-func scanFileForLeaks(repoRoot string, file string) ([]LeakMatch, error) {
-        diff, err := git.GetStagedDiffUnified(repoRoot, file)
-        if err != nil {
-                return nil, err
-        }
-
-        var leaks []LeakMatch
-        lines := strings.Split(diff, "\n")
-        currentLine := 0
-
-        for _, line := range lines {
-                if strings.HasPrefix(line, "@@") {
-                        // Parse chunk header to get line number if needed, but for now simple
-                        continue
-                }
-                if !strings.HasPrefix(line, "+") || strings.HasPrefix(line, "+++") {
-                        continue
-                }
-
-                content := strings.TrimPrefix(line, "+")
-                currentLine++ // This is naive, but works for identifying relative position
-
-                if awsKeyRegex.MatchString(content) {
-                        leaks = append(leaks, LeakMatch{File: file, Line: currentLine, Content: content, Type: "AWS Key"})
-                } else if stripeKeyRegex.MatchString(content) {
-                        leaks = append(leaks, LeakMatch{File: file, Line: currentLine, Content: content, Type: "Stripe Key"})
-                } else if apiKeyRegex.MatchString(content) {
-                        leaks = append(leaks, LeakMatch{File: file, Line: currentLine, Content: content, Type: "Potential API Key/Secret"})
-                } else if emailRegex.MatchString(content) {
-                        // Optional: only alert if it looks like a leak (e.g. not a known contributor email)
-                        leaks = append(leaks, LeakMatch{File: file, Line: currentLine, Content: content, Type: "PII (Email)"})
-                }
-        }
-
-        return leaks, nil
-}
-
-func isInsecure(relPath string, fullPath string, info os.FileInfo) bool {
-        lowerPath := strings.ToLower(relPath)
-        ext := filepath.Ext(lowerPath)
-        base := filepath.Base(lowerPath)
-
-        // 1. Check sensitive extensions
-        for _, targetExt := range sensitiveExtensions {
-                if ext == targetExt {
-                        return true
-                }
-        }
-
-        // 2. Check sensitive exact filenames
-        for _, targetName := range sensitiveFileNames {
-                if base == targetName || strings.HasPrefix(base, targetName+".") {
-                        return true
-                }
-        }
-
-        // 3. Check for bulky files that might be binaries
-        if info.Size() > BinaryThreshold {
-                return isBinary(fullPath)
-        }
-
-        return false
-}
-
-func isBinary(path string) bool {
-        f, err := os.Open(path)
-        if err != nil {
-                return false
-        }
-        defer f.Close()
-
-        buffer := make([]byte, 512)
-        n, err := f.Read(buffer)
-        if err != nil && err != io.EOF {
-                return false
-        }
-
-        // Check for null bytes in the first 512 bytes
-        return bytes.Contains(buffer[:n], []byte{0})
-}
-
-func handleInsecureFiles(repoRoot string, files []string) error {
-        // 1. Unstage files
-        for _, f := range files {
-                _, err := git.RunGitCommand(repoRoot, "reset", "HEAD", "--", f)
-                if err != nil {
-                        return err
-                }
-        }
-
-        // 2. Add to .gitignore
-        gitignorePath := filepath.Join(repoRoot, ".gitignore")
-        existing, _ := os.ReadFile(gitignorePath)
-        content := string(existing)
-
-        var toAppend []string
-        lineMap := make(map[string]bool)
-        for _, l := range strings.Split(content, "\n") {
-                trimmed := strings.TrimSpace(l)
-                if trimmed != "" {
-                        lineMap[trimmed] = true
-                }
-        }
-
-        for _, f := range files {
-                if !lineMap[f] {
-                        toAppend = append(toAppend, f)
-                }
-        }
-
-        if len(toAppend) > 0 {
-                newContent := content
-                if !strings.HasSuffix(content, "\n") && content != "" {
-                        newContent += "\n"
-                }
-                newContent += "\n# Added by Autocommiter SECURE_MODE:\n"
-                newContent += strings.Join(toAppend, "\n") + "\n"
-                return os.WriteFile(gitignorePath, []byte(newContent), 0644)
-        }
-
-        return nil
+	".env",
+	"id_rsa",
+	"id_dsa",
+	"id_ecdsa",
+	"id_ed25519",
+	"secrets.json",
+	"credentials.json",
+}
+
+// ScanStagedChanges inspects the staged files without modifying anything. It
+// returns the sensitive or bulky files and the leaks found in the code diffs,
+// leaving out leaks acknowledged in the repository's baseline.
+func ScanStagedChanges(repo git.Repository) ([]string, []LeakMatch, error) {
+	insecureFiles, leaks, err := scanStaged(repo)
+	if err != nil || len(leaks) == 0 {
+		return insecureFiles, leaks, err
+	}
+
+	baseline, err := secrets.LoadBaseline(filepath.Join(repo.Root(), secrets.BaselineFile))
+	if err != nil {
+		return nil, nil, err
+	}
+	var fresh []LeakMatch
+	for _, leak := range leaks {
+		if !baseline.Contains(leak.Fingerprint) {
+			fresh = append(fresh, leak)
+		}
+	}
+	return insecureFiles, fresh, nil
+}
+
+// RecordBaseline adds the leaks currently found in the staged changes to the
+// repository's baseline. It returns how many were added and the baseline path.
+func RecordBaseline(repoRoot string) (int, string, error) {
+	_, leaks, err := scanStaged(OpenRepository(repoRoot))
+	if err != nil {
+		return 0, "", err
+	}
+
+	path := filepath.Join(repoRoot, secrets.BaselineFile)
+	baseline, err := secrets.LoadBaseline(path)
+	if err != nil {
+		return 0, path, err
+	}
+	added := 0
+	for _, leak := range leaks {
+		if baseline.Add(secrets.BaselineEntry{
+			Fingerprint: leak.Fingerprint,
+			File:        leak.File,
+			RuleID:      leak.RuleID,
+			Description: leak.Type,
+			Line:        leak.Line,
+		}) {
+			added++
+		}
+	}
+	if added == 0 {
+		return 0, path, nil
+	}
+	return added, path, baseline.Save(path)
+}
+
+func scanStaged(repo git.Repository) ([]string, []LeakMatch, error) {
+	repoRoot := repo.Root()
+	cfg, _ := config.LoadMergedConfig(repoRoot)
+	stagedFiles, err := repo.StagedFiles()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	detectPII := true
+	if cfg.SecureDetectPII != nil {
+		detectPII = *cfg.SecureDetectPII
+	}
+	var detector *secrets.Detector
+	if detectPII {
+		if detector, err = LoadSecretDetector(repoRoot, cfg); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	threshold := BulkyThreshold(cfg)
+	var insecureFiles []string
+	var leaks []LeakMatch
+	worktree := repo.Worktree()
+
+	for _, file := range stagedFiles {
+		info, err := fs.Stat(worktree, file)
+		if err != nil {
+			continue
+		}
+
+		// 1. Check for sensitive/bulky files
+		detectBulky := true
+		if cfg.SecureDetectBulky != nil {
+			detectBulky = *cfg.SecureDetectBulky
+		}
+
+		if detectBulky && insecureReason(file, info.Size(), threshold, bulkyCheck(repo, file, threshold)) != "" {
+			insecureFiles = append(insecureFiles, file)
+			continue
+		}
+
+		// 2. Check for PII/Leaks in code diffs
+		if detectPII && info.Size() < MaxCodeFileSize {
+			fileLeaks, _ := scanFileForLeaks(repo, file, detector)
+			if len(fileLeaks) > 0 {
+				leaks = append(leaks, fileLeaks...)
+			}
+		}
+	}
+
+	return insecureFiles, leaks, nil
+}
+
+// securityReport is what the security check of the staged changes found.
+type securityReport struct {
+	// Leaks abort the commit; warnings, high-entropy strings no rule
+	// matches, are only listed.
+	leaks, warnings []LeakMatch
+	// removed are the files taken out of staging.
+	removed []string
+}
+
+// RunSecurityCheck scans the staged changes of repo. Leaks abort the commit,
+// except high-entropy strings no rule matches, which are only listed.
+// Sensitive files are unstaged and ignored, and bulky binaries are handled by
+// the bulky file policy. It returns the files removed from staging.
+func RunSecurityCheck(repo git.Repository) ([]string, error) {
+	report, err := securityCheck(repo, color.Output)
+	return report.removed, err
+}
+
+// securityCheck is RunSecurityCheck writing to w. The report holds the
+// findings even when leaks fail the check.
+func securityCheck(repo git.Repository, w io.Writer) (securityReport, error) {
+	var report securityReport
+	insecureFiles, leaks, err := ScanStagedChanges(repo)
+	if err != nil {
+		return report, err
+	}
+	cfg, _ := config.LoadMergedConfig(repo.Root())
+
+	for _, leak := range leaks {
+		if leak.RuleID == secrets.EntropyRuleID {
+			report.warnings = append(report.warnings, leak)
+		} else {
+			report.leaks = append(report.leaks, leak)
+		}
+	}
+
+	if len(report.warnings) > 0 {
+		color.New(color.FgYellow).Fprintln(w, "\n⚠️  SECURE_MODE: Possible secrets (high-entropy strings, not blocking):")
+		printLeaks(w, report.warnings)
+	}
+
+	if len(report.leaks) > 0 {
+		cyan := color.New(color.FgCyan)
+		color.New(color.FgRed).Fprintln(w, "\n🚨 SECURE_MODE: Potential PII or Secrets detected in code diffs:")
+		printLeaks(w, report.leaks)
+		cyan.Fprintln(w, "\n🛡️  Action Required: Please review these lines for sensitive data.")
+		cyan.Fprintln(w, "👉 To acknowledge a false positive, add an 'autocommiter:allow' comment on the line")
+		cyan.Fprintln(w, "   or record the current findings with 'autocommiter security baseline'")
+		cyan.Fprintln(w, "👉 To skip this check for this run, use --no-secure")
+		cyan.Fprintln(w, "👉 To disable this permanently, use 'autocommiter toggle-secure-pii'")
+		return report, fmt.Errorf("security check failed: PII/Leaks detected")
+	}
+
+	var sensitive, bulky []string
+	for _, f := range insecureFiles {
+		if isSensitive(f) {
+			sensitive = append(sensitive, f)
+		} else {
+			bulky = append(bulky, f)
+		}
+	}
+
+	if len(sensitive) > 0 {
+		color.New(color.FgYellow).Fprintln(w, "⚠️  SECURE_MODE: Detected potentially sensitive files staged for commit:")
+		for _, f := range sensitive {
+			color.New(color.FgRed).Fprintf(w, "   - %s\n", f)
+		}
+
+		color.New(color.FgCyan).Fprint(w, "🛡️  Adding these to .gitignore and unstaging them... ")
+		if err := handleInsecureFiles(repo, sensitive); err != nil {
+			color.New(color.FgRed).Fprintf(w, "Failed: %v\n", err)
+			return report, err
+		}
+		color.New(color.FgGreen).Fprintln(w, "Done!")
+	}
+
+	report.removed = sensitive
+	if len(bulky) > 0 {
+		ignored, err := handleBulkyFiles(repo, cfg, bulky, w)
+		if err != nil {
+			return report, err
+		}
+		report.removed = append(report.removed, ignored...)
+	}
+
+	return report, nil
+}
+
+func printLeaks(w io.Writer, leaks []LeakMatch) {
+	for _, leak := range leaks {
+		if leak.Line == 0 {
+			color.New(color.FgYellow).Fprintf(w, "   - %s [%s]\n", leak.File, leak.Type)
+			continue
+		}
+		color.New(color.FgYellow).Fprintf(w, "   - %s:%d [%s]: %s\n", leak.File, leak.Line, leak.Type, color.New(color.Faint).Sprint(leak.Content))
+		if context := hunkContext(leak.Hunk); context != "" {
+			color.New(color.Faint).Fprintf(w, "     in %s\n", context)
+		}
+	}
+}
+
+func scanFileForLeaks(repo git.Repository, file string, detector *secrets.Detector) ([]LeakMatch, error) {
+	var leaks []LeakMatch
+	for _, f := range detector.ScanPath(file) {
+		leaks = append(leaks, newLeak(file, 0, file, f))
+	}
+
+	diff, err := repo.StagedDiffUnified(file)
+	if err != nil {
+		return leaks, err
+	}
+
+	for _, added := range git.ParseAddedLines(diff) {
+		for _, f := range detector.ScanLine(file, added.Content) {
+			leak := newLeak(file, added.Line, added.Content, f)
+			leak.Hunk = added.Hunk.Header
+			leaks = append(leaks, leak)
+		}
+	}
+
+	return leaks, nil
+}
+
+// hunkContext returns the function context git appends to a hunk header.
+func hunkContext(header string) string {
+	if i := strings.Index(header, " @@ "); i >= 0 {
+		return strings.TrimSpace(header[i+4:])
+	}
+	return ""
+}
+
+// Reasons a file is flagged without looking at its content.
+const (
+	ReasonSensitive = "sensitive file"
+	ReasonBulky     = "bulky binary"
+)
+
+// insecureReason classifies a file by name and size. bulky is only consulted
+// for files above threshold.
+func insecureReason(relPath string, size int64, threshold int64, bulky func() bool) string {
+	if isSensitive(relPath) {
+		return ReasonSensitive
+	}
+
+	// Check for bulky files that might be binaries
+	if size > threshold && bulky() {
+		return ReasonBulky
+	}
+
+	return ""
+}
+
+// isSensitive reports whether relPath names a key, certificate or credentials file.
+func isSensitive(relPath string) bool {
+	lowerPath := strings.ToLower(relPath)
+	ext := filepath.Ext(lowerPath)
+	base := filepath.Base(lowerPath)
+
+	// 1. Check sensitive extensions
+	for _, targetExt := range sensitiveExtensions {
+		if ext == targetExt {
+			return true
+		}
+	}
+
+	// 2. Check sensitive exact filenames
+	for _, targetName := range sensitiveFileNames {
+		if base == targetName || strings.HasPrefix(base, targetName+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isBinary(fsys fs.FS, name string) bool {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	buffer := make([]byte, 512)
+	n, err := f.Read(buffer)
+	if err != nil && err != io.EOF {
+		return false
+	}
+
+	// Check for null bytes in the first 512 bytes
+	return bytes.Contains(buffer[:n], []byte{0})
+}
+
+func handleInsecureFiles(repo git.Repository, files []string) error {
+	// 1. Unstage files
+	if err := repo.Unstage(files); err != nil {
+		return err
+	}
+
+	// 2. Add to .gitignore
+	existing, _ := fs.ReadFile(repo.Worktree(), ".gitignore")
+	content := string(existing)
+
+	var toAppend []string
+	lineMap := make(map[string]bool)
+	for _, l := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(l)
+		if trimmed != "" {
+			lineMap[trimmed] = true
+		}
+	}
+
+	for _, f := range files {
+		if !lineMap[f] {
+			toAppend = append(toAppend, f)
+		}
+	}
+
+	if len(toAppend) > 0 {
+		newContent := content
+		if !strings.HasSuffix(content, "\n") && content != "" {
+			newContent += "\n"
+		}
+		newContent += "\n# Added by Autocommiter SECURE_MODE:\n"
+		newContent += strings.Join(toAppend, "\n") + "\n"
+		return repo.WriteFile(".gitignore", []byte(newContent))
+	}
+
+	return nil
 }
@@ -0,0 +1,292 @@
+package processor
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/nathfavour/autocommiter.go/internal/config"
+	"github.com/nathfavour/autocommiter.go/internal/git"
+	"github.com/nathfavour/autocommiter.go/internal/offline"
+	"github.com/nathfavour/autocommiter.go/internal/summarizer"
+)
+
+// Changeset is a group of staged files that will be committed together.
+type Changeset struct {
+	Label   string
+	Changes []summarizer.FileChange
+	Message string
+}
+
+// Files returns the paths covered by the changeset.
+func (c *Changeset) Files() []string {
+	files := make([]string, 0, len(c.Changes))
+	for _, fc := range c.Changes {
+		files = append(files, fc.File)
+	}
+	return files
+}
+
+// SplitCommit groups the staged files of the repository into logical changesets
+// and commits each one separately, in order.
+func SplitCommit(repoPath string, opts CommitOptions) error {
+	repoRoot, err := git.GetRepoRoot(repoPath)
+	if err != nil {
+		return err
+	}
+	color.Cyan("📂 Repository: %s", color.New(color.Bold).Sprint(repoRoot))
+	repo := OpenRepository(repoRoot)
+
+	cfg, _ := config.LoadMergedConfig(repoRoot)
+	isSecureEnabled := true
+	if cfg.SecureMode != nil {
+		isSecureEnabled = *cfg.SecureMode
+	}
+	if isSecureEnabled && !opts.NoSecure {
+		color.Cyan("🔒 SECURE_MODE: Scanning staged files for security leaks...")
+		if _, err := RunSecurityCheck(repo); err != nil {
+			return err
+		}
+	}
+
+	// Renames are split into a deletion and an addition so that both sides
+	// land in the index together with whichever group owns them.
+	statuses, err := git.GetStagedNameStatus(repoRoot)
+	if err != nil {
+		return err
+	}
+	if len(statuses) == 0 {
+		color.Yellow("ℹ️ No staged changes to split. Stage files with 'git add' first.")
+		return nil
+	}
+
+	files := make([]string, 0, len(statuses))
+	for f := range statuses {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+
+	fileChanges, err := StagedFileChanges(repo, cfg, files, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	groups := GroupChanges(fileChanges, ScopeResolver(repoRoot, cfg).Scope)
+	color.Green("✓ Found %d changesets in %d staged files", len(groups), len(files))
+
+	msgOpts := MessageOptions{Offline: opts.Offline}
+	for _, g := range groups {
+		if g.Message, err = GenerateMessageForChanges(repoRoot, cfg, g.Changes, msgOpts); err != nil {
+			return err
+		}
+	}
+
+	skipConf := false
+	if cfg.SkipConfirmation != nil {
+		skipConf = *cfg.SkipConfirmation
+	}
+
+	if !opts.Force && !skipConf {
+		reader := bufio.NewReader(os.Stdin)
+		for {
+			printChangesets(groups)
+			fmt.Print(color.CyanString("\n🤔 [y] commit all, [m A B] merge groups, [n] cancel: "))
+			input, err := reader.ReadString('\n')
+			fields := strings.Fields(strings.ToLower(input))
+			if len(fields) == 0 {
+				if err != nil {
+					// stdin is closed; there is nobody to answer.
+					color.Red("❌ Cancelled.\n")
+					return nil
+				}
+				continue
+			}
+
+			switch fields[0] {
+			case "y", "yes":
+			case "m", "merge":
+				var merged *Changeset
+				groups, merged, err = mergeChangesets(groups, fields[1:])
+				if err != nil {
+					color.Red("✗ %v", err)
+					continue
+				}
+				if merged.Message, err = GenerateMessageForChanges(repoRoot, cfg, merged.Changes, msgOpts); err != nil {
+					return err
+				}
+				continue
+			case "n", "no", "q":
+				color.Red("❌ Cancelled.\n")
+				return nil
+			default:
+				color.Yellow("⚠️ Unknown choice: %s", fields[0])
+				continue
+			}
+			break
+		}
+	}
+
+	if err := commitChangesets(repoRoot, groups); err != nil {
+		return err
+	}
+
+	if !opts.NoPush {
+		if err := pushWithDiscovery(repo); err != nil {
+			return err
+		}
+	}
+	syncForkIfEnabled(repoRoot, cfg)
+
+	color.Green("✓ Done with this repository!\n")
+	return nil
+}
+
+// GroupChanges partitions file changes into changesets. Files that scopeOf
+// places in a monorepo scope are grouped by it. Otherwise dependency
+// manifests, CI, docs and configuration each get their own group, while code
+// and tests are grouped by the directory scope they live in.
+func GroupChanges(fileChanges []summarizer.FileChange, scopeOf func(file string) string) []*Changeset {
+	byKey := make(map[string]*Changeset)
+	var order []string
+
+	add := func(key, label string, fc summarizer.FileChange) {
+		g, ok := byKey[key]
+		if !ok {
+			g = &Changeset{Label: label}
+			byKey[key] = g
+			order = append(order, key)
+		}
+		g.Changes = append(g.Changes, fc)
+	}
+
+	for _, fc := range fileChanges {
+		if s := scopeOf(fc.File); s != "" {
+			add("scope:"+s, s, fc)
+			continue
+		}
+		switch category := offline.Categorize(fc.File); category {
+		case "code", "test":
+			scope := offline.Scope(fc.File)
+			label := scope
+			if label == "" {
+				label = "root"
+			}
+			add("scope:"+scope, label, fc)
+		case "build":
+			add(category, "deps", fc)
+		default:
+			add(category, category, fc)
+		}
+	}
+
+	// Dependency bumps go first so that later groups build against them;
+	// everything else keeps a stable alphabetical order.
+	sort.SliceStable(order, func(i, j int) bool {
+		if (order[i] == "build") != (order[j] == "build") {
+			return order[i] == "build"
+		}
+		return order[i] < order[j]
+	})
+
+	groups := make([]*Changeset, 0, len(order))
+	for _, key := range order {
+		groups = append(groups, byKey[key])
+	}
+	return groups
+}
+
+func printChangesets(groups []*Changeset) {
+	fmt.Println()
+	color.New(color.FgCyan, color.Bold).Println("🧩 Proposed commits:")
+	for i, g := range groups {
+		fmt.Printf("%d. [%s] %s\n", i+1, color.YellowString(g.Label), color.New(color.Faint).Sprint(strings.Join(g.Files(), ", ")))
+		color.Cyan("   💬 %s", color.New(color.Italic).Sprint(strings.SplitN(g.Message, "\n", 2)[0]))
+	}
+}
+
+// mergeChangesets folds the groups numbered in args into the first of them.
+// It returns the updated group list and the merged group.
+func mergeChangesets(groups []*Changeset, args []string) ([]*Changeset, *Changeset, error) {
+	if len(args) < 2 {
+		return groups, nil, fmt.Errorf("specify at least two groups to merge, e.g. 'm 1 3'")
+	}
+
+	var indexes []int
+	seen := make(map[int]bool)
+	for _, a := range args {
+		n, err := strconv.Atoi(a)
+		if err != nil || n < 1 || n > len(groups) {
+			return groups, nil, fmt.Errorf("invalid group: %s", a)
+		}
+		if !seen[n-1] {
+			seen[n-1] = true
+			indexes = append(indexes, n-1)
+		}
+	}
+	sort.Ints(indexes)
+
+	target := groups[indexes[0]]
+	var labels []string
+	for _, idx := range indexes {
+		labels = append(labels, groups[idx].Label)
+		if idx != indexes[0] {
+			target.Changes = append(target.Changes, groups[idx].Changes...)
+		}
+	}
+	target.Label = strings.Join(labels, "+")
+
+	remaining := make([]*Changeset, 0, len(groups)-len(indexes)+1)
+	for i, g := range groups {
+		if !seen[i] || i == indexes[0] {
+			remaining = append(remaining, g)
+		}
+	}
+	return remaining, target, nil
+}
+
+// commitChangesets commits each group in order by rebuilding the index from
+// HEAD plus that group's staged entries. Unstaged working tree changes are left
+// untouched. If a commit fails, the remaining groups are restaged.
+func commitChangesets(repoRoot string, groups []*Changeset) error {
+	entries := make([][]git.IndexEntry, len(groups))
+	for i, g := range groups {
+		e, err := git.GetIndexEntries(repoRoot, g.Files())
+		if err != nil {
+			return err
+		}
+		entries[i] = e
+	}
+
+	for i, g := range groups {
+		color.Cyan("✍️ Committing %d/%d [%s]...", i+1, len(groups), g.Label)
+		err := git.ResetIndexToHead(repoRoot)
+		if err == nil {
+			err = git.UpdateIndexEntries(repoRoot, entries[i])
+		}
+		if err == nil {
+			err = git.CommitWithMessage(repoRoot, g.Message)
+		}
+		if err != nil {
+			restoreIndex(repoRoot, entries[i:])
+			return fmt.Errorf("commit %d/%d failed (remaining changes restaged): %w", i+1, len(groups), err)
+		}
+		color.Green("✓ %s", strings.SplitN(g.Message, "\n", 2)[0])
+	}
+	return nil
+}
+
+func restoreIndex(repoRoot string, remaining [][]git.IndexEntry) {
+	if err := git.ResetIndexToHead(repoRoot); err != nil {
+		color.Red("✗ Could not reset index: %v", err)
+		return
+	}
+	for _, e := range remaining {
+		if err := git.UpdateIndexEntries(repoRoot, e); err != nil {
+			color.Red("✗ Could not restage entries: %v", err)
+		}
+	}
+}
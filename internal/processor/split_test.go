@@ -0,0 +1,47 @@
+package processor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/autocommiter.go/internal/summarizer"
+)
+
+func TestGroupChangesByScope(t *testing.T) {
+	changes := []summarizer.FileChange{
+		{File: "services/billing/main.go"},
+		{File: "services/billing/go.mod"},
+		{File: "web/src/app.ts"},
+		{File: "docs/guide.md"},
+		{File: "internal/util/strings.go"},
+	}
+	scopeOf := func(file string) string {
+		switch {
+		case strings.HasPrefix(file, "services/billing/"):
+			return "billing-api"
+		case strings.HasPrefix(file, "web/"):
+			return "web"
+		}
+		return ""
+	}
+
+	var labels []string
+	for _, g := range GroupChanges(changes, scopeOf) {
+		labels = append(labels, g.Label)
+	}
+	// A module's manifest travels with its code; files outside every scope
+	// are grouped as before.
+	want := []string{"docs", "billing-api", "util", "web"}
+	if !reflect.DeepEqual(labels, want) {
+		t.Errorf("labels = %v; want %v", labels, want)
+	}
+
+	var noScope []string
+	for _, g := range GroupChanges(changes, func(string) string { return "" }) {
+		noScope = append(noScope, g.Label)
+	}
+	if want := []string{"deps", "docs", "billing", "util", "web"}; !reflect.DeepEqual(noScope, want) {
+		t.Errorf("labels without scopes = %v; want %v", noScope, want)
+	}
+}
@@ -0,0 +1,207 @@
+package processor
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/nathfavour/autocommiter.go/internal/config"
+	"github.com/nathfavour/autocommiter.go/internal/git"
+)
+
+// Staging policies applied when nothing is staged.
+const (
+	StageTracked     = "tracked"     // git add -u
+	StageAll         = "all"         // tracked and untracked files
+	StageInteractive = "interactive" // pick files from a list
+	StageAbort       = "abort"       // stage nothing
+)
+
+// StagingPolicies returns the supported staging policies.
+func StagingPolicies() []string {
+	return []string{StageTracked, StageAll, StageInteractive, StageAbort}
+}
+
+// ValidateStagingPolicy returns an error for unknown policy names.
+func ValidateStagingPolicy(policy string) error {
+	for _, p := range StagingPolicies() {
+		if p == policy {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown staging policy %q (supported: %s)", policy, strings.Join(StagingPolicies(), ", "))
+}
+
+// ResolveStagingPolicy returns override if set, otherwise the configured policy.
+func ResolveStagingPolicy(cfg config.Config, override string) (string, error) {
+	policy := override
+	if policy == "" && cfg.StagingPolicy != nil {
+		policy = *cfg.StagingPolicy
+	}
+	if policy == "" {
+		policy = StageTracked
+	}
+	policy = strings.ToLower(strings.TrimSpace(policy))
+	return policy, ValidateStagingPolicy(policy)
+}
+
+// StageByPolicy stages working tree changes according to policy. Untracked
+// files are listed separately, and files the policy leaves out are reported.
+// When interactive is false the interactive policy falls back to tracked.
+func StageByPolicy(repo git.Repository, policy string, interactive bool) error {
+	return stageByPolicy(repo, policy, interactive, color.Output)
+}
+
+// stageByPolicy is StageByPolicy reporting to w.
+func stageByPolicy(repo git.Repository, policy string, interactive bool, w io.Writer) error {
+	modified, err := repo.UnstagedFiles()
+	if err != nil {
+		return err
+	}
+	untracked, err := repo.UntrackedFiles()
+	if err != nil {
+		return err
+	}
+	if len(modified) == 0 && len(untracked) == 0 {
+		return nil
+	}
+
+	if len(modified) > 0 {
+		color.New(color.FgCyan).Fprintf(w, "📝 %d tracked files changed\n", len(modified))
+	}
+	if len(untracked) > 0 {
+		color.New(color.FgCyan).Fprintf(w, "❔ %d untracked files:\n", len(untracked))
+		printFileList(w, untracked)
+	}
+
+	if policy == StageInteractive && !interactive {
+		color.New(color.FgYellow).Fprintln(w, "⚠️ Interactive staging unavailable without a prompt; staging tracked files only.")
+		policy = StageTracked
+	}
+
+	var selected []string
+	switch policy {
+	case StageAll:
+		color.New(color.FgCyan).Fprintf(w, "📦 Staging all changes (policy: %s)...\n", policy)
+		selected = append(append(selected, modified...), untracked...)
+	case StageTracked:
+		color.New(color.FgCyan).Fprintf(w, "📦 Staging tracked changes (policy: %s)...\n", policy)
+		selected = modified
+	case StageInteractive:
+		selected, err = pickFiles(modified, untracked)
+		if err != nil {
+			return err
+		}
+	case StageAbort:
+		color.New(color.FgYellow).Fprintf(w, "ℹ️ Nothing staged and staging policy is '%s'. Stage files with 'git add' first.\n", StageAbort)
+		return nil
+	default:
+		return ValidateStagingPolicy(policy)
+	}
+
+	if err := repo.Stage(selected); err != nil {
+		return err
+	}
+
+	if excluded := excludedFiles(append(modified, untracked...), selected); len(excluded) > 0 {
+		color.New(color.FgYellow).Fprintf(w, "ℹ️ Left %d files unstaged:\n", len(excluded))
+		printFileList(w, excluded)
+	}
+	return nil
+}
+
+func pickFiles(modified, untracked []string) ([]string, error) {
+	candidates := append(append([]string{}, modified...), untracked...)
+	fmt.Println()
+	color.New(color.FgCyan, color.Bold).Println("📋 Select files to stage:")
+	for i, f := range candidates {
+		marker := color.YellowString("M")
+		if i >= len(modified) {
+			marker = color.New(color.Faint).Sprint("?")
+		}
+		fmt.Printf("%3d. %s %s\n", i+1, marker, f)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Print(color.CyanString("\n🤔 Files (e.g. 1 3 5-7), [t] tracked, [a] all, [Enter] none: "))
+		input, _ := reader.ReadString('\n')
+		input = strings.ToLower(strings.TrimSpace(input))
+		switch input {
+		case "":
+			return nil, nil
+		case "t":
+			return modified, nil
+		case "a":
+			return candidates, nil
+		}
+
+		indexes, err := parseSelection(input, len(candidates))
+		if err != nil {
+			color.Red("✗ %v", err)
+			continue
+		}
+		selected := make([]string, 0, len(indexes))
+		for _, i := range indexes {
+			selected = append(selected, candidates[i])
+		}
+		return selected, nil
+	}
+}
+
+// parseSelection turns "1 3,5-7" into sorted zero-based indexes below n.
+func parseSelection(input string, n int) ([]int, error) {
+	seen := make(map[int]bool)
+	fields := strings.FieldsFunc(input, func(r rune) bool { return r == ' ' || r == ',' })
+	for _, f := range fields {
+		lo, hi := f, f
+		if a, b, ok := strings.Cut(f, "-"); ok {
+			lo, hi = a, b
+		}
+		start, err1 := strconv.Atoi(lo)
+		end, err2 := strconv.Atoi(hi)
+		if err1 != nil || err2 != nil || start < 1 || end > n || start > end {
+			return nil, fmt.Errorf("invalid selection: %s", f)
+		}
+		for i := start; i <= end; i++ {
+			seen[i-1] = true
+		}
+	}
+
+	indexes := make([]int, 0, len(seen))
+	for i := range seen {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return indexes, nil
+}
+
+func excludedFiles(all, selected []string) []string {
+	chosen := make(map[string]bool, len(selected))
+	for _, f := range selected {
+		chosen[f] = true
+	}
+	var excluded []string
+	for _, f := range all {
+		if !chosen[f] {
+			excluded = append(excluded, f)
+		}
+	}
+	return excluded
+}
+
+func printFileList(w io.Writer, files []string) {
+	const limit = 10
+	for i, f := range files {
+		if i == limit {
+			color.New(color.Faint).Fprintf(w, "   ...and %d more\n", len(files)-limit)
+			break
+		}
+		color.New(color.Faint).Fprintf(w, "   %s\n", f)
+	}
+}
@@ -0,0 +1,84 @@
+package processor
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestParseSelection(t *testing.T) {
+	tests := []struct {
+		input   string
+		n       int
+		want    []int
+		wantErr bool
+	}{
+		{"1", 3, []int{0}, false},
+		{"1 3,5-7", 7, []int{0, 2, 4, 5, 6}, false},
+		{"2-3, 1", 3, []int{0, 1, 2}, false},
+		{"3 1-3 3", 3, []int{0, 1, 2}, false},
+		{"4-4", 4, []int{3}, false},
+		{",, ", 3, []int{}, false},
+		{"3-1", 3, nil, true},
+		{"0", 3, nil, true},
+		{"4", 3, nil, true},
+		{"2-4", 3, nil, true},
+		{"-2", 3, nil, true},
+		{"1-", 3, nil, true},
+		{"x", 3, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseSelection(tt.input, tt.n)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSelection(%q, %d) error = %v; want error %v", tt.input, tt.n, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSelection(%q, %d) = %v; want %v", tt.input, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestPickFiles(t *testing.T) {
+	modified := []string{"a.go", "b.go"}
+	untracked := []string{"c.go"}
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"\n", nil},
+		{"t\n", []string{"a.go", "b.go"}},
+		{"A\n", []string{"a.go", "b.go", "c.go"}},
+		{"3 1\n", []string{"a.go", "c.go"}},
+		// An invalid selection asks again.
+		{"5\n2-3\n", []string{"b.go", "c.go"}},
+	}
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	for _, tt := range tests {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.WriteString(tt.input)
+		w.Close()
+		stdin, stdout, output := os.Stdin, os.Stdout, color.Output
+		os.Stdin, os.Stdout, color.Output = r, devNull, io.Discard
+		got, err := pickFiles(modified, untracked)
+		os.Stdin, os.Stdout, color.Output = stdin, stdout, output
+		r.Close()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("pickFiles(%q) = %v; want %v", tt.input, got, tt.want)
+		}
+	}
+}
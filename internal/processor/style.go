@@ -0,0 +1,58 @@
+package processor
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/nathfavour/autocommiter.go/internal/config"
+	"github.com/nathfavour/autocommiter.go/internal/git"
+	"github.com/nathfavour/autocommiter.go/internal/index"
+	"github.com/nathfavour/autocommiter.go/internal/style"
+)
+
+// styleCacheTTL is how long a learned style profile is reused before the
+// history is analyzed again.
+const styleCacheTTL = 24 * time.Hour
+
+// LoadStyleProfile returns the commit style profile of the repository, using
+// the index cache unless refresh is set.
+func LoadStyleProfile(repoRoot string, cfg config.Config, refresh bool) (style.Profile, error) {
+	if !refresh {
+		if cached, ok := index.GetStyleProfile(repoRoot, styleCacheTTL); ok {
+			var p style.Profile
+			if err := json.Unmarshal([]byte(cached), &p); err == nil {
+				return p, nil
+			}
+		}
+	}
+
+	sampleSize := 50
+	if cfg.StyleSampleSize != nil && *cfg.StyleSampleSize > 0 {
+		sampleSize = *cfg.StyleSampleSize
+	}
+
+	messages, err := git.GetRecentCommitMessages(repoRoot, sampleSize)
+	if err != nil {
+		// A repository without commits has no style to learn yet.
+		return style.Analyze(nil), nil
+	}
+
+	p := style.Analyze(messages)
+	if data, err := json.Marshal(p); err == nil {
+		_ = index.SetStyleProfile(repoRoot, string(data))
+	}
+	return p, nil
+}
+
+// stylePromptRules returns the learned style instructions for the prompt, or
+// an empty string when style learning is disabled or history is too short.
+func stylePromptRules(repoRoot string, cfg config.Config) string {
+	if cfg.LearnStyle != nil && !*cfg.LearnStyle {
+		return ""
+	}
+	p, err := LoadStyleProfile(repoRoot, cfg, false)
+	if err != nil {
+		return ""
+	}
+	return p.PromptRules()
+}
@@ -0,0 +1,218 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/nathfavour/autocommiter.go/internal/config"
+	"github.com/nathfavour/autocommiter.go/internal/git"
+)
+
+// Submodule policies, applied to the submodules of a discovered repository.
+const (
+	SubmodulesSkip    = "skip"    // left alone, as before submodules were discovered
+	SubmodulesInclude = "include" // committed on their own, before the superproject
+	SubmodulesRecurse = "recurse" // committed first, then the superproject records their new commits
+)
+
+// SubmodulePolicies returns the supported submodule policies.
+func SubmodulePolicies() []string {
+	return []string{SubmodulesSkip, SubmodulesInclude, SubmodulesRecurse}
+}
+
+// ValidateSubmodulePolicy returns an error for unknown policy names.
+func ValidateSubmodulePolicy(policy string) error {
+	for _, p := range SubmodulePolicies() {
+		if p == policy {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown submodule policy %q (supported: %s)", policy, strings.Join(SubmodulePolicies(), ", "))
+}
+
+// ResolveSubmodulePolicy returns override if set, otherwise the configured
+// policy. Submodules are skipped unless one opts in.
+func ResolveSubmodulePolicy(cfg config.Config, override string) (string, error) {
+	policy := override
+	if policy == "" && cfg.SubmodulePolicy != nil {
+		policy = *cfg.SubmodulePolicy
+	}
+	if policy == "" {
+		policy = SubmodulesSkip
+	}
+	policy = strings.ToLower(strings.TrimSpace(policy))
+	return policy, ValidateSubmodulePolicy(policy)
+}
+
+// orderRepositories drops the submodules whose superproject skips them and
+// puts nested repositories before the ones containing them, so submodules
+// are committed before their superproject. Submodules skipped only because
+// no policy is set are counted in a hint.
+func orderRepositories(repos []string, override string) ([]string, error) {
+	listed := make(map[string]bool)
+	for _, r := range repos {
+		listed[r] = true
+	}
+	kept := make([]string, 0, len(repos))
+	skipped := 0
+	for _, r := range repos {
+		if super := git.GetSuperproject(r); super != "" && listed[filepath.Clean(super)] {
+			cfg, _ := config.LoadMergedConfig(super)
+			policy, err := ResolveSubmodulePolicy(cfg, override)
+			if err != nil {
+				return nil, err
+			}
+			if policy == SubmodulesSkip {
+				if override == "" && cfg.SubmodulePolicy == nil {
+					skipped++
+				}
+				continue
+			}
+		}
+		kept = append(kept, r)
+	}
+	if skipped > 0 {
+		color.Yellow("ℹ️ Skipped %d submodules; commit them with --submodules include or recurse", skipped)
+	}
+	return nestedFirst(kept), nil
+}
+
+// nestedFirst orders repository paths so that every repository comes after
+// the repositories inside it, and siblings stay sorted.
+func nestedFirst(repos []string) []string {
+	sorted := append([]string(nil), repos...)
+	// Compare with "/" as the lowest byte so "a/sub" sorts right after "a",
+	// before "a-b".
+	key := func(p string) string { return strings.ReplaceAll(filepath.ToSlash(p), "/", "\x00") }
+	sort.Slice(sorted, func(i, j int) bool { return key(sorted[i]) < key(sorted[j]) })
+
+	out := make([]string, 0, len(sorted))
+	var stack []string
+	for _, r := range sorted {
+		for len(stack) > 0 && !within(r, stack[len(stack)-1]) {
+			out = append(out, stack[len(stack)-1])
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, r)
+	}
+	for len(stack) > 0 {
+		out = append(out, stack[len(stack)-1])
+		stack = stack[:len(stack)-1]
+	}
+	return out
+}
+
+// within reports whether path lies inside dir.
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// submoduleBump is a submodule whose checked out commit moved.
+type submoduleBump struct {
+	Path     string
+	From, To string
+	// Subjects are the subjects of the new commits, newest first.
+	Subjects []string
+}
+
+// commitSubmodulePointers stages the submodules of repoRoot that moved and
+// commits them with a message listing their new commits. Moved submodules in
+// exclude are left unstaged. It returns the message, or an empty string when
+// no submodule was committed, and the excluded submodules that moved.
+func commitSubmodulePointers(repoRoot string, cfg config.Config, exclude []string) (string, []string, error) {
+	subs, err := git.GetSubmodules(repoRoot)
+	if err != nil {
+		return "", nil, err
+	}
+	excluded := make(map[string]bool)
+	for _, p := range exclude {
+		excluded[p] = true
+	}
+	var bumps []submoduleBump
+	var paths, held []string
+	for _, s := range subs {
+		if !s.Moved {
+			continue
+		}
+		if excluded[s.Path] {
+			held = append(held, s.Path)
+			continue
+		}
+		b := submoduleBump{Path: s.Path, From: git.GetGitlink(repoRoot, "HEAD", s.Path), To: s.Commit}
+		if b.From != "" {
+			log, _ := git.GetRangeLog(filepath.Join(repoRoot, s.Path), b.From+".."+b.To)
+			for _, entry := range log {
+				subject, _, _ := strings.Cut(entry.Message, "\n")
+				b.Subjects = append(b.Subjects, subject)
+			}
+		}
+		bumps = append(bumps, b)
+		paths = append(paths, s.Path)
+	}
+	if len(bumps) == 0 {
+		return "", held, nil
+	}
+
+	if err := git.StageFiles(repoRoot, paths); err != nil {
+		return "", held, err
+	}
+	message := enforceLint(os.Stderr, applyGitmoji(submoduleMessage(bumps), cfg), LintConfig(repoRoot, cfg), nil)
+	if err := git.CommitWithMessage(repoRoot, message); err != nil {
+		return "", held, err
+	}
+	return message, held, nil
+}
+
+// maxSubmoduleSubjects is how many commits of a submodule a pointer update
+// lists.
+const maxSubmoduleSubjects = 10
+
+// submoduleMessage describes moved submodules and their new commits.
+func submoduleMessage(bumps []submoduleBump) string {
+	var subject string
+	switch {
+	case len(bumps) == 1:
+		subject = fmt.Sprintf("chore: update %s submodule", bumps[0].Path)
+	case len(bumps) <= 3:
+		var paths []string
+		for _, b := range bumps {
+			paths = append(paths, b.Path)
+		}
+		subject = "chore: update submodules " + strings.Join(paths, ", ")
+	default:
+		subject = fmt.Sprintf("chore: update %d submodules", len(bumps))
+	}
+
+	var sections []string
+	for _, b := range bumps {
+		if b.From == "" {
+			sections = append(sections, fmt.Sprintf("Add %s at %s.", b.Path, short(b.To)))
+			continue
+		}
+		lines := []string{fmt.Sprintf("Move %s from %s to %s:", b.Path, short(b.From), short(b.To))}
+		if len(b.Subjects) == 0 {
+			lines[0] = strings.TrimSuffix(lines[0], ":") + "."
+		}
+		for i, s := range b.Subjects {
+			if i == maxSubmoduleSubjects {
+				lines = append(lines, fmt.Sprintf("- and %d more", len(b.Subjects)-i))
+				break
+			}
+			lines = append(lines, "- "+s)
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+	return subject + "\n\n" + strings.Join(sections, "\n\n")
+}
+
+func short(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,80 @@
+package processor
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nathfavour/autocommiter.go/internal/config"
+)
+
+func TestNestedFirst(t *testing.T) {
+	repos := []string{"/w/b", "/w/a-b", "/w/a", "/w/a/sub", "/w/a/sub/deep", "/w/a/other"}
+	want := []string{"/w/a/other", "/w/a/sub/deep", "/w/a/sub", "/w/a", "/w/a-b", "/w/b"}
+	if got := nestedFirst(repos); !reflect.DeepEqual(got, want) {
+		t.Errorf("nestedFirst = %v; want %v", got, want)
+	}
+}
+
+func TestNestedGroups(t *testing.T) {
+	repos := nestedFirst([]string{"/w/a", "/w/a/sub", "/w/b", "/w/a/sub/deep"})
+	want := [][]int{{0, 1, 2}, {3}}
+	if got := nestedGroups(repos); !reflect.DeepEqual(got, want) {
+		t.Errorf("nestedGroups(%v) = %v; want %v", repos, got, want)
+	}
+}
+
+func TestSubmoduleMessage(t *testing.T) {
+	var many []string
+	for i := 0; i < maxSubmoduleSubjects+2; i++ {
+		many = append(many, fmt.Sprintf("fix: bug %d", i))
+	}
+	tests := []struct {
+		name  string
+		bumps []submoduleBump
+		want  string
+	}{
+		{
+			"one",
+			[]submoduleBump{{Path: "libs/ui", From: "1111111aaaa", To: "2222222bbbb", Subjects: []string{"feat: add button", "fix: align icon"}}},
+			"chore: update libs/ui submodule\n\nMove libs/ui from 1111111 to 2222222:\n- feat: add button\n- fix: align icon",
+		},
+		{
+			"added and rewound",
+			[]submoduleBump{{Path: "a", To: "3333333cccc"}, {Path: "b", From: "1111111", To: "2222222"}},
+			"chore: update submodules a, b\n\nAdd a at 3333333.\n\nMove b from 1111111 to 2222222.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := submoduleMessage(tt.bumps); got != tt.want {
+				t.Errorf("submoduleMessage =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+
+	got := submoduleMessage([]submoduleBump{{Path: "a", From: "1", To: "2", Subjects: many}, {Path: "b"}, {Path: "c"}, {Path: "d"}})
+	if !strings.HasPrefix(got, "chore: update 4 submodules\n") || !strings.Contains(got, "- and 2 more") {
+		t.Errorf("submoduleMessage = %q", got)
+	}
+}
+
+func TestResolveSubmodulePolicy(t *testing.T) {
+	if p, err := ResolveSubmodulePolicy(configWithSubmodules(""), ""); p != SubmodulesSkip || err != nil {
+		t.Errorf("default = %q, %v", p, err)
+	}
+	if p, _ := ResolveSubmodulePolicy(configWithSubmodules("skip"), "Recurse"); p != SubmodulesRecurse {
+		t.Errorf("override = %q; want recurse", p)
+	}
+	if _, err := ResolveSubmodulePolicy(configWithSubmodules("always"), ""); err == nil {
+		t.Error("ResolveSubmodulePolicy accepted an unknown policy")
+	}
+}
+
+func configWithSubmodules(policy string) config.Config {
+	if policy == "" {
+		return config.Config{}
+	}
+	return config.Config{SubmodulePolicy: &policy}
+}
@@ -0,0 +1,24 @@
+package processor
+
+import (
+	"io"
+
+	"github.com/fatih/color"
+	"github.com/nathfavour/autocommiter.go/internal/config"
+	"github.com/nathfavour/autocommiter.go/internal/ticket"
+)
+
+// ticketRef returns the issue reference in branch and the ticket settings
+// it should be placed with. Extraction problems are reported to w.
+func ticketRef(branch string, cfg config.Config, w io.Writer) (string, ticket.Config) {
+	var tc ticket.Config
+	if cfg.Ticket != nil {
+		tc = *cfg.Ticket
+	}
+	ref, err := ticket.Extract(branch, tc)
+	if err != nil {
+		color.New(color.FgYellow).Fprintf(w, "⚠️ Ticket reference skipped: %v\n", err)
+		return "", tc
+	}
+	return ref, tc
+}
@@ -0,0 +1,134 @@
+// Package sarif builds SARIF 2.1.0 logs for code-scanning dashboards.
+package sarif
+
+// Version and Schema identify the SARIF revision produced by this package.
+const (
+	Version = "2.1.0"
+	Schema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// Result levels.
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelNote    = "note"
+)
+
+type Log struct {
+	Version string `json:"version"`
+	Schema  string `json:"$schema"`
+	Runs    []Run  `json:"runs"`
+}
+
+type Run struct {
+	Tool    Tool     `json:"tool"`
+	Results []Result `json:"results"`
+}
+
+type Tool struct {
+	Driver Driver `json:"driver"`
+}
+
+type Driver struct {
+	Name           string                `json:"name"`
+	Version        string                `json:"version,omitempty"`
+	InformationURI string                `json:"informationUri,omitempty"`
+	Rules          []ReportingDescriptor `json:"rules"`
+}
+
+type ReportingDescriptor struct {
+	ID               string   `json:"id"`
+	Name             string   `json:"name,omitempty"`
+	ShortDescription *Message `json:"shortDescription,omitempty"`
+	DefaultConfig    *Config  `json:"defaultConfiguration,omitempty"`
+}
+
+type Config struct {
+	Level string `json:"level"`
+}
+
+type Message struct {
+	Text string `json:"text"`
+}
+
+type Result struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             Message           `json:"message"`
+	Locations           []Location        `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+}
+
+type Location struct {
+	PhysicalLocation PhysicalLocation `json:"physicalLocation"`
+}
+
+type PhysicalLocation struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+	Region           *Region          `json:"region,omitempty"`
+}
+
+type ArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type Region struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// New creates a log with a single run for the named tool.
+func New(name, version, informationURI string) *Log {
+	return &Log{
+		Version: Version,
+		Schema:  Schema,
+		Runs: []Run{{
+			Tool: Tool{Driver: Driver{
+				Name:           name,
+				Version:        version,
+				InformationURI: informationURI,
+				Rules:          []ReportingDescriptor{},
+			}},
+			Results: []Result{},
+		}},
+	}
+}
+
+// AddResult records a finding at file:line:column (line 0 means the whole
+// file), registering ruleID on first use.
+func (l *Log) AddResult(ruleID, description, level, message, file string, line, column int, fingerprint string) {
+	run := &l.Runs[0]
+	index := -1
+	for i, r := range run.Tool.Driver.Rules {
+		if r.ID == ruleID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		index = len(run.Tool.Driver.Rules)
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, ReportingDescriptor{
+			ID:               ruleID,
+			Name:             description,
+			ShortDescription: &Message{Text: description},
+			DefaultConfig:    &Config{Level: level},
+		})
+	}
+
+	loc := PhysicalLocation{ArtifactLocation: ArtifactLocation{URI: file}}
+	if line > 0 {
+		loc.Region = &Region{StartLine: line, StartColumn: column}
+	}
+	result := Result{
+		RuleID:    ruleID,
+		RuleIndex: index,
+		Level:     level,
+		Message:   Message{Text: message},
+		Locations: []Location{{PhysicalLocation: loc}},
+	}
+	if fingerprint != "" {
+		result.PartialFingerprints = map[string]string{"autocommiter/v1": fingerprint}
+	}
+	run.Results = append(run.Results, result)
+}
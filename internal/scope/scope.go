@@ -0,0 +1,219 @@
+// Package scope maps the files of a monorepo to Conventional Commits scopes,
+// from configured path patterns or from the package manifests that mark
+// module boundaries.
+package scope
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nathfavour/autocommiter.go/internal/glob"
+)
+
+// What to do when staged files span several scopes.
+const (
+	OnMultipleWarn   = "warn"
+	OnMultipleSplit  = "split"
+	OnMultipleIgnore = "ignore"
+)
+
+// Manifests are the files that mark the root of a module, in the order they
+// are consulted for its name.
+var Manifests = []string{"package.json", "Cargo.toml", "pyproject.toml", "go.mod"}
+
+// Config selects how files map to scopes.
+type Config struct {
+	Disabled bool `json:"disabled,omitempty"`
+	// Paths maps globs to scopes, e.g. {"services/billing/": "billing-api"}.
+	// The longest matching pattern wins, and mapped files skip module
+	// detection.
+	Paths map[string]string `json:"paths,omitempty"`
+	// NoDetect turns off module detection from manifests.
+	NoDetect bool `json:"no_detect,omitempty"`
+	// OnMultiple is OnMultipleWarn (default), OnMultipleSplit or
+	// OnMultipleIgnore.
+	OnMultiple string `json:"on_multiple,omitempty"`
+}
+
+// Validate reports an unknown OnMultiple value.
+func (c Config) Validate() error {
+	switch c.OnMultiple {
+	case "", OnMultipleWarn, OnMultipleSplit, OnMultipleIgnore:
+		return nil
+	}
+	return fmt.Errorf("invalid on_multiple %q (use warn, split or ignore)", c.OnMultiple)
+}
+
+// Resolver finds the scope of repository files. It caches the module of
+// each directory it visits.
+type Resolver struct {
+	root     string
+	cfg      Config
+	patterns []string
+	modules  map[string]string
+}
+
+// NewResolver returns a resolver for the repository at root.
+func NewResolver(root string, cfg Config) *Resolver {
+	patterns := make([]string, 0, len(cfg.Paths))
+	for p := range cfg.Paths {
+		patterns = append(patterns, p)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	return &Resolver{root: root, cfg: cfg, patterns: patterns, modules: make(map[string]string)}
+}
+
+// Scope returns the scope of file, a slash-separated path relative to the
+// repository root. Files outside every mapped path and module, including
+// those of a module at the repository root, have no scope.
+func (r *Resolver) Scope(file string) string {
+	if r.cfg.Disabled {
+		return ""
+	}
+	for _, p := range r.patterns {
+		if glob.Match(p, file) {
+			return r.cfg.Paths[p]
+		}
+	}
+	if r.cfg.NoDetect {
+		return ""
+	}
+	return r.module(path.Dir(file))
+}
+
+// Scopes returns the distinct scopes of files, sorted, leaving out files
+// without one.
+func (r *Resolver) Scopes(files []string) []string {
+	seen := make(map[string]bool)
+	var scopes []string
+	for _, f := range files {
+		if s := r.Scope(f); s != "" && !seen[s] {
+			seen[s] = true
+			scopes = append(scopes, s)
+		}
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// module returns the scope of the nearest module containing dir.
+func (r *Resolver) module(dir string) string {
+	if dir == "." || dir == "/" || dir == "" {
+		return ""
+	}
+	if s, ok := r.modules[dir]; ok {
+		return s
+	}
+	s, ok := moduleName(filepath.Join(r.root, filepath.FromSlash(dir)))
+	if !ok {
+		s = r.module(path.Dir(dir))
+	}
+	r.modules[dir] = s
+	return s
+}
+
+// moduleName reports whether dir holds a manifest and returns the module's
+// name, falling back to the directory name when the manifest has none.
+func moduleName(dir string) (string, bool) {
+	found := false
+	for _, m := range Manifests {
+		data, err := os.ReadFile(filepath.Join(dir, m))
+		if err != nil {
+			continue
+		}
+		found = true
+		if name := manifestName(m, data); name != "" {
+			return Normalize(name), true
+		}
+	}
+	if !found {
+		return "", false
+	}
+	return Normalize(filepath.Base(dir)), true
+}
+
+var (
+	tomlSectionRegex = regexp.MustCompile(`^\[([^\]]+)\]`)
+	tomlNameRegex    = regexp.MustCompile(`^name\s*=\s*["']([^"']+)["']`)
+	goModuleRegex    = regexp.MustCompile(`^module\s+"?([^\s"]+)"?`)
+	majorVersionPart = regexp.MustCompile(`^v[0-9]+$`)
+)
+
+// manifestName extracts the package name declared in a manifest.
+func manifestName(manifest string, data []byte) string {
+	switch manifest {
+	case "package.json":
+		var pkg struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(data, &pkg) == nil {
+			return pkg.Name
+		}
+	case "Cargo.toml":
+		return tomlName(data, "package")
+	case "pyproject.toml":
+		if name := tomlName(data, "project"); name != "" {
+			return name
+		}
+		return tomlName(data, "tool.poetry")
+	case "go.mod":
+		scanner := bufio.NewScanner(strings.NewReader(string(data)))
+		for scanner.Scan() {
+			if m := goModuleRegex.FindStringSubmatch(strings.TrimSpace(scanner.Text())); m != nil {
+				parts := strings.Split(m[1], "/")
+				name := parts[len(parts)-1]
+				if majorVersionPart.MatchString(name) && len(parts) > 1 {
+					name = parts[len(parts)-2]
+				}
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// tomlName returns the name key of a TOML section.
+func tomlName(data []byte, section string) string {
+	current := ""
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if m := tomlSectionRegex.FindStringSubmatch(line); m != nil {
+			current = strings.TrimSpace(m[1])
+			continue
+		}
+		if current == section {
+			if m := tomlNameRegex.FindStringSubmatch(line); m != nil {
+				return m[1]
+			}
+		}
+	}
+	return ""
+}
+
+// Normalize turns a package name into a scope: "@acme/Billing_API" becomes
+// "billing-api".
+func Normalize(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == ' ' {
+			return '-'
+		}
+		return r
+	}, name)
+}
@@ -0,0 +1,95 @@
+package scope
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestResolverDetectsModules(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":                          "module example.com/mono\n",
+		"services/billing/go.mod":         "module example.com/mono/services/billing-api/v2\n",
+		"web/package.json":                `{"name": "@acme/Web_App", "private": true}`,
+		"crates/parser/Cargo.toml":        "[workspace]\nmembers = []\n\n[package]\nname = \"fast-parser\"\nversion = \"0.1.0\"\n",
+		"tools/lint/pyproject.toml":       "[build-system]\nrequires = []\n\n[tool.poetry]\nname = \"lint_tools\"\n",
+		"libs/unnamed/package.json":       `{"private": true}`,
+		"services/billing/internal/db.go": "",
+	})
+
+	r := NewResolver(root, Config{})
+	tests := map[string]string{
+		"services/billing/internal/db.go": "billing-api",
+		"services/billing/main.go":        "billing-api",
+		"web/src/app.tsx":                 "web-app",
+		"crates/parser/src/lib.rs":        "fast-parser",
+		"tools/lint/check.py":             "lint-tools",
+		"libs/unnamed/index.js":           "unnamed",
+		"cmd/main.go":                     "",
+		"README.md":                       "",
+	}
+	for file, want := range tests {
+		if got := r.Scope(file); got != want {
+			t.Errorf("Scope(%q) = %q; want %q", file, got, want)
+		}
+	}
+
+	got := r.Scopes([]string{"web/a.ts", "README.md", "services/billing/main.go", "web/b.ts"})
+	if want := []string{"billing-api", "web-app"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Scopes = %v; want %v", got, want)
+	}
+}
+
+func TestResolverPaths(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"services/billing/go.mod": "module billing\n"})
+
+	r := NewResolver(root, Config{Paths: map[string]string{
+		"services/":                "services",
+		"services/billing/":        "billing-api",
+		"services/billing/docs/**": "docs",
+	}})
+	tests := map[string]string{
+		"services/billing/main.go":     "billing-api",
+		"services/billing/docs/api.md": "docs",
+		"services/search/main.go":      "services",
+		"other/main.go":                "",
+	}
+	for file, want := range tests {
+		if got := r.Scope(file); got != want {
+			t.Errorf("Scope(%q) = %q; want %q", file, got, want)
+		}
+	}
+
+	r = NewResolver(root, Config{NoDetect: true})
+	if got := r.Scope("services/billing/main.go"); got != "" {
+		t.Errorf("Scope with NoDetect = %q; want none", got)
+	}
+	r = NewResolver(root, Config{Disabled: true, Paths: map[string]string{"services/": "services"}})
+	if got := r.Scope("services/billing/main.go"); got != "" {
+		t.Errorf("Scope when disabled = %q; want none", got)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	for in, want := range map[string]string{"@acme/Billing_API": "billing-api", "Web App": "web-app", "core": "core"} {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q; want %q", in, got, want)
+		}
+	}
+}
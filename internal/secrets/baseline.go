@@ -0,0 +1,102 @@
+package secrets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// BaselineFile is the name of the per-repository baseline of acknowledged
+// findings.
+const BaselineFile = ".autocommiter-baseline.json"
+
+// AllowComments are inline markers that suppress findings on their line.
+var AllowComments = []string{"autocommiter:allow", "gitleaks:allow"}
+
+// Fingerprint identifies a finding independently of its line number, so
+// baselined findings survive unrelated edits. The secret itself is hashed.
+func Fingerprint(file, ruleID, secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return fmt.Sprintf("%s:%s:%s", file, ruleID, hex.EncodeToString(sum[:]))
+}
+
+// BaselineEntry is an acknowledged finding. It never contains the secret.
+type BaselineEntry struct {
+	Fingerprint string    `json:"fingerprint"`
+	File        string    `json:"file"`
+	RuleID      string    `json:"rule_id"`
+	Description string    `json:"description,omitempty"`
+	Line        int       `json:"line,omitempty"`
+	Added       time.Time `json:"added"`
+}
+
+// Baseline is the set of acknowledged findings of a repository.
+type Baseline struct {
+	Version  int             `json:"version"`
+	Findings []BaselineEntry `json:"findings"`
+
+	index map[string]bool
+}
+
+// LoadBaseline reads a baseline file. A missing file yields an empty baseline.
+func LoadBaseline(path string) (*Baseline, error) {
+	b := &Baseline{Version: 1}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return b, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("invalid baseline %s: %w", path, err)
+	}
+	return b, nil
+}
+
+// Contains reports whether fingerprint has been acknowledged.
+func (b *Baseline) Contains(fingerprint string) bool {
+	if b.index == nil {
+		b.index = make(map[string]bool, len(b.Findings))
+		for _, e := range b.Findings {
+			b.index[e.Fingerprint] = true
+		}
+	}
+	return b.index[fingerprint]
+}
+
+// Add records entry unless its fingerprint is already present. It reports
+// whether the entry was added.
+func (b *Baseline) Add(entry BaselineEntry) bool {
+	if b.Contains(entry.Fingerprint) {
+		return false
+	}
+	if entry.Added.IsZero() {
+		entry.Added = time.Now().UTC().Truncate(time.Second)
+	}
+	b.Findings = append(b.Findings, entry)
+	b.index[entry.Fingerprint] = true
+	return true
+}
+
+// Save writes the baseline sorted by file and rule for stable diffs.
+func (b *Baseline) Save(path string) error {
+	sort.SliceStable(b.Findings, func(i, j int) bool {
+		if b.Findings[i].File != b.Findings[j].File {
+			return b.Findings[i].File < b.Findings[j].File
+		}
+		return b.Findings[i].Fingerprint < b.Findings[j].Fingerprint
+	})
+	if b.Version == 0 {
+		b.Version = 1
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
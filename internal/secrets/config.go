@@ -0,0 +1,49 @@
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// File is a gitleaks-style rules file.
+type File struct {
+	Title     string     `json:"title,omitempty" toml:"title"`
+	Rules     []Rule     `json:"rules" toml:"rules"`
+	Allowlist *Allowlist `json:"allowlist,omitempty" toml:"allowlist"`
+}
+
+// LoadFile reads a rules file. Files ending in .json are parsed as JSON;
+// anything else as TOML.
+func LoadFile(path string) (File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return ParseJSON(data)
+	}
+	return ParseTOML(data)
+}
+
+// ParseTOML parses a gitleaks TOML configuration.
+func ParseTOML(data []byte) (File, error) {
+	var f File
+	if _, err := toml.Decode(string(data), &f); err != nil {
+		return f, fmt.Errorf("invalid rules TOML: %w", err)
+	}
+	return f, nil
+}
+
+// ParseJSON parses rules in the same shape as the TOML format.
+func ParseJSON(data []byte) (File, error) {
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return f, fmt.Errorf("invalid rules JSON: %w", err)
+	}
+	return f, nil
+}
@@ -0,0 +1,188 @@
+package secrets
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/nathfavour/autocommiter.go/internal/glob"
+)
+
+// EntropyRuleID and EntropyDescription label entropy-based findings.
+const (
+	EntropyRuleID      = "high-entropy-string"
+	EntropyDescription = "High Entropy String"
+)
+
+// EntropyConfig tunes detection of random-looking strings that no rule
+// matched. Zero values fall back to DefaultEntropy.
+type EntropyConfig struct {
+	Disabled        bool    `json:"disabled,omitempty"`
+	Base64Threshold float64 `json:"base64_threshold,omitempty"`
+	Base64MinLength int     `json:"base64_min_length,omitempty"`
+	HexThreshold    float64 `json:"hex_threshold,omitempty"`
+	HexMinLength    int     `json:"hex_min_length,omitempty"`
+	// ExcludePaths are globs added to the default exclusions (lockfiles,
+	// test fixtures, minified and generated assets).
+	ExcludePaths []string `json:"exclude_paths,omitempty"`
+}
+
+// DefaultEntropy returns the default thresholds. Random base64 tokens of 20 to
+// 40 characters score between 4.1 and 4.8 bits per character and random hex
+// around 3.7, while identifiers, words and version strings stay below them.
+func DefaultEntropy() EntropyConfig {
+	return EntropyConfig{
+		Base64Threshold: 4.0,
+		Base64MinLength: 20,
+		HexThreshold:    3.0,
+		HexMinLength:    32,
+		ExcludePaths: []string{
+			BaselineFile, "go.sum", "*.lock", "package-lock.json", "pnpm-lock.yaml", "npm-shrinkwrap.json",
+			"**/testdata/**", "**/fixtures/**", "**/__fixtures__/**", "**/__snapshots__/**", "*.snap",
+			"*.min.js", "*.min.css", "*.map", "*.svg",
+		},
+	}
+}
+
+// withDefaults fills unset fields of c from DefaultEntropy and appends the
+// default path exclusions.
+func (c EntropyConfig) withDefaults() EntropyConfig {
+	d := DefaultEntropy()
+	if c.Base64Threshold == 0 {
+		c.Base64Threshold = d.Base64Threshold
+	}
+	if c.Base64MinLength == 0 {
+		c.Base64MinLength = d.Base64MinLength
+	}
+	if c.HexThreshold == 0 {
+		c.HexThreshold = d.HexThreshold
+	}
+	if c.HexMinLength == 0 {
+		c.HexMinLength = d.HexMinLength
+	}
+	c.ExcludePaths = append(d.ExcludePaths, c.ExcludePaths...)
+	return c
+}
+
+var (
+	entropyTokenRegex = regexp.MustCompile(`[A-Za-z0-9+/_\-]+={0,2}`)
+	hexRegex          = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+	// digestPrefixRegex precedes content digests and pinned commit SHAs,
+	// e.g. "actions/checkout@<sha>" or "image@sha256:<hex>".
+	digestPrefixRegex = regexp.MustCompile(`(?i)(?:@|sha(?:1|224|256|384|512)[:-]|md5[:-])$`)
+)
+
+// SetEntropy enables entropy scoring on d with cfg.
+func (d *Detector) SetEntropy(cfg EntropyConfig) {
+	if cfg.Disabled {
+		d.entropy = nil
+		return
+	}
+	cfg = cfg.withDefaults()
+	d.entropy = &cfg
+}
+
+// scanEntropy returns high-entropy tokens in line that overlap none of the
+// existing findings.
+func (d *Detector) scanEntropy(path, line string, findings []Finding) []Finding {
+	c := d.entropy
+	if c == nil || glob.MatchAny(c.ExcludePaths, path) {
+		return findings
+	}
+
+	for _, loc := range entropyTokenRegex.FindAllStringIndex(line, -1) {
+		tok := strings.Trim(line[loc[0]:loc[1]], "=-_")
+		if len(tok) < c.Base64MinLength && len(tok) < c.HexMinLength {
+			continue
+		}
+		// Inline data URIs (images, fonts) are base64 by design.
+		prefix := line[:loc[0]]
+		if strings.HasSuffix(prefix, "base64,") || digestPrefixRegex.MatchString(prefix) {
+			continue
+		}
+		if !c.isSecret(tok) || overlaps(findings, tok) {
+			continue
+		}
+		if d.allowlist != nil && d.allowlist.allows(path, line, tok, tok) {
+			continue
+		}
+		findings = append(findings, Finding{
+			RuleID:      EntropyRuleID,
+			Description: EntropyDescription,
+			Match:       tok,
+			Secret:      tok,
+			Column:      loc[0] + strings.Index(line[loc[0]:], tok) + 1,
+			Tags:        []string{"entropy"},
+		})
+	}
+	return findings
+}
+
+// isSecret scores tok against the threshold of its character set.
+func (c *EntropyConfig) isSecret(tok string) bool {
+	if !hasDigitAndLetter(tok) || hasSequence(tok, 8) {
+		return false
+	}
+	if hexRegex.MatchString(tok) {
+		return len(tok) >= c.HexMinLength && ShannonEntropy(tok) >= c.HexThreshold
+	}
+	return len(tok) >= c.Base64MinLength && ShannonEntropy(tok) >= c.Base64Threshold && !isIdentifier(tok)
+}
+
+// hasDigitAndLetter filters out words, identifiers and plain numbers, which
+// can reach high entropy when long but are not credentials.
+func hasDigitAndLetter(tok string) bool {
+	digit, letter := false, false
+	for _, r := range tok {
+		switch {
+		case r >= '0' && r <= '9':
+			digit = true
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+			letter = true
+		}
+	}
+	return digit && letter
+}
+
+// isIdentifier reports whether tok reads like a camelCase or snake_case name.
+// Random base64 switches between lower case, upper case and digits every one
+// or two characters; identifiers run whole words between switches.
+func isIdentifier(tok string) bool {
+	class := func(r rune) int {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return 1
+		case r >= 'A' && r <= 'Z':
+			return 2
+		case r >= '0' && r <= '9':
+			return 3
+		}
+		return 4
+	}
+	runs, prev := 0, 0
+	for _, r := range tok {
+		c := class(r)
+		// A capital followed by lower case letters is a single word.
+		if c != prev && !(prev == 2 && c == 1) {
+			runs++
+		}
+		prev = c
+	}
+	return float64(len(tok))/float64(runs) >= 3.5
+}
+
+// hasSequence reports whether tok contains n or more consecutive ascending
+// characters, as in alphabets and charset tables ("abcdefgh", "01234567").
+func hasSequence(tok string, n int) bool {
+	run := 1
+	for i := 1; i < len(tok); i++ {
+		if tok[i] == tok[i-1]+1 {
+			run++
+			if run >= n {
+				return true
+			}
+		} else {
+			run = 1
+		}
+	}
+	return false
+}
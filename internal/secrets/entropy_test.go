@@ -0,0 +1,98 @@
+package secrets
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+)
+
+// readCorpus returns the path/line pairs of a testdata corpus file.
+func readCorpus(t *testing.T, name string) [][2]string {
+	t.Helper()
+	f, err := os.Open("testdata/entropy/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var cases [][2]string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		path, content, ok := strings.Cut(line, "\t")
+		if !ok {
+			t.Fatalf("malformed corpus line %q", line)
+		}
+		cases = append(cases, [2]string{path, content})
+	}
+	return cases
+}
+
+func entropyDetector(t *testing.T) *Detector {
+	t.Helper()
+	d := defaultDetector(t)
+	d.SetEntropy(EntropyConfig{})
+	return d
+}
+
+func hasEntropyFinding(findings []Finding) bool {
+	for _, f := range findings {
+		if f.RuleID == EntropyRuleID {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEntropyTruePositives(t *testing.T) {
+	d := entropyDetector(t)
+	for _, c := range readCorpus(t, "true_positives.tsv") {
+		if findings := d.ScanLine(c[0], c[1]); !hasEntropyFinding(findings) {
+			t.Errorf("missed %s: %q (findings %+v)", c[0], c[1], findings)
+		}
+	}
+}
+
+func TestEntropyFalsePositives(t *testing.T) {
+	d := entropyDetector(t)
+	for _, c := range readCorpus(t, "false_positives.tsv") {
+		if findings := d.ScanLine(c[0], c[1]); hasEntropyFinding(findings) {
+			t.Errorf("false positive in %s: %q (findings %+v)", c[0], c[1], findings)
+		}
+	}
+}
+
+func TestEntropyDoesNotDuplicateRules(t *testing.T) {
+	d := entropyDetector(t)
+	findings := d.ScanLine("main.go", `token := "ghp_`+strings.Repeat("aB3dE5", 6)+`"`)
+	if len(findings) != 1 || findings[0].RuleID != "github-pat" {
+		t.Errorf("findings = %+v; want only github-pat", findings)
+	}
+}
+
+func TestEntropyConfig(t *testing.T) {
+	d := defaultDetector(t)
+	d.SetEntropy(EntropyConfig{Base64Threshold: 5.5, ExcludePaths: []string{"legacy/**"}})
+
+	line := `blob := "u8jzPde0IgxLd6GncfBAepfJBd0Kh8oOOL8dKLzd"`
+	if hasEntropyFinding(d.ScanLine("app.go", line)) {
+		t.Error("raised base64 threshold should suppress the finding")
+	}
+
+	d.SetEntropy(EntropyConfig{ExcludePaths: []string{"legacy/**"}})
+	if hasEntropyFinding(d.ScanLine("legacy/app.go", line)) {
+		t.Error("excluded path should not be scored")
+	}
+	if !hasEntropyFinding(d.ScanLine("app.go", line)) {
+		t.Error("custom exclusions must keep the default thresholds")
+	}
+
+	d.SetEntropy(EntropyConfig{Disabled: true})
+	if hasEntropyFinding(d.ScanLine("app.go", line)) {
+		t.Error("disabled entropy scoring still reported a finding")
+	}
+}
@@ -0,0 +1,86 @@
+package secrets
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// tokenRegex finds candidate opaque tokens for entropy-based redaction. "/"
+// is left out so that long paths and URLs, whose digits and mixed segments
+// score high as a whole, reach the model intact.
+var tokenRegex = regexp.MustCompile(`[A-Za-z0-9+=_\-]{20,}`)
+
+// Redactor masks secrets, PII and high-entropy strings in text before it
+// leaves the machine. The same value always maps to the same placeholder, so
+// the model can still tell that two redacted values are equal. A Redactor is
+// safe for concurrent use.
+type Redactor struct {
+	mu           sync.Mutex
+	detector     *Detector
+	placeholders map[string]string
+	counts       map[string]int
+}
+
+// NewRedactor creates a redactor using detector's rules.
+func NewRedactor(detector *Detector) *Redactor {
+	return &Redactor{
+		detector:     detector,
+		placeholders: make(map[string]string),
+		counts:       make(map[string]int),
+	}
+}
+
+// Redact masks sensitive values in text, which belongs to the file at path.
+// Unlike scanning, inline allow comments and allowlisted paths are not
+// honored: redaction errs on the side of not sending data. It returns the
+// redacted text and the number of values masked.
+func (r *Redactor) Redact(path, text string) (string, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lines := strings.Split(text, "\n")
+	masked := 0
+	for i, line := range lines {
+		for _, f := range r.detector.scanLine(path, line) {
+			if f.Secret == "" || !strings.Contains(line, f.Secret) {
+				continue
+			}
+			line = strings.ReplaceAll(line, f.Secret, r.placeholder(f.RuleID, f.Secret))
+			masked++
+		}
+		line = tokenRegex.ReplaceAllStringFunc(line, func(tok string) string {
+			if !IsHighEntropy(tok) {
+				return tok
+			}
+			masked++
+			return r.placeholder("high-entropy", tok)
+		})
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n"), masked
+}
+
+func (r *Redactor) placeholder(kind, secret string) string {
+	if p, ok := r.placeholders[secret]; ok {
+		return p
+	}
+	r.counts[kind]++
+	p := fmt.Sprintf("<redacted:%s:%d>", kind, r.counts[kind])
+	r.placeholders[secret] = p
+	return p
+}
+
+// IsHighEntropy reports whether tok looks like a random credential: it must
+// mix letters and digits and have high entropy. Unlike detection it skips the
+// identifier and sequence filters, since masking a harmless string only costs
+// the model a little context while a missed secret leaves the machine.
+func IsHighEntropy(tok string) bool {
+	if !hasDigitAndLetter(tok) {
+		return false
+	}
+	if hexRegex.MatchString(tok) {
+		return len(tok) >= 32 && ShannonEntropy(tok) >= 3.0
+	}
+	return ShannonEntropy(tok) >= 4.0
+}
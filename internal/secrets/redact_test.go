@@ -0,0 +1,71 @@
+package secrets
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	r := NewRedactor(defaultDetector(t))
+	token := "ghp_" + strings.Repeat("aB3dE5", 6)
+	diff := strings.Join([]string{
+		"@@ -1,2 +1,3 @@",
+		`-const owner = "jane.doe@corp-mail.io"`,
+		`+const owner = "jane.doe@corp-mail.io" // autocommiter:allow`,
+		`+const token = "` + token + `"`,
+		`+const other = "` + token + `"`,
+		`+const salt = "q8Zt3LmR9vXw2KpN7yHc4BfJ"`,
+		`+const seed = "abcdefgh7Kq2Lm9Xw4Zr"`,
+		` func BuildFileChangesForFilesInRepository() {}`,
+	}, "\n")
+
+	got, masked := r.Redact("config/prod/app.go", diff)
+	for _, leaked := range []string{"jane.doe", token, "q8Zt3LmR9vXw2KpN7yHc4BfJ", "abcdefgh7Kq2Lm9Xw4Zr"} {
+		if strings.Contains(got, leaked) {
+			t.Errorf("Redact() leaked %q:\n%s", leaked, got)
+		}
+	}
+	if !strings.Contains(got, "BuildFileChangesForFilesInRepository") {
+		t.Errorf("Redact() masked an identifier:\n%s", got)
+	}
+	if strings.Count(got, "<redacted:github-pat:1>") != 2 {
+		t.Errorf("same secret should map to one stable placeholder:\n%s", got)
+	}
+	if masked != 6 {
+		t.Errorf("masked = %d; want 6", masked)
+	}
+}
+
+func TestRedactKeepsPaths(t *testing.T) {
+	r := NewRedactor(defaultDetector(t))
+	for _, line := range []string{
+		`+	root := "/home/runner/work/api2/cmd/autocommiter/tools_v3"`,
+		`+<script src="https://cdn.example.com/assets/v2/build/main-4f9a1c.js"></script>`,
+	} {
+		if got, masked := r.Redact("main.go", line); got != line || masked != 0 {
+			t.Errorf("Redact(%q) = %q, %d masked; want it unchanged", line, got, masked)
+		}
+	}
+}
+
+func TestIsHighEntropy(t *testing.T) {
+	tests := []struct {
+		tok  string
+		want bool
+	}{
+		{"q8Zt3LmR9vXw2KpN7yHc4BfJ", true},
+		{"d41d8cd98f00b204e9800998ecf8427e", true},
+		// Masked although detection filters them as a sequence or an
+		// identifier.
+		{"abcdefgh7Kq2Lm9Xw4Zr", true},
+		{"apiKeyValue2024ForProd", true},
+		{"BuildFileChangesForFiles", false},
+		{"aaaaaaaaaaaaaaaaaaaaaaaa1", false},
+		{"12345678901234567890", false},
+	}
+	for _, tt := range tests {
+		if got := IsHighEntropy(tt.tok); got != tt.want {
+			t.Errorf("IsHighEntropy(%q) = %v; want %v", tt.tok, got, tt.want)
+		}
+	}
+}
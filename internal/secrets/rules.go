@@ -0,0 +1,293 @@
+package secrets
+
+// DefaultRules returns the built-in ruleset. IDs follow gitleaks so that a
+// team's gitleaks config can override individual rules. Specific rules come
+// first; the generic and PII rules last, so overlapping matches are reported
+// under the most precise rule.
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			ID:          "private-key",
+			Description: "Private Key",
+			Regex:       `(?i)-----BEGIN[ A-Z0-9_-]{0,100}PRIVATE KEY(?: BLOCK)?-----`,
+			Keywords:    []string{"-----begin"},
+		},
+		{
+			ID:          "aws-access-token",
+			Description: "AWS Key",
+			Regex:       `\b((?:A3T[A-Z0-9]|AKIA|ASIA|ABIA|ACCA|AGPA|AIDA|AROA|AIPA|ANPA|ANVA)[A-Z0-9]{16})\b`,
+			Keywords:    []string{"a3t", "akia", "asia", "abia", "acca", "agpa", "aida", "aroa", "aipa", "anpa", "anva"},
+			Allowlist:   &Allowlist{Regexes: []string{`EXAMPLE$`}},
+		},
+		{
+			ID:          "github-pat",
+			Description: "GitHub Personal Access Token",
+			Regex:       `\b(ghp_[0-9a-zA-Z]{36})\b`,
+			Keywords:    []string{"ghp_"},
+		},
+		{
+			ID:          "github-fine-grained-pat",
+			Description: "GitHub Fine-Grained Personal Access Token",
+			Regex:       `\b(github_pat_\w{82})\b`,
+			Keywords:    []string{"github_pat_"},
+		},
+		{
+			ID:          "github-oauth",
+			Description: "GitHub OAuth Access Token",
+			Regex:       `\b(gho_[0-9a-zA-Z]{36})\b`,
+			Keywords:    []string{"gho_"},
+		},
+		{
+			ID:          "github-app-token",
+			Description: "GitHub App Token",
+			Regex:       `\b((?:ghu|ghs)_[0-9a-zA-Z]{36})\b`,
+			Keywords:    []string{"ghu_", "ghs_"},
+		},
+		{
+			ID:          "github-refresh-token",
+			Description: "GitHub Refresh Token",
+			Regex:       `\b(ghr_[0-9a-zA-Z]{36})\b`,
+			Keywords:    []string{"ghr_"},
+		},
+		{
+			ID:          "gitlab-pat",
+			Description: "GitLab Personal Access Token",
+			Regex:       `\b(glpat-[\w-]{20})\b`,
+			Keywords:    []string{"glpat-"},
+		},
+		{
+			ID:          "slack-bot-token",
+			Description: "Slack Bot Token",
+			Regex:       `\b(xoxb-[0-9]{10,13}-[0-9]{10,13}[a-zA-Z0-9-]*)`,
+			Keywords:    []string{"xoxb"},
+		},
+		{
+			ID:          "slack-user-token",
+			Description: "Slack User Token",
+			Regex:       `\b(xox[pe](?:-[0-9]{10,13}){3}-[a-zA-Z0-9-]{28,34})`,
+			Keywords:    []string{"xoxp-", "xoxe-"},
+		},
+		{
+			ID:          "slack-app-token",
+			Description: "Slack App Token",
+			Regex:       `(?i)\b(xapp-\d-[A-Z0-9]+-\d+-[a-z0-9]+)`,
+			Keywords:    []string{"xapp"},
+		},
+		{
+			ID:          "slack-legacy-token",
+			Description: "Slack Legacy Token",
+			Regex:       `\b(xox[os]-\d+-\d+-\d+-[a-fA-F\d]+)`,
+			Keywords:    []string{"xoxo", "xoxs"},
+		},
+		{
+			ID:          "slack-webhook-url",
+			Description: "Slack Webhook",
+			Regex:       `(?:https?://)?hooks\.slack\.com/(?:services|workflows|triggers)/[A-Za-z0-9+/]{43,56}`,
+			Keywords:    []string{"hooks.slack.com"},
+		},
+		{
+			ID:          "gcp-api-key",
+			Description: "GCP API Key",
+			Regex:       `\b(AIza[\w-]{35})(?:[^\w-]|$)`,
+			Keywords:    []string{"aiza"},
+		},
+		{
+			ID:          "gcp-service-account",
+			Description: "GCP Service Account Key",
+			Regex:       `"type"\s*:\s*"service_account"`,
+			Keywords:    []string{"service_account"},
+		},
+		{
+			ID:          "gcp-oauth-client-secret",
+			Description: "GCP OAuth Client Secret",
+			Regex:       `\b(GOCSPX-[a-zA-Z0-9_-]{28})(?:[^\w-]|$)`,
+			Keywords:    []string{"gocspx-"},
+		},
+		{
+			ID:          "azure-storage-connection-string",
+			Description: "Azure Storage Account Key",
+			Regex:       `(?i)AccountKey=([A-Za-z0-9+/]{86}==)`,
+			Keywords:    []string{"accountkey="},
+		},
+		{
+			ID:          "stripe-access-token",
+			Description: "Stripe Key",
+			Regex:       `\b((?:sk|rk)_(?:test|live|prod)_[a-zA-Z0-9]{10,99})\b`,
+			Keywords:    []string{"sk_test", "sk_live", "sk_prod", "rk_test", "rk_live", "rk_prod"},
+		},
+		{
+			ID:          "openai-api-key",
+			Description: "OpenAI API Key",
+			Regex:       `\b(sk-(?:(?:proj|svcacct|admin)-[A-Za-z0-9_-]{20,}|[a-zA-Z0-9]{20})T3BlbkFJ[A-Za-z0-9_-]{20,})\b`,
+			Keywords:    []string{"t3blbkfj"},
+		},
+		{
+			ID:          "anthropic-api-key",
+			Description: "Anthropic API Key",
+			Regex:       `\b(sk-ant-(?:api03|admin01)-[a-zA-Z0-9_\-]{93}AA)(?:[^\w-]|$)`,
+			Keywords:    []string{"sk-ant-"},
+		},
+		{
+			ID:          "huggingface-access-token",
+			Description: "Hugging Face Access Token",
+			Regex:       `\b(hf_[a-zA-Z]{34})\b`,
+			Keywords:    []string{"hf_"},
+		},
+		{
+			ID:          "npm-access-token",
+			Description: "npm Access Token",
+			Regex:       `(?i)\b(npm_[a-z0-9]{36})\b`,
+			Keywords:    []string{"npm_"},
+		},
+		{
+			ID:          "pypi-upload-token",
+			Description: "PyPI Upload Token",
+			Regex:       `pypi-AgEIcHlwaS5vcmc[\w-]{50,1000}`,
+			Keywords:    []string{"pypi-ageichlwas5vcmc"},
+		},
+		{
+			ID:          "sendgrid-api-token",
+			Description: "SendGrid API Token",
+			Regex:       `\b(SG\.[a-zA-Z0-9=_\-.]{66})\b`,
+			Keywords:    []string{"sg."},
+		},
+		{
+			ID:          "twilio-api-key",
+			Description: "Twilio API Key",
+			Regex:       `\b(SK[0-9a-fA-F]{32})\b`,
+			Keywords:    []string{"sk"},
+			Entropy:     3,
+		},
+		{
+			ID:          "mailchimp-api-key",
+			Description: "Mailchimp API Key",
+			Regex:       `\b([0-9a-f]{32}-us\d{1,2})\b`,
+			Keywords:    []string{"-us"},
+		},
+		{
+			ID:          "shopify-access-token",
+			Description: "Shopify Token",
+			Regex:       `\b(shp(?:at|ca|pa|ss)_[a-fA-F0-9]{32})\b`,
+			Keywords:    []string{"shpat_", "shpca_", "shppa_", "shpss_"},
+		},
+		{
+			ID:          "square-access-token",
+			Description: "Square Access Token",
+			Regex:       `\b((?:EAAA|sq0atp-)[\w-]{22,60})\b`,
+			Keywords:    []string{"sq0atp-", "eaaa"},
+			Entropy:     3,
+		},
+		{
+			ID:          "digitalocean-pat",
+			Description: "DigitalOcean Personal Access Token",
+			Regex:       `\b(dop_v1_[a-f0-9]{64})\b`,
+			Keywords:    []string{"dop_v1_"},
+		},
+		{
+			ID:          "doppler-api-token",
+			Description: "Doppler API Token",
+			Regex:       `\b(dp\.pt\.[a-zA-Z0-9]{43})\b`,
+			Keywords:    []string{"dp.pt."},
+		},
+		{
+			ID:          "hashicorp-tf-api-token",
+			Description: "Terraform Cloud API Token",
+			Regex:       `(?i)\b([a-z0-9]{14}\.atlasv1\.[a-z0-9\-_=]{60,70})\b`,
+			Keywords:    []string{"atlasv1"},
+		},
+		{
+			ID:          "databricks-api-token",
+			Description: "Databricks API Token",
+			Regex:       `\b(dapi[a-f0-9]{32}(?:-\d)?)\b`,
+			Keywords:    []string{"dapi"},
+			Entropy:     3,
+		},
+		{
+			ID:          "linear-api-key",
+			Description: "Linear API Key",
+			Regex:       `\b(lin_api_[a-zA-Z0-9]{40})\b`,
+			Keywords:    []string{"lin_api_"},
+		},
+		{
+			ID:          "grafana-service-account-token",
+			Description: "Grafana Service Account Token",
+			Regex:       `\b(glsa_[A-Za-z0-9]{32}_[A-Fa-f0-9]{8})\b`,
+			Keywords:    []string{"glsa_"},
+		},
+		{
+			ID:          "postman-api-token",
+			Description: "Postman API Token",
+			Regex:       `\b(PMAK-[a-fA-F0-9]{24}-[a-fA-F0-9]{34})\b`,
+			Keywords:    []string{"pmak-"},
+		},
+		{
+			ID:          "age-secret-key",
+			Description: "age Secret Key",
+			Regex:       `AGE-SECRET-KEY-1[QPZRY9X8GF2TVDW0S3JN54KHCE6MUA7L]{58}`,
+			Keywords:    []string{"age-secret-key-1"},
+		},
+		{
+			ID:          "telegram-bot-api-token",
+			Description: "Telegram Bot API Token",
+			Regex:       `(?i)telegr\w*.{0,40}?\b([0-9]{5,16}:A[a-z0-9_\-]{34})\b`,
+			Keywords:    []string{"telegr"},
+		},
+		{
+			ID:          "discord-bot-token",
+			Description: "Discord Bot Token",
+			Regex:       `\b([MN][A-Za-z\d]{23,25}\.[\w-]{6}\.[\w-]{27,38})\b`,
+			Keywords:    []string{"discord"},
+		},
+		{
+			ID:          "jwt",
+			Description: "JSON Web Token",
+			Regex:       `\b(ey[a-zA-Z0-9]{17,}\.ey[a-zA-Z0-9/\\_-]{17,}\.(?:[a-zA-Z0-9/\\_-]{10,}={0,2})?)(?:['"\s;` + "`" + `]|$)`,
+			Keywords:    []string{"ey"},
+			Entropy:     3,
+		},
+		{
+			ID:          "url-credentials",
+			Description: "Credentials in URL",
+			Regex:       `\b[a-zA-Z][a-zA-Z0-9+.-]*://[^\s:/@'"]+:([^\s:/@'"$]{3,})@[^\s/'"]+`,
+			Keywords:    []string{"://"},
+			Allowlist: &Allowlist{
+				StopWords: []string{"password", "passwd", "secret", "changeme", "example", "xxx", "***"},
+				Regexes:   []string{`^\{.*\}$`, `^<.*>$`, `^%`},
+			},
+		},
+		{
+			ID:          "generic-api-key",
+			Description: "Potential API Key/Secret",
+			Regex:       `(?i)(?:key|token|secret|password|passwd|auth|api)[a-z0-9_.-]*["']?\s*[:=]{1,2}>?\s*["']?([a-zA-Z0-9\-._~+/]{32,})["']?`,
+			Keywords:    []string{"key", "token", "secret", "passw", "auth", "api"},
+			Entropy:     3.5,
+		},
+		{
+			ID:          "email-address",
+			Description: "PII (Email)",
+			Regex:       `(?i)\b[A-Z0-9._%+-]+@[A-Z0-9.-]+\.[A-Z]{2,}\b`,
+			Keywords:    []string{"@"},
+			Tags:        []string{"pii"},
+			Allowlist: &Allowlist{
+				RegexTarget: "match",
+				Regexes: []string{
+					`(?i)@(?:[a-z0-9-]+\.)?example\.(?:com|org|net)$`,
+					`(?i)noreply`,
+					`(?i)^git@`,
+				},
+			},
+		},
+	}
+}
+
+// DefaultAllowlist returns the global allowlist applied to every rule.
+func DefaultAllowlist() *Allowlist {
+	return &Allowlist{
+		Description: "lockfiles and vendored code",
+		Paths: []string{
+			`(?:^|/)go\.sum$`,
+			`(?:^|/)(?:package-lock\.json|yarn\.lock|pnpm-lock\.yaml|Cargo\.lock|poetry\.lock|Gemfile\.lock|composer\.lock)$`,
+			`(?:^|/)(?:vendor|node_modules)/`,
+		},
+	}
+}
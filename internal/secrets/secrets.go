@@ -0,0 +1,338 @@
+// Package secrets detects credentials and other sensitive values in text
+// using gitleaks-compatible rules.
+package secrets
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Rule is a detection rule in the gitleaks format.
+type Rule struct {
+	ID          string   `json:"id" toml:"id"`
+	Description string   `json:"description,omitempty" toml:"description"`
+	Regex       string   `json:"regex,omitempty" toml:"regex"`
+	SecretGroup int      `json:"secretGroup,omitempty" toml:"secretGroup"`
+	Entropy     float64  `json:"entropy,omitempty" toml:"entropy"`
+	Keywords    []string `json:"keywords,omitempty" toml:"keywords"`
+	Path        string   `json:"path,omitempty" toml:"path"`
+	Tags        []string `json:"tags,omitempty" toml:"tags"`
+	// Allowlist is the single-allowlist form used by older gitleaks configs;
+	// Allowlists is the current form. Both are honored.
+	Allowlist  *Allowlist  `json:"allowlist,omitempty" toml:"allowlist"`
+	Allowlists []Allowlist `json:"allowlists,omitempty" toml:"allowlists"`
+}
+
+// Allowlist suppresses findings by secret, line or path.
+type Allowlist struct {
+	Description string `json:"description,omitempty" toml:"description"`
+	// RegexTarget selects what Regexes are matched against: "secret"
+	// (default), "match" or "line".
+	RegexTarget string   `json:"regexTarget,omitempty" toml:"regexTarget"`
+	Regexes     []string `json:"regexes,omitempty" toml:"regexes"`
+	Paths       []string `json:"paths,omitempty" toml:"paths"`
+	StopWords   []string `json:"stopwords,omitempty" toml:"stopwords"`
+}
+
+// Finding is a single rule match.
+type Finding struct {
+	RuleID      string
+	Description string
+	Match       string
+	Secret      string
+	Column      int
+	Tags        []string
+}
+
+// Detector applies a compiled ruleset.
+type Detector struct {
+	rules     []compiledRule
+	allowlist *compiledAllowlist
+	entropy   *EntropyConfig
+}
+
+type compiledRule struct {
+	Rule
+	regex      *regexp.Regexp
+	path       *regexp.Regexp
+	keywords   []string
+	allowlists []*compiledAllowlist
+}
+
+type compiledAllowlist struct {
+	target    string
+	regexes   []*regexp.Regexp
+	paths     []*regexp.Regexp
+	stopWords []string
+}
+
+// NewDetector compiles rules and an optional global allowlist.
+func NewDetector(rules []Rule, global *Allowlist) (*Detector, error) {
+	d := &Detector{}
+	for _, r := range rules {
+		cr, err := compileRule(r)
+		if err != nil {
+			return nil, err
+		}
+		d.rules = append(d.rules, cr)
+	}
+	if global != nil {
+		al, err := compileAllowlist(*global)
+		if err != nil {
+			return nil, fmt.Errorf("global allowlist: %w", err)
+		}
+		d.allowlist = al
+	}
+	return d, nil
+}
+
+// Rules returns the number of rules loaded.
+func (d *Detector) Rules() int {
+	return len(d.rules)
+}
+
+// PathAllowed reports whether path is excluded by the global allowlist.
+func (d *Detector) PathAllowed(path string) bool {
+	return d.allowlist != nil && d.allowlist.allowsPath(path)
+}
+
+// ScanPath returns findings for path-only rules, i.e. rules that flag a file
+// by its name regardless of content.
+func (d *Detector) ScanPath(path string) []Finding {
+	if d.PathAllowed(path) {
+		return nil
+	}
+	var findings []Finding
+	for _, r := range d.rules {
+		if r.regex != nil || r.path == nil || !r.path.MatchString(path) {
+			continue
+		}
+		findings = append(findings, Finding{RuleID: r.ID, Description: r.Description, Match: path, Tags: r.Tags})
+	}
+	return findings
+}
+
+// ScanLine returns the findings in a single line of the file at path.
+// Overlapping matches are reported once, for the first (most specific) rule;
+// entropy findings come last, for strings no rule matched. Lines carrying an
+// AllowComments marker are skipped.
+func (d *Detector) ScanLine(path, line string) []Finding {
+	if d.PathAllowed(path) || containsAny(strings.ToLower(line), AllowComments) {
+		return nil
+	}
+	return d.scanEntropy(path, line, d.scanLine(path, line))
+}
+
+// scanLine applies the rules to line, ignoring inline allow comments and the
+// global path allowlist.
+func (d *Detector) scanLine(path, line string) []Finding {
+	lower := strings.ToLower(line)
+	var findings []Finding
+	for _, r := range d.rules {
+		if r.regex == nil {
+			continue
+		}
+		if r.path != nil && !r.path.MatchString(path) {
+			continue
+		}
+		if len(r.keywords) > 0 && !containsAny(lower, r.keywords) {
+			continue
+		}
+
+		for _, loc := range r.regex.FindAllStringSubmatchIndex(line, -1) {
+			match := line[loc[0]:loc[1]]
+			secret := match
+			if g := r.secretGroup(); g > 0 && 2*g+1 < len(loc) && loc[2*g] >= 0 {
+				secret = line[loc[2*g]:loc[2*g+1]]
+			}
+			if r.Entropy > 0 && ShannonEntropy(secret) < r.Entropy {
+				continue
+			}
+			if r.allowed(path, line, match, secret) || (d.allowlist != nil && d.allowlist.allows(path, line, match, secret)) {
+				continue
+			}
+			if overlaps(findings, secret) {
+				continue
+			}
+			findings = append(findings, Finding{
+				RuleID:      r.ID,
+				Description: r.Description,
+				Match:       match,
+				Secret:      secret,
+				Column:      loc[0] + 1,
+				Tags:        r.Tags,
+			})
+		}
+	}
+	return findings
+}
+
+// ShannonEntropy returns the entropy of s in bits per character.
+func ShannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	var entropy float64
+	for _, c := range counts {
+		p := float64(c) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// Merge returns base with extra appended; a rule in extra replaces the rule
+// in base with the same ID.
+func Merge(base []Rule, extra []Rule) []Rule {
+	index := make(map[string]int, len(base))
+	merged := make([]Rule, len(base))
+	copy(merged, base)
+	for i, r := range merged {
+		index[r.ID] = i
+	}
+	for _, r := range extra {
+		if i, ok := index[r.ID]; ok && r.ID != "" {
+			merged[i] = r
+			continue
+		}
+		index[r.ID] = len(merged)
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+func compileRule(r Rule) (compiledRule, error) {
+	cr := compiledRule{Rule: r}
+	if r.ID == "" {
+		return cr, fmt.Errorf("rule is missing an id")
+	}
+	if r.Regex == "" && r.Path == "" {
+		return cr, fmt.Errorf("rule %s: regex or path is required", r.ID)
+	}
+	if cr.Description == "" {
+		cr.Description = r.ID
+	}
+
+	var err error
+	if r.Regex != "" {
+		if cr.regex, err = regexp.Compile(r.Regex); err != nil {
+			return cr, fmt.Errorf("rule %s: invalid regex: %w", r.ID, err)
+		}
+	}
+	if r.Path != "" {
+		if cr.path, err = regexp.Compile(r.Path); err != nil {
+			return cr, fmt.Errorf("rule %s: invalid path: %w", r.ID, err)
+		}
+	}
+	for _, k := range r.Keywords {
+		cr.keywords = append(cr.keywords, strings.ToLower(k))
+	}
+
+	allowlists := r.Allowlists
+	if r.Allowlist != nil {
+		allowlists = append([]Allowlist{*r.Allowlist}, allowlists...)
+	}
+	for _, a := range allowlists {
+		al, err := compileAllowlist(a)
+		if err != nil {
+			return cr, fmt.Errorf("rule %s: %w", r.ID, err)
+		}
+		cr.allowlists = append(cr.allowlists, al)
+	}
+	return cr, nil
+}
+
+func compileAllowlist(a Allowlist) (*compiledAllowlist, error) {
+	al := &compiledAllowlist{target: a.RegexTarget}
+	for _, expr := range a.Regexes {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowlist regex %q: %w", expr, err)
+		}
+		al.regexes = append(al.regexes, re)
+	}
+	for _, expr := range a.Paths {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowlist path %q: %w", expr, err)
+		}
+		al.paths = append(al.paths, re)
+	}
+	for _, w := range a.StopWords {
+		al.stopWords = append(al.stopWords, strings.ToLower(w))
+	}
+	return al, nil
+}
+
+func (r compiledRule) secretGroup() int {
+	if r.SecretGroup > 0 {
+		return r.SecretGroup
+	}
+	// Like gitleaks, default to the first capture group when there is one.
+	if r.regex != nil && r.regex.NumSubexp() > 0 {
+		return 1
+	}
+	return 0
+}
+
+func (r compiledRule) allowed(path, line, match, secret string) bool {
+	for _, al := range r.allowlists {
+		if al.allows(path, line, match, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *compiledAllowlist) allowsPath(path string) bool {
+	for _, re := range a.paths {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *compiledAllowlist) allows(path, line, match, secret string) bool {
+	if a.allowsPath(path) {
+		return true
+	}
+	target := secret
+	switch a.target {
+	case "match":
+		target = match
+	case "line":
+		target = line
+	}
+	for _, re := range a.regexes {
+		if re.MatchString(target) {
+			return true
+		}
+	}
+	return containsAny(strings.ToLower(secret), a.stopWords)
+}
+
+func overlaps(findings []Finding, secret string) bool {
+	for _, f := range findings {
+		if strings.Contains(f.Secret, secret) || strings.Contains(secret, f.Secret) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(s string, words []string) bool {
+	for _, w := range words {
+		if strings.Contains(s, w) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,235 @@
+package style
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Subject conventions recognized by Analyze.
+const (
+	Conventional = "conventional" // feat(scope): message
+	Bracket      = "bracket"      // [component] Message
+	Subsystem    = "subsystem"    // subsys: message (kernel style)
+	Freeform     = "freeform"
+)
+
+// MinSamples is the number of commits needed before a profile is trusted.
+const MinSamples = 5
+
+var (
+	conventionalRegex = regexp.MustCompile(`^([a-z]+)(?:\(([^)]+)\))?!?: (.+)$`)
+	bracketRegex      = regexp.MustCompile(`^\[([^\]]+)\]:?\s+(.+)$`)
+	subsystemRegex    = regexp.MustCompile(`^([A-Za-z0-9_./-]+(?:, ?[A-Za-z0-9_./-]+)*): (.+)$`)
+	gitmojiCodeRegex  = regexp.MustCompile(`^:[a-z0-9_+-]+:\s*`)
+)
+
+var conventionalTypes = map[string]bool{
+	"feat": true, "fix": true, "docs": true, "style": true, "refactor": true,
+	"perf": true, "test": true, "build": true, "ci": true, "chore": true, "revert": true,
+}
+
+// Profile summarizes how a repository writes its commit messages.
+type Profile struct {
+	Samples       int      `json:"samples"`
+	Convention    string   `json:"convention"`
+	Casing        string   `json:"casing"`
+	Gitmoji       bool     `json:"gitmoji"`
+	AvgSubjectLen int      `json:"avg_subject_len"`
+	BodyRatio     float64  `json:"body_ratio"`
+	Scopes        []string `json:"scopes,omitempty"`
+	Examples      []string `json:"examples,omitempty"`
+}
+
+type parsedSubject struct {
+	convention  string
+	scope       string
+	description string
+	gitmoji     bool
+}
+
+// Analyze builds a profile from full commit messages, newest first.
+func Analyze(messages []string) Profile {
+	var p Profile
+	counts := make(map[string]int)
+	scopeCounts := make(map[string]int)
+	upper, lower, gitmojis, bodies, totalLen := 0, 0, 0, 0, 0
+	var parsed []parsedSubject
+	var subjects []string
+
+	for _, msg := range messages {
+		msg = strings.TrimSpace(msg)
+		if msg == "" {
+			continue
+		}
+		subject, body, _ := strings.Cut(msg, "\n")
+		subject = strings.TrimSpace(subject)
+		if isAutomatic(subject) {
+			continue
+		}
+
+		ps := parseSubject(subject)
+		parsed = append(parsed, ps)
+		subjects = append(subjects, subject)
+		counts[ps.convention]++
+		if ps.scope != "" {
+			for _, s := range strings.Split(ps.scope, ",") {
+				scopeCounts[strings.TrimSpace(s)]++
+			}
+		}
+		if ps.gitmoji {
+			gitmojis++
+		}
+		if r, _ := utf8.DecodeRuneInString(ps.description); unicode.IsUpper(r) {
+			upper++
+		} else if unicode.IsLower(r) {
+			lower++
+		}
+		if strings.TrimSpace(body) != "" {
+			bodies++
+		}
+		totalLen += utf8.RuneCountInString(subject)
+	}
+
+	p.Samples = len(parsed)
+	if p.Samples == 0 {
+		p.Convention = Freeform
+		return p
+	}
+
+	// A convention must cover at least half of the history to be enforced.
+	p.Convention = Freeform
+	best := 0
+	for _, c := range []string{Conventional, Bracket, Subsystem} {
+		if counts[c] > best && counts[c]*2 >= p.Samples {
+			best = counts[c]
+			p.Convention = c
+		}
+	}
+
+	p.Casing = "lower"
+	if upper > lower {
+		p.Casing = "upper"
+	}
+	p.Gitmoji = gitmojis*2 >= p.Samples
+	p.AvgSubjectLen = totalLen / p.Samples
+	p.BodyRatio = float64(bodies) / float64(p.Samples)
+	p.Scopes = topKeys(scopeCounts, 10)
+
+	for i, ps := range parsed {
+		if len(p.Examples) >= 5 {
+			break
+		}
+		if ps.convention == p.Convention {
+			p.Examples = append(p.Examples, subjects[i])
+		}
+	}
+
+	return p
+}
+
+// PromptRules renders the profile as instructions for the system prompt. It
+// returns an empty string when there is not enough history to learn from.
+func (p Profile) PromptRules() string {
+	if p.Samples < MinSamples {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Repository commit style (learned from %d recent commits):\n", p.Samples)
+
+	switch p.Convention {
+	case Conventional:
+		b.WriteString("- Format: Conventional Commits, \"type(scope): description\".\n")
+	case Bracket:
+		b.WriteString("- Format: \"[component] Description\". Do NOT use Conventional Commits types like feat: or fix:.\n")
+	case Subsystem:
+		b.WriteString("- Format: \"subsystem: description\" where subsystem is the area of the code changed. Do NOT use Conventional Commits types like feat: or fix:.\n")
+	default:
+		b.WriteString("- Format: plain descriptive subject without a type prefix. Do NOT use Conventional Commits types like feat: or fix:.\n")
+	}
+	if len(p.Scopes) > 0 {
+		fmt.Fprintf(&b, "- Prefer these existing scopes/components when they fit: %s\n", strings.Join(p.Scopes, ", "))
+	}
+	if p.Casing == "upper" {
+		b.WriteString("- Start the description with a capital letter.\n")
+	} else {
+		b.WriteString("- Start the description with a lowercase letter.\n")
+	}
+	if p.AvgSubjectLen > 0 {
+		fmt.Fprintf(&b, "- Typical subject length is about %d characters.\n", p.AvgSubjectLen)
+	}
+	if p.BodyRatio < 0.2 {
+		b.WriteString("- Bodies are rarely used; omit the body unless the change truly needs explanation.\n")
+	} else if p.BodyRatio > 0.6 {
+		b.WriteString("- Most commits include a short body explaining why; include one.\n")
+	}
+	if len(p.Examples) > 0 {
+		b.WriteString("Examples of real subjects from this repository:\n")
+		for _, e := range p.Examples {
+			fmt.Fprintf(&b, "- %s\n", e)
+		}
+	}
+
+	return b.String()
+}
+
+func parseSubject(subject string) parsedSubject {
+	stripped, hadGitmoji := stripGitmoji(subject)
+	ps := parsedSubject{convention: Freeform, description: stripped, gitmoji: hadGitmoji}
+
+	if m := conventionalRegex.FindStringSubmatch(stripped); m != nil && conventionalTypes[m[1]] {
+		ps.convention, ps.scope, ps.description = Conventional, m[2], m[3]
+	} else if m := bracketRegex.FindStringSubmatch(stripped); m != nil {
+		ps.convention, ps.scope, ps.description = Bracket, m[1], m[2]
+	} else if m := subsystemRegex.FindStringSubmatch(stripped); m != nil {
+		ps.convention, ps.scope, ps.description = Subsystem, m[1], m[2]
+	}
+	return ps
+}
+
+// stripGitmoji removes a leading emoji or :shortcode: from subject.
+func stripGitmoji(subject string) (string, bool) {
+	if loc := gitmojiCodeRegex.FindStringIndex(subject); loc != nil {
+		return subject[loc[1]:], true
+	}
+	r, size := utf8.DecodeRuneInString(subject)
+	if r > unicode.MaxLatin1 && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+		rest := subject[size:]
+		// Skip variation selectors and joiners that follow the emoji.
+		rest = strings.TrimLeftFunc(rest, func(r rune) bool {
+			return r == '\ufe0f' || r == '\u200d' || unicode.IsSpace(r)
+		})
+		return rest, true
+	}
+	return subject, false
+}
+
+func isAutomatic(subject string) bool {
+	return strings.HasPrefix(subject, "Merge ") ||
+		strings.HasPrefix(subject, "Revert \"") ||
+		strings.HasPrefix(subject, "fixup! ") ||
+		strings.HasPrefix(subject, "squash! ")
+}
+
+func topKeys(counts map[string]int, n int) []string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
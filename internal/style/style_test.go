@@ -0,0 +1,111 @@
+package style
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeConvention(t *testing.T) {
+	tests := []struct {
+		name       string
+		messages   []string
+		convention string
+		casing     string
+		scopes     []string
+	}{
+		{
+			name: "conventional",
+			messages: []string{
+				"feat(api): add provider interface",
+				"fix(git): handle detached HEAD",
+				"docs: update readme",
+				"refactor(api): split client",
+				"chore: bump deps",
+			},
+			convention: Conventional,
+			casing:     "lower",
+			scopes:     []string{"api", "git"},
+		},
+		{
+			name: "bracket",
+			messages: []string{
+				"[parser] Fix crash on empty input",
+				"[cli] Add --verbose flag",
+				"[parser] Support nested blocks",
+				"Merge branch 'main' into dev",
+				"[docs] Describe config format",
+			},
+			convention: Bracket,
+			casing:     "upper",
+			scopes:     []string{"parser", "cli", "docs"},
+		},
+		{
+			name: "kernel style",
+			messages: []string{
+				"net: fix refcount leak in tcp_close",
+				"mm/slab: remove dead code",
+				"net: add missing lock",
+				"drm/i915: handle hotplug",
+			},
+			convention: Subsystem,
+			casing:     "lower",
+			scopes:     []string{"net", "drm/i915", "mm/slab"},
+		},
+		{
+			name: "gitmoji conventional",
+			messages: []string{
+				"✨ feat: add split command",
+				"🐛 fix: correct line numbers",
+				":memo: docs: describe providers",
+			},
+			convention: Conventional,
+			casing:     "lower",
+		},
+		{
+			name: "freeform",
+			messages: []string{
+				"Update the thing",
+				"wip",
+				"more fixes",
+				"feat: one conventional commit",
+			},
+			convention: Freeform,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Analyze(tt.messages)
+			if p.Convention != tt.convention {
+				t.Errorf("Convention = %q; want %q", p.Convention, tt.convention)
+			}
+			if tt.casing != "" && p.Casing != tt.casing {
+				t.Errorf("Casing = %q; want %q", p.Casing, tt.casing)
+			}
+			if tt.scopes != nil && strings.Join(p.Scopes, ",") != strings.Join(tt.scopes, ",") {
+				t.Errorf("Scopes = %v; want %v", p.Scopes, tt.scopes)
+			}
+		})
+	}
+}
+
+func TestPromptRules(t *testing.T) {
+	messages := []string{
+		"[core] Add cache\n\nExplain why.",
+		"[core] Fix leak",
+		"[ui] Tweak colors",
+		"[ui] Add dark mode",
+		"[build] Pin toolchain",
+	}
+
+	rules := Analyze(messages).PromptRules()
+	for _, want := range []string{"[component] Description", "core, ui", "capital letter", "- [core] Add cache"} {
+		if !strings.Contains(rules, want) {
+			t.Errorf("PromptRules() missing %q:\n%s", want, rules)
+		}
+	}
+
+	if got := Analyze(messages[:2]).PromptRules(); got != "" {
+		t.Errorf("PromptRules() with too few samples = %q; want empty", got)
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 type FileChange struct {
 	File   string `json:"f"`
+	Status string `json:"s,omitempty"`
 	Change string `json:"c"`
 }
 
@@ -18,23 +19,52 @@ type FileChangesResponse struct {
 	Files []FileChange `json:"files"`
 }
 
-func AnalyzeFileChange(cwd string, file string) (string, error) {
+// Redact masks sensitive values in the diff of file. It is applied to the full
+// diff before it is truncated: a secret cut in half no longer matches the
+// rule that would have caught it.
+type Redact func(file, diff string) string
+
+func AnalyzeFileChange(repo git.Repository, file string, redact Redact) (string, error) {
 	// First, get the diff with context
-	diff, err := git.GetStagedDiff(cwd, file)
+	diff, err := repo.StagedDiff(file)
 	if err == nil && diff != "" {
-		// If the diff is small enough, return it all
-		if len(diff) < 2000 {
-			return diff, nil
-		}
+		return summarizeDiff(file, diff, redact, func() string {
+			numstat, _ := repo.StagedDiffNumstat(file)
+			return numstat
+		}), nil
+	}
+
+	return "mod", nil
+}
 
-		// Otherwise, get a summary of what changed
-		numstat, _ := git.GetStagedDiffNumstat(cwd, file)
-		return fmt.Sprintf("Large diff: %s\nFull diff omitted but here is the start:\n%s", numstat, truncateDiff(diff, 1000)), nil
+// AnalyzeRangeFileChange is AnalyzeFileChange for the changes a revision
+// range such as "base..HEAD" makes to file.
+func AnalyzeRangeFileChange(cwd string, revRange string, file string, redact Redact) (string, error) {
+	diff, err := git.GetRangeFileDiff(cwd, revRange, file)
+	if err == nil && diff != "" {
+		return summarizeDiff(file, diff, redact, func() string {
+			numstat, _ := git.GetRangeFileNumstat(cwd, revRange, file)
+			return numstat
+		}), nil
 	}
 
 	return "mod", nil
 }
 
+func summarizeDiff(file, diff string, redact Redact, numstat func() string) string {
+	if redact != nil {
+		diff = redact(file, diff)
+	}
+
+	// If the diff is small enough, return it all
+	if len(diff) < 2000 {
+		return diff
+	}
+
+	// Otherwise, get a summary of what changed
+	return fmt.Sprintf("Large diff: %s\nFull diff omitted but here is the start:\n%s", numstat(), truncateDiff(diff, 1000))
+}
+
 func truncateDiff(diff string, maxLen int) string {
 	if len(diff) <= maxLen {
 		return diff
@@ -42,12 +72,41 @@ func truncateDiff(diff string, maxLen int) string {
 	return diff[:maxLen] + "\n... (truncated)"
 }
 
-func BuildFileChanges(cwd string) ([]FileChange, error) {
-	files, err := git.GetStagedFiles(cwd)
+// BuildFileChanges summarizes every staged change. redact, when not nil, is
+// applied to each diff.
+func BuildFileChanges(repo git.Repository, redact Redact) ([]FileChange, error) {
+	files, err := repo.StagedFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildFileChangesForFiles(repo, files, redact)
+}
+
+// BuildFileChangesForFiles summarizes the staged changes of the given files only.
+func BuildFileChangesForFiles(repo git.Repository, files []string, redact Redact) ([]FileChange, error) {
+	statuses, _ := repo.StagedNameStatus()
+	return buildChanges(files, statuses, func(f string) (string, error) {
+		return AnalyzeFileChange(repo, f, redact)
+	}), nil
+}
+
+// BuildRangeFileChanges summarizes every file changed in a revision range.
+func BuildRangeFileChanges(cwd string, revRange string, redact Redact) ([]FileChange, error) {
+	statuses, err := git.GetRangeNameStatus(cwd, revRange)
 	if err != nil {
 		return nil, err
 	}
+	files := make([]string, 0, len(statuses))
+	for f := range statuses {
+		files = append(files, f)
+	}
+	return buildChanges(files, statuses, func(f string) (string, error) {
+		return AnalyzeRangeFileChange(cwd, revRange, f, redact)
+	}), nil
+}
 
+func buildChanges(files []string, statuses map[string]string, analyze func(string) (string, error)) []FileChange {
 	var changes []FileChange
 	var mu sync.Mutex
 	var wg sync.WaitGroup
@@ -56,10 +115,10 @@ func BuildFileChanges(cwd string) ([]FileChange, error) {
 		wg.Add(1)
 		go func(f string) {
 			defer wg.Done()
-			change, _ := AnalyzeFileChange(cwd, f)
+			change, _ := analyze(f)
 			
 			mu.Lock()
-			changes = append(changes, FileChange{File: f, Change: change})
+			changes = append(changes, FileChange{File: f, Status: statuses[f], Change: change})
 			mu.Unlock()
 		}(file)
 	}
@@ -71,7 +130,7 @@ func BuildFileChanges(cwd string) ([]FileChange, error) {
 		return changes[i].File < changes[j].File
 	})
 
-	return changes, nil
+	return changes
 }
 
 func CompressToJSON(fileChanges []FileChange, maxLen int) string {
@@ -90,7 +149,7 @@ func CompressToJSON(fileChanges []FileChange, maxLen int) string {
 			if truncateLen > 0 && len(change) > truncateLen {
 				change = change[:truncateLen] + "\n..."
 			}
-			mapped = append(mapped, FileChange{File: fc.File, Change: change})
+			mapped = append(mapped, FileChange{File: fc.File, Status: fc.Status, Change: change})
 		}
 		res := FileChangesResponse{Files: mapped}
 		b, _ := json.Marshal(res)
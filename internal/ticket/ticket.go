@@ -0,0 +1,207 @@
+// Package ticket extracts issue tracker references from branch names and
+// makes sure commit messages carry them.
+package ticket
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/nathfavour/autocommiter.go/internal/conventional"
+)
+
+// Where the reference goes in the message.
+const (
+	// PlacementTrailer adds a "Refs: PROJ-1234" trailer.
+	PlacementTrailer = "trailer"
+	// PlacementSubject starts the subject description with the reference,
+	// e.g. "feat(auth): PROJ-1234 add login".
+	PlacementSubject = "subject"
+	// PlacementCloses adds a "Closes #567" trailer that closes the issue on
+	// merge.
+	PlacementCloses = "closes"
+)
+
+// DefaultPatterns match Jira-style keys ("feature/PROJ-1234-new-login") and
+// issue numbers leading a branch segment, followed by a word or nothing
+// ("fix/567-crash", "567"), which leaves dates and versions such as
+// "release/2024-10" alone.
+var DefaultPatterns = []string{
+	`\b([A-Z][A-Z0-9]+-[0-9]+)\b`,
+	`(?:^|/)#?([0-9]+)(?:[-_][A-Za-z]|$)`,
+}
+
+// Config selects how references are found and placed.
+type Config struct {
+	Disabled bool `json:"disabled,omitempty"`
+	// Patterns are regular expressions tried in order against the branch
+	// name. The first capture group, or the whole match, is the reference.
+	Patterns []string `json:"patterns,omitempty"`
+	// Placement is PlacementTrailer (default), PlacementSubject or
+	// PlacementCloses.
+	Placement string `json:"placement,omitempty"`
+	// Trailer is the token of PlacementTrailer, "Refs" by default.
+	Trailer string `json:"trailer,omitempty"`
+}
+
+// WithDefaults fills unset fields of c.
+func (c Config) WithDefaults() Config {
+	if len(c.Patterns) == 0 {
+		c.Patterns = DefaultPatterns
+	}
+	if c.Placement == "" {
+		c.Placement = PlacementTrailer
+	}
+	if c.Trailer == "" {
+		c.Trailer = "Refs"
+	}
+	return c
+}
+
+// Validate reports invalid patterns and placements.
+func (c Config) Validate() error {
+	for _, p := range c.Patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("invalid ticket pattern %q: %w", p, err)
+		}
+	}
+	switch c.Placement {
+	case "", PlacementTrailer, PlacementSubject, PlacementCloses:
+		return nil
+	}
+	return fmt.Errorf("invalid ticket placement %q (use trailer, subject or closes)", c.Placement)
+}
+
+// Extract returns the ticket reference in branch, or an empty string. Issue
+// numbers come back as "#567".
+func Extract(branch string, cfg Config) (string, error) {
+	if cfg.Disabled {
+		return "", nil
+	}
+	cfg = cfg.WithDefaults()
+	for _, p := range cfg.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return "", fmt.Errorf("invalid ticket pattern %q: %w", p, err)
+		}
+		m := re.FindStringSubmatch(branch)
+		if m == nil {
+			continue
+		}
+		ref := m[0]
+		if len(m) > 1 && m[1] != "" {
+			ref = m[1]
+		}
+		ref = strings.TrimPrefix(ref, "#")
+		if isNumber(ref) {
+			ref = "#" + ref
+		}
+		return ref, nil
+	}
+	return "", nil
+}
+
+// Instruction tells the model where to put ref.
+func Instruction(ref string, cfg Config) string {
+	cfg = cfg.WithDefaults()
+	switch cfg.Placement {
+	case PlacementSubject:
+		return fmt.Sprintf("Start the subject description with the ticket reference %q.", ref)
+	case PlacementCloses:
+		return fmt.Sprintf("End the message with the trailer %q after a blank line.", closesTrailer(ref))
+	}
+	return fmt.Sprintf("End the message with the trailer %q after a blank line.", cfg.Trailer+": "+ref)
+}
+
+// Apply returns message with ref where cfg places it, adding it when the
+// message does not already carry it there.
+func Apply(message, ref string, cfg Config) string {
+	if ref == "" || cfg.Disabled {
+		return message
+	}
+	cfg = cfg.WithDefaults()
+	message = strings.TrimSpace(message)
+	c := conventional.Parse(message)
+
+	switch cfg.Placement {
+	case PlacementSubject:
+		if mentions(c.Subject, ref) {
+			return message
+		}
+		subject, rest, _ := strings.Cut(message, "\n")
+		description := c.Description
+		prefix := strings.TrimSuffix(subject, description)
+		subject = prefix + ref + " " + description
+		if rest != "" {
+			return subject + "\n" + rest
+		}
+		return subject
+	case PlacementCloses:
+		for _, f := range c.Footers {
+			if closing(f.Token) && footerMentions(f, ref) {
+				return message
+			}
+		}
+		return addTrailer(message, c, closesTrailer(ref))
+	}
+	for _, f := range c.Footers {
+		if footerMentions(f, ref) {
+			return message
+		}
+	}
+	return addTrailer(message, c, cfg.Trailer+": "+ref)
+}
+
+// closesTrailer renders the closing trailer: "Closes #567" for issue
+// numbers, which GitHub and GitLab act on, and "Closes: PROJ-1234" otherwise.
+func closesTrailer(ref string) string {
+	if strings.HasPrefix(ref, "#") {
+		return "Closes " + ref
+	}
+	return "Closes: " + ref
+}
+
+// addTrailer appends trailer to the message's footer paragraph, starting
+// one when there is none.
+func addTrailer(message string, c conventional.Commit, trailer string) string {
+	if len(c.Footers) > 0 {
+		return message + "\n" + trailer
+	}
+	return message + "\n\n" + trailer
+}
+
+func closing(token string) bool {
+	switch strings.ToLower(token) {
+	case "close", "closes", "closed", "fix", "fixes", "fixed", "resolve", "resolves", "resolved":
+		return true
+	}
+	return false
+}
+
+// footerMentions reports whether a footer refers to ref. In "Closes #567"
+// the "#" separates the token from the value, so it is not part of it.
+func footerMentions(f conventional.Footer, ref string) bool {
+	return mentions(f.Value, ref) || mentions("#"+f.Value, ref)
+}
+
+// mentions reports whether text contains ref as a whole word, so that #56
+// does not match #567.
+func mentions(text, ref string) bool {
+	re := regexp.MustCompile(`(?:^|[^\w#-])` + regexp.QuoteMeta(strings.TrimPrefix(ref, "#")) + `(?:$|[^\w-])`)
+	if strings.HasPrefix(ref, "#") {
+		re = regexp.MustCompile(regexp.QuoteMeta(ref) + `(?:$|\D)`)
+	}
+	return re.MatchString(text)
+}
+
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
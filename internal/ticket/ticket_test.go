@@ -0,0 +1,73 @@
+package ticket
+
+import "testing"
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		branch string
+		cfg    Config
+		want   string
+	}{
+		{"feature/PROJ-1234-new-login", Config{}, "PROJ-1234"},
+		{"fix/567-crash", Config{}, "#567"},
+		{"567", Config{}, "#567"},
+		{"bugfix/#89_null", Config{}, "#89"},
+		{"main", Config{}, ""},
+		{"feature/login-v2", Config{}, ""},
+		{"release/2024-10", Config{}, ""},
+		{"hotfix/1.4.2", Config{}, ""},
+		{"feature/PROJ-1234", Config{Disabled: true}, ""},
+		{"users/ana/gh-42-typo", Config{Patterns: []string{`gh-(\d+)`}}, "#42"},
+		{"task/abc-12", Config{Patterns: []string{`(?i)abc-\d+`}}, "abc-12"},
+	}
+	for _, tt := range tests {
+		got, err := Extract(tt.branch, tt.cfg)
+		if err != nil || got != tt.want {
+			t.Errorf("Extract(%q) = %q, %v; want %q", tt.branch, got, err, tt.want)
+		}
+	}
+	if _, err := Extract("x", Config{Patterns: []string{"("}}); err == nil {
+		t.Error("Extract accepted an invalid pattern")
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		ref     string
+		cfg     Config
+		want    string
+	}{
+		{"trailer added", "feat: add login", "PROJ-1234", Config{}, "feat: add login\n\nRefs: PROJ-1234"},
+		{"trailer joins footers", "feat: add login\n\nBody.\n\nReviewed-by: A", "PROJ-1234", Config{}, "feat: add login\n\nBody.\n\nReviewed-by: A\nRefs: PROJ-1234"},
+		{"trailer kept", "feat: add login\n\nRefs: PROJ-1234", "PROJ-1234", Config{}, "feat: add login\n\nRefs: PROJ-1234"},
+		{"custom trailer", "fix: crash", "#567", Config{Trailer: "Issue"}, "fix: crash\n\nIssue: #567"},
+		{"mention in body is not a trailer", "fix: crash\n\nThe crash in #567 is gone.", "#567", Config{}, "fix: crash\n\nThe crash in #567 is gone.\n\nRefs: #567"},
+		{"subject prefix", "✨ feat(auth): add login\n\nBody.", "PROJ-1234", Config{Placement: PlacementSubject}, "✨ feat(auth): PROJ-1234 add login\n\nBody."},
+		{"subject freeform", "Add login", "#567", Config{Placement: PlacementSubject}, "#567 Add login"},
+		{"subject kept", "feat: add login (PROJ-1234)", "PROJ-1234", Config{Placement: PlacementSubject}, "feat: add login (PROJ-1234)"},
+		{"similar ref is not the ref", "fix: crash (#56)", "#567", Config{Placement: PlacementSubject}, "fix: #567 crash (#56)"},
+		{"closes added", "fix: crash", "#567", Config{Placement: PlacementCloses}, "fix: crash\n\nCloses #567"},
+		{"closes kept", "fix: crash\n\nFixes #567", "#567", Config{Placement: PlacementCloses}, "fix: crash\n\nFixes #567"},
+		{"refs is not closes", "fix: crash\n\nRefs: #567", "#567", Config{Placement: PlacementCloses}, "fix: crash\n\nRefs: #567\nCloses #567"},
+		{"closes jira", "fix: crash", "PROJ-9", Config{Placement: PlacementCloses}, "fix: crash\n\nCloses: PROJ-9"},
+		{"no ref", "fix: crash", "", Config{}, "fix: crash"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Apply(tt.message, tt.ref, tt.cfg); got != tt.want {
+				t.Errorf("Apply =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := (Config{Placement: "body"}).Validate(); err == nil {
+		t.Error("Validate accepted an unknown placement")
+	}
+	if err := (Config{Patterns: []string{"[a-"}}).Validate(); err == nil {
+		t.Error("Validate accepted an invalid pattern")
+	}
+}
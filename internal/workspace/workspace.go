@@ -0,0 +1,373 @@
+// Package workspace reads the manifest that lists the repositories of a
+// multi-repo workspace, so commands run over a folder of clones touch exactly
+// the repositories it names.
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nathfavour/autocommiter.go/internal/git"
+	"github.com/nathfavour/autocommiter.go/internal/glob"
+	"gopkg.in/yaml.v3"
+)
+
+// FileNames are the manifest names looked up in a directory, in order.
+var FileNames = []string{".autocommiter-workspace.json", ".autocommiter-workspace.yaml", ".autocommiter-workspace.yml"}
+
+// DefaultMaxDepth is how many directories below the fixed part of a glob
+// entry are searched for repositories.
+const DefaultMaxDepth = 3
+
+// Manifest lists the repositories of a workspace.
+type Manifest struct {
+	// MaxDepth applies to entries without their own; DefaultMaxDepth when
+	// zero.
+	MaxDepth int `json:"max_depth,omitempty" yaml:"max_depth,omitempty"`
+	// Exclude are globs of repositories left out of every entry.
+	Exclude []string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+	Repos   []Entry  `json:"repos" yaml:"repos"`
+
+	// Dir is the directory holding the manifest.
+	Dir string `json:"-" yaml:"-"`
+	// File is the path of the manifest.
+	File string `json:"-" yaml:"-"`
+}
+
+// Entry names one repository, or a glob of them, relative to the manifest.
+type Entry struct {
+	// Path is a slash-separated path or glob such as "services/*" or
+	// "libs/**". It must stay inside the manifest's directory.
+	Path string   `json:"path" yaml:"path"`
+	Tags []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Include, when set, keeps only the repositories matching one of its
+	// globs; Exclude drops those matching any of its globs.
+	Include []string `json:"include,omitempty" yaml:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+	// MaxDepth is how many directories below the fixed part of Path are
+	// searched.
+	MaxDepth int `json:"max_depth,omitempty" yaml:"max_depth,omitempty"`
+	// Overrides are config settings, in the format of .autocommiter.json,
+	// applied to the entry's repositories before their own config.
+	Overrides map[string]any `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+}
+
+// Repo is a repository of a workspace.
+type Repo struct {
+	// Path is absolute; Rel is slash-separated and relative to the manifest.
+	Path  string
+	Rel   string
+	Entry Entry
+}
+
+// Load reads the manifest in dir. It returns nil when there is none.
+func Load(dir string) (*Manifest, error) {
+	for _, name := range FileNames {
+		file := filepath.Join(dir, name)
+		data, err := os.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var m Manifest
+		if strings.HasSuffix(name, ".json") {
+			err = json.Unmarshal(data, &m)
+		} else {
+			err = yaml.Unmarshal(data, &m)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid workspace manifest %s: %w", file, err)
+		}
+		if err := m.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid workspace manifest %s: %w", file, err)
+		}
+		m.Dir, _ = filepath.Abs(dir)
+		m.File = file
+		return &m, nil
+	}
+	return nil, nil
+}
+
+// Find returns the manifest of the nearest directory containing dir that has
+// one, or nil.
+func Find(dir string) (*Manifest, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		m, err := Load(dir)
+		if m != nil || err != nil {
+			return m, err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// Validate reports entries without a path, paths leaving the manifest's
+// directory, malformed globs and negative depths.
+func (m Manifest) Validate() error {
+	if m.MaxDepth < 0 {
+		return fmt.Errorf("max_depth must not be negative")
+	}
+	for _, p := range m.Exclude {
+		if err := checkGlob(p); err != nil {
+			return err
+		}
+	}
+	for i, e := range m.Repos {
+		if e.Path == "" {
+			return fmt.Errorf("repos[%d] has no path", i)
+		}
+		clean := path.Clean(e.Path)
+		if path.IsAbs(e.Path) || filepath.IsAbs(e.Path) || clean == ".." || strings.HasPrefix(clean, "../") {
+			return fmt.Errorf("repos[%d] path %q leaves the workspace", i, e.Path)
+		}
+		if e.MaxDepth < 0 {
+			return fmt.Errorf("repos[%d] max_depth must not be negative", i)
+		}
+		for _, p := range append(append([]string{e.Path}, e.Include...), e.Exclude...) {
+			if err := checkGlob(p); err != nil {
+				return fmt.Errorf("repos[%d]: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+func checkGlob(pattern string) error {
+	for _, segment := range strings.Split(pattern, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("invalid glob %q", pattern)
+		}
+	}
+	return nil
+}
+
+// Expand returns the repositories the manifest names, sorted by path. A
+// repository matched by several entries belongs to the first. Entries naming
+// a directory that is not a repository are skipped.
+func (m Manifest) Expand() []Repo {
+	seen := make(map[string]bool)
+	var repos []Repo
+	for _, e := range m.Repos {
+		for _, rel := range m.candidates(e) {
+			if seen[rel] || !m.matches(e, rel) {
+				continue
+			}
+			seen[rel] = true
+			repos = append(repos, Repo{Path: filepath.Join(m.Dir, filepath.FromSlash(rel)), Rel: rel, Entry: e})
+		}
+	}
+	sort.Slice(repos, func(i, j int) bool { return repos[i].Path < repos[j].Path })
+	return repos
+}
+
+// Lookup returns the entry the repository at repoRoot belongs to.
+func (m Manifest) Lookup(repoRoot string) (Entry, bool) {
+	abs, err := filepath.Abs(repoRoot)
+	if err != nil {
+		return Entry{}, false
+	}
+	rel, err := filepath.Rel(m.Dir, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return Entry{}, false
+	}
+	rel = filepath.ToSlash(rel)
+	for _, e := range m.Repos {
+		if m.matches(e, rel) && depth(rel, e.Path) <= m.maxDepth(e) {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// matches reports whether the repository at rel belongs to e.
+func (m Manifest) matches(e Entry, rel string) bool {
+	if !glob.Match("/"+path.Clean(e.Path), rel) {
+		return false
+	}
+	if len(e.Include) > 0 && !glob.MatchAny(e.Include, rel) {
+		return false
+	}
+	return !glob.MatchAny(e.Exclude, rel) && !glob.MatchAny(m.Exclude, rel)
+}
+
+func (m Manifest) maxDepth(e Entry) int {
+	switch {
+	case e.MaxDepth > 0:
+		return e.MaxDepth
+	case m.MaxDepth > 0:
+		return m.MaxDepth
+	}
+	return DefaultMaxDepth
+}
+
+// candidates returns the repositories below the fixed part of e's path, up
+// to its depth. Repositories are not searched for nested ones.
+func (m Manifest) candidates(e Entry) []string {
+	base := fixedPrefix(path.Clean(e.Path))
+	if base == path.Clean(e.Path) {
+		if isRepo(filepath.Join(m.Dir, filepath.FromSlash(base))) {
+			return []string{base}
+		}
+		return nil
+	}
+
+	maxDepth := m.maxDepth(e)
+	var found []string
+	root := filepath.Join(m.Dir, filepath.FromSlash(base))
+	filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(m.Dir, p)
+		rel = filepath.ToSlash(rel)
+		if p != root && (git.SkippedDirs[d.Name()] || d.Name() == ".git") {
+			return filepath.SkipDir
+		}
+		if isRepo(p) {
+			found = append(found, rel)
+			// Only the directory the glob starts from may hold repositories
+			// of its own, e.g. a workspace that is itself a repository.
+			if p != root {
+				return filepath.SkipDir
+			}
+		}
+		if depth(rel, base) >= maxDepth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return found
+}
+
+// fixedPrefix returns the leading segments of pattern without glob
+// characters, "." when there are none.
+func fixedPrefix(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	for i, s := range segments {
+		if strings.ContainsAny(s, "*?[") {
+			if i == 0 {
+				return "."
+			}
+			return strings.Join(segments[:i], "/")
+		}
+	}
+	return pattern
+}
+
+// depth returns how many directories rel lies below the fixed part of
+// pattern.
+func depth(rel, pattern string) int {
+	base := fixedPrefix(path.Clean(pattern))
+	if rel == base {
+		return 0
+	}
+	if base == "." {
+		return len(strings.Split(rel, "/"))
+	}
+	return strings.Count(strings.TrimPrefix(rel, base), "/")
+}
+
+func isRepo(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// Overrides returns the config overrides of the workspace entry the
+// repository at repoRoot belongs to, or nil.
+func Overrides(repoRoot string) (map[string]any, error) {
+	m, err := Find(repoRoot)
+	if m == nil || err != nil {
+		return nil, err
+	}
+	e, ok := m.Lookup(repoRoot)
+	if !ok {
+		return nil, nil
+	}
+	return e.Overrides, nil
+}
+
+// Filter narrows the repositories a command works on.
+type Filter struct {
+	// Tags keeps the repositories of workspace entries with one of them.
+	Tags []string
+	// Exclude drops the repositories whose path, relative to the searched
+	// root, matches one of these globs.
+	Exclude []string
+}
+
+// Discover returns the repositories under the comma-separated roots. A root
+// holding a manifest yields the repositories it lists; other roots are
+// searched as git.DiscoverRepositories does. Filtering by tag needs a
+// manifest.
+func Discover(roots string, f Filter) ([]string, error) {
+	var repos []string
+	for _, root := range strings.Split(roots, ",") {
+		root = strings.TrimSpace(root)
+		if root == "" {
+			continue
+		}
+		m, err := Load(root)
+		if err != nil {
+			return nil, err
+		}
+		if m == nil {
+			if len(f.Tags) > 0 {
+				return nil, fmt.Errorf("filtering by tag needs a workspace manifest (%s) in %s", FileNames[0], root)
+			}
+			abs, _ := filepath.Abs(root)
+			for _, repo := range git.DiscoverRepositories(root) {
+				if rel, err := filepath.Rel(abs, repo); err == nil && glob.MatchAny(f.Exclude, filepath.ToSlash(rel)) {
+					continue
+				}
+				repos = append(repos, repo)
+			}
+			continue
+		}
+		for _, r := range m.Expand() {
+			if len(f.Tags) > 0 && !anyTag(r.Entry.Tags, f.Tags) {
+				continue
+			}
+			if glob.MatchAny(f.Exclude, r.Rel) {
+				continue
+			}
+			repos = append(repos, r.Path)
+		}
+	}
+
+	sort.Strings(repos)
+	unique := make([]string, 0, len(repos))
+	seen := make(map[string]bool)
+	for _, r := range repos {
+		if !seen[r] {
+			seen[r] = true
+			unique = append(unique, r)
+		}
+	}
+	return unique, nil
+}
+
+func anyTag(tags, wanted []string) bool {
+	for _, t := range tags {
+		for _, w := range wanted {
+			if strings.EqualFold(t, w) {
+				return true
+			}
+		}
+	}
+	return false
+}
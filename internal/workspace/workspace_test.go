@@ -0,0 +1,132 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// layout creates dir/<repo>/.git for each repo and writes the manifest.
+func layout(t *testing.T, name, manifest string, repos ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, r := range repos {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.FromSlash(r), ".git"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if manifest != "" {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(manifest), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func rels(dir string, repos []string) []string {
+	var out []string
+	for _, r := range repos {
+		rel, _ := filepath.Rel(dir, r)
+		out = append(out, filepath.ToSlash(rel))
+	}
+	return out
+}
+
+const manifestJSON = `{
+  "exclude": ["scratch-*"],
+  "repos": [
+    {"path": "services/*", "tags": ["backend"], "exclude": ["services/legacy"]},
+    {"path": "web", "tags": ["frontend"]},
+    {"path": "libs/**", "max_depth": 1, "tags": ["backend"]},
+    {"path": "missing"}
+  ]
+}`
+
+var workspaceRepos = []string{
+	"services/api", "services/billing", "services/legacy", "web",
+	"libs/core", "libs/vendored/deep", "scratch-1", "node_modules/pkg",
+}
+
+func TestDiscover(t *testing.T) {
+	dir := layout(t, FileNames[0], manifestJSON, workspaceRepos...)
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"all", Filter{}, []string{"libs/core", "services/api", "services/billing", "web"}},
+		{"tag", Filter{Tags: []string{"Backend"}}, []string{"libs/core", "services/api", "services/billing"}},
+		{"exclude", Filter{Exclude: []string{"billing", "libs/"}}, []string{"services/api", "web"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos, err := Discover(dir, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rels(dir, repos); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Discover = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscoverWithoutManifest(t *testing.T) {
+	dir := layout(t, "", "", "api", "web", "node_modules/pkg")
+	repos, err := Discover(dir, Filter{Exclude: []string{"web"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rels(dir, repos); !reflect.DeepEqual(got, []string{"api"}) {
+		t.Errorf("Discover = %v; want [api]", got)
+	}
+	if _, err := Discover(dir, Filter{Tags: []string{"backend"}}); err == nil {
+		t.Error("Discover filtered by tag without a manifest")
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	manifest := `
+repos:
+  - path: services/*
+    overrides:
+      enable_gitmoji: true
+      commit_lint:
+        scopes: [api]
+  - path: web
+`
+	dir := layout(t, ".autocommiter-workspace.yaml", manifest, "services/api", "web")
+
+	got, err := Overrides(filepath.Join(dir, "services", "api"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["enable_gitmoji"] != true {
+		t.Errorf("Overrides = %v; want enable_gitmoji", got)
+	}
+	if got, _ := Overrides(filepath.Join(dir, "web")); got != nil {
+		t.Errorf("Overrides(web) = %v; want none", got)
+	}
+	if got, _ := Overrides(t.TempDir()); got != nil {
+		t.Errorf("Overrides outside the workspace = %v", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Manifest
+	}{
+		{"no path", Manifest{Repos: []Entry{{Tags: []string{"x"}}}}},
+		{"outside", Manifest{Repos: []Entry{{Path: "../other"}}}},
+		{"absolute", Manifest{Repos: []Entry{{Path: "/src/api"}}}},
+		{"bad glob", Manifest{Repos: []Entry{{Path: "services/[a"}}}},
+		{"negative depth", Manifest{Repos: []Entry{{Path: "a", MaxDepth: -1}}}},
+	}
+	for _, tt := range tests {
+		if err := tt.m.Validate(); err == nil {
+			t.Errorf("%s: Validate accepted %+v", tt.name, tt.m)
+		}
+	}
+}